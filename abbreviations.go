@@ -0,0 +1,167 @@
+package prose
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A BoundaryDecision records whether a period after an abbreviation (e.g.,
+// "Inc.") ends a sentence, as decided from the part-of-speech of the word
+// that follows it (see UsingAbbreviationTagger).
+type BoundaryDecision struct {
+	Offset       int    // The byte offset just past the abbreviation.
+	Abbreviation string // The abbreviation, e.g., "Inc.".
+	Next         string // The word following the abbreviation.
+	Tag          string // The POS tag of Next.
+	Split        bool   // If true, the abbreviation ends a sentence.
+	Changed      bool   // If true, Split overrides the SentenceTokenizer.
+	Reason       string // Why the decision was made.
+}
+
+// UsingAbbreviationTagger decides whether periods after abbreviations
+// followed by a capitalized word end sentences with `tagger`, rather than
+// leaving it to the SentenceTokenizer: in "He works at Acme Inc. He is the
+// CEO.", "He" is a pronoun, which starts a sentence, whereas "Chairman" in
+// "Acme Inc. Chairman John Doe resigned." is a proper noun, which continues
+// one. Titles (e.g., "Dr.") and initials never end sentences.
+func UsingAbbreviationTagger(tagger *PerceptronTagger) SegmenterOptFunc {
+	return func(opts *segmenterOpts) {
+		opts.tagger = tagger
+	}
+}
+
+// WithAbbreviationTagging can enable or disable (the default) deciding
+// whether periods after abbreviations end sentences with the model's POS
+// tagger (see UsingAbbreviationTagger), which also applies to custom
+// SentenceTokenizers.
+func WithAbbreviationTagging(include bool) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.AbbreviationTagging = include
+	}
+}
+
+// Boundaries returns the decisions Segment makes about the periods after
+// abbreviations in `text`, for debugging; there are none unless the
+// SentenceTokenizer was created with UsingAbbreviationTagger.
+func (s *sentenceSegmenter) Boundaries(text string) []BoundaryDecision {
+	if s.tagger == nil || strings.TrimSpace(text) == "" {
+		return []BoundaryDecision{}
+	}
+	_, decisions := s.disambiguate(text, locateSentences(text, s.tokenizer.Segment(text)))
+	return decisions
+}
+
+// sentenceStarters are the POS tags of (capitalized) words that start a
+// sentence after an abbreviation, rather than continue one.
+var sentenceStarters = map[string]bool{
+	"CC": true, "DT": true, "EX": true, "IN": true, "MD": true, "PRP": true,
+	"PRP$": true, "RB": true, "WDT": true, "WP": true, "WP$": true,
+	"WRB": true}
+
+// titleAbbreviations precede names, so they never end sentences.
+var titleAbbreviations = map[string]bool{
+	"capt": true, "col": true, "dr": true, "fr": true, "gen": true,
+	"gov": true, "hon": true, "lt": true, "maj": true, "messrs": true,
+	"mr": true, "mrs": true, "ms": true, "mt": true, "prof": true,
+	"rep": true, "rev": true, "sen": true, "sgt": true, "st": true}
+
+var reWord = regexp.MustCompile(`\S+`)
+
+// isAbbreviation determines if `word` (e.g., "Inc." or "U.S.") is an
+// abbreviation known to the segmenter, a dotted abbreviation, or an
+// initial.
+func (s *sentenceSegmenter) isAbbreviation(word string) bool {
+	word = strings.TrimLeft(word, `"'([‘“`)
+	if !strings.HasSuffix(word, ".") {
+		return false
+	}
+	stem := strings.TrimSuffix(word, ".")
+	if strings.IndexFunc(stem, unicode.IsLetter) < 0 || strings.HasSuffix(stem, ".") {
+		// Not a word, or an ellipsis.
+		return false
+	}
+	return s.abbreviations[strings.ToLower(stem)] || strings.Contains(stem, ".") ||
+		utf8.RuneCountInString(stem) == 1
+}
+
+// disambiguate decides whether the abbreviations in `text`, which has been
+// segmented into `sents`, end sentences (see UsingAbbreviationTagger),
+// returning the resulting sentences and the decisions.
+func (s *sentenceSegmenter) disambiguate(text string, sents []Sentence) ([]Sentence, []BoundaryDecision) {
+	decisions := []BoundaryDecision{}
+	for _, sent := range sents {
+		if sent.Start < 0 {
+			// We can't relate the sentences to the text.
+			return sents, decisions
+		}
+	}
+	if len(sents) == 0 {
+		return sents, decisions
+	}
+
+	tokens := s.tagger.Tag(NewIterTokenizer().Tokenize(text))
+	tagAt := func(offset int) string {
+		i := sort.Search(len(tokens), func(i int) bool { return tokens[i].Start >= offset })
+		if i == len(tokens) {
+			return ""
+		}
+		return tokens[i].Tag
+	}
+
+	ends := map[int]bool{}
+	for _, sent := range sents {
+		ends[sent.End] = true
+	}
+	words := reWord.FindAllStringIndex(text, -1)
+	for i := 0; i+1 < len(words); i++ {
+		word, next := text[words[i][0]:words[i][1]], text[words[i+1][0]:words[i+1][1]]
+		start := words[i+1][0] + len(next) - len(strings.TrimLeft(next, `"'([‘“`))
+		first, _ := utf8.DecodeRuneInString(text[start:])
+		if !unicode.IsUpper(first) || !s.isAbbreviation(word) {
+			continue
+		}
+
+		decision := BoundaryDecision{
+			Offset: words[i][1], Abbreviation: word, Next: next, Tag: tagAt(start)}
+		stem := strings.ToLower(strings.TrimSuffix(strings.TrimLeft(word, `"'([‘“`), "."))
+		switch {
+		case titleAbbreviations[stem]:
+			decision.Reason = "titles precede names"
+		case utf8.RuneCountInString(stem) == 1:
+			decision.Reason = "initials precede names"
+		case sentenceStarters[decision.Tag]:
+			decision.Split = true
+			decision.Reason = "the next word starts a sentence"
+		default:
+			decision.Reason = "the next word continues a sentence"
+		}
+		decision.Changed = decision.Split != ends[decision.Offset]
+		ends[decision.Offset] = decision.Split
+		decisions = append(decisions, decision)
+	}
+
+	cuts := []int{}
+	for offset, end := range ends {
+		if end {
+			cuts = append(cuts, offset)
+		}
+	}
+	sort.Ints(cuts)
+
+	resegmented := []Sentence{}
+	start, final := sents[0].Start, sents[len(sents)-1].End
+	for _, cut := range append(cuts, final) {
+		if cut <= start || cut > final {
+			continue
+		}
+		if sent := strings.TrimSpace(text[start:cut]); sent != "" {
+			from := start + strings.Index(text[start:cut], sent)
+			resegmented = append(resegmented, Sentence{Text: sent, Start: from, End: from + len(sent)})
+		}
+		start = cut
+	}
+	return resegmented, decisions
+}
@@ -0,0 +1,90 @@
+package prose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// NewDocuments creates a Document, according to `opts`, for each of
+// `texts`, using one worker per CPU and sharing a single Model. The
+// Documents are returned in input order.
+//
+// A text that can't be processed doesn't stop the others: its Document is
+// nil, and the error is reported in the returned *BatchError.
+func NewDocuments(texts []string, opts ...DocOpt) ([]*Document, error) {
+	// Resolve the model and segmenter once rather than once per Document.
+	probe, config := Document{}, defaultOpts
+	for _, applyOpt := range opts {
+		applyOpt(&probe, &config)
+	}
+	model := config.Model
+	if model == nil {
+		var err error
+		model, err = defaultModel(config.Tag, config.Extract)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load default model: %w", err)
+		}
+	}
+	if config.Segment {
+		segmenter, err := documentSegmenter(config, model.tagger)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts[:len(opts):len(opts)], UsingSentenceTokenizer(segmenter))
+	}
+
+	input := make(chan string)
+	go func() {
+		defer close(input)
+		for _, text := range texts {
+			input <- text
+		}
+	}()
+
+	docs := make([]*Document, len(texts))
+	failed := &BatchError{Errs: map[int]error{}}
+	for result := range model.ExtractStream(context.Background(), input, opts...) {
+		if result.Err != nil {
+			failed.Errs[result.Index] = result.Err
+		} else {
+			docs[result.Index] = result.Document
+		}
+	}
+
+	if len(failed.Errs) > 0 {
+		return docs, failed
+	}
+	return docs, nil
+}
+
+// NewDocuments creates Documents as NewDocuments does, using `p`'s model
+// and configuration.
+func (p *Pipeline) NewDocuments(texts []string, opts ...DocOpt) ([]*Document, error) {
+	return NewDocuments(texts, append([]DocOpt{UsingConfig(p.Config), UsingModel(p.Model)}, opts...)...)
+}
+
+// A BatchError holds the errors of the texts that NewDocuments couldn't
+// process.
+type BatchError struct {
+	Errs map[int]error // The error of each failed text, by its index.
+}
+
+func (e *BatchError) Error() string {
+	indices := e.Indices()
+	if len(indices) == 0 {
+		return "none of the texts failed"
+	}
+	first := indices[0]
+	return fmt.Sprintf("%d of the texts failed; text %d: %v", len(indices), first, e.Errs[first])
+}
+
+// Indices returns the indices of the failed texts, in order.
+func (e *BatchError) Indices() []int {
+	indices := make([]int, 0, len(e.Errs))
+	for i := range e.Errs {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
@@ -0,0 +1,81 @@
+package prose
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDocuments(t *testing.T) {
+	texts := []string{}
+	for i := 0; i < 10; i++ {
+		texts = append(texts, fmt.Sprintf("Jack Smith moved to Seattle in %d.", 1990+i))
+	}
+	texts[3] = "Это не английский текст."
+
+	docs, err := NewDocuments(texts, WithInputGuard(PolicyError))
+	require.Error(t, err)
+	var failed *BatchError
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, []int{3}, failed.Indices())
+	assert.True(t, errors.Is(failed.Errs[3], ErrUnsupportedLanguage))
+
+	require.Len(t, docs, len(texts))
+	for i, doc := range docs {
+		if i == 3 {
+			assert.Nil(t, doc)
+			continue
+		}
+		expected, err := NewDocument(texts[i])
+		require.NoError(t, err)
+		assert.Equal(t, texts[i], doc.Text)
+		assert.Equal(t, expected.Entities(), doc.Entities())
+	}
+
+	docs, err = NewDocuments(texts[:3])
+	require.NoError(t, err)
+	assert.Len(t, docs, 3)
+
+	assert.NotPanics(t, func() { _ = (&BatchError{}).Error() })
+}
+
+func batchTexts() []string {
+	sentences := []string{
+		"Jack Smith moved to Seattle to work for Microsoft.",
+		"The board will meet in New York on Tuesday.",
+		"Apple opened a new office in London last year.",
+		"Barack Obama was born in Hawaii.",
+	}
+	texts := make([]string, 2000)
+	for i := range texts {
+		texts[i] = sentences[i%len(sentences)]
+	}
+	return texts
+}
+
+func BenchmarkNewDocumentSerial(b *testing.B) {
+	model, err := defaultModel(true, true)
+	require.NoError(b, err)
+	texts := batchTexts()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, text := range texts {
+			_, err := NewDocument(text, UsingModel(model))
+			require.NoError(b, err)
+		}
+	}
+}
+
+func BenchmarkNewDocuments(b *testing.B) {
+	model, err := defaultModel(true, true)
+	require.NoError(b, err)
+	texts := batchTexts()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, err := NewDocuments(texts, UsingModel(model))
+		require.NoError(b, err)
+	}
+}
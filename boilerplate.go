@@ -0,0 +1,121 @@
+package prose
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// A BoilerplateDetector finds boilerplate, such as signatures and
+// disclaimers: sentences that recur across many documents of a corpus.
+// It's safe for concurrent use.
+type BoilerplateDetector struct {
+	segmenter *sentenceSegmenter
+	minDocs   int
+
+	mu     sync.RWMutex
+	counts map[string]int // The number of documents containing each sentence.
+}
+
+// NewBoilerplateDetector creates a BoilerplateDetector that considers a
+// sentence boilerplate once it has been seen in `minDocs` documents (at
+// least 2).
+func NewBoilerplateDetector(minDocs int) (*BoilerplateDetector, error) {
+	segmenter, err := NewPunktSentenceTokenizer()
+	if err != nil {
+		return nil, err
+	}
+	if minDocs < 2 {
+		minDocs = 2
+	}
+	return &BoilerplateDetector{
+		segmenter: segmenter,
+		minDocs:   minDocs,
+		counts:    make(map[string]int)}, nil
+}
+
+// Add records the sentences of the document `text`.
+func (d *BoilerplateDetector) Add(text string) {
+	seen := make(map[string]bool)
+	for _, sent := range d.segmenter.Segment(text) {
+		seen[boilerplateKey(sent.Text)] = true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key := range seen {
+		d.counts[key]++
+	}
+}
+
+// IsBoilerplate determines if `sentence` is boilerplate. Sentences are
+// compared regardless of case, whitespace, and digits, so that (e.g.)
+// dated signatures match.
+func (d *BoilerplateDetector) IsBoilerplate(sentence string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.counts[boilerplateKey(sentence)] >= d.minDocs
+}
+
+// Filter returns the examples of `data` that are neither boilerplate nor
+// duplicates of an earlier example, for training on a corpus of sentences.
+func (d *BoilerplateDetector) Filter(data []EntityContext) []EntityContext {
+	kept := []EntityContext{}
+	seen := make(map[string]bool)
+	for _, example := range data {
+		key := boilerplateKey(example.Text)
+		if seen[key] || d.IsBoilerplate(example.Text) {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, example)
+	}
+	return kept
+}
+
+// boilerplateKey normalizes `sentence` for comparison: it's lowercased,
+// its whitespace collapsed, and its digits replaced by zeros.
+func boilerplateKey(sentence string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return '0'
+		}
+		return unicode.ToLower(r)
+	}, strings.Join(strings.Fields(sentence), " "))
+}
+
+// WithoutBoilerplate excludes entities within sentences that `detector`
+// considers boilerplate. Documents created with it aren't cached, since the
+// detector changes as it sees more documents.
+func WithoutBoilerplate(detector *BoilerplateDetector) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Boilerplate = detector
+	}
+}
+
+// dropBoilerplate removes the entities of `doc` that start within one of its
+// boilerplate sentences.
+func dropBoilerplate(doc *Document, detector *BoilerplateDetector) {
+	spans := [][2]int{}
+	for _, sent := range doc.sentences {
+		if sent.Start >= 0 && detector.IsBoilerplate(sent.Text) {
+			spans = append(spans, [2]int{sent.Start, sent.End})
+		}
+	}
+	if len(spans) == 0 {
+		return
+	}
+
+	kept, parts := doc.entities[:0], doc.entParts[:0]
+	for i, ent := range doc.entities {
+		boilerplate := false
+		for _, span := range spans {
+			boilerplate = boilerplate || (ent.Start >= span[0] && ent.Start < span[1])
+		}
+		if !boilerplate {
+			kept = append(kept, ent)
+			parts = append(parts, doc.entParts[i])
+		}
+	}
+	doc.entities, doc.entParts = kept, parts
+}
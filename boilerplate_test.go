@@ -0,0 +1,60 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoilerplateDetector(t *testing.T) {
+	detector, err := NewBoilerplateDetector(3)
+	require.NoError(t, err)
+
+	footer := "This email is confidential and intended only for John Smith of Acme Corp."
+	emails := []string{
+		"Please review the contract by Monday. " + footer,
+		"The meeting moved to Room 4.  THIS EMAIL IS CONFIDENTIAL and intended only for John Smith of Acme Corp.",
+		"Lunch is on us today. " + footer,
+	}
+	for _, text := range emails[:2] {
+		detector.Add(text)
+	}
+	assert.False(t, detector.IsBoilerplate(footer))
+	detector.Add(emails[2])
+	assert.True(t, detector.IsBoilerplate(footer))
+	assert.False(t, detector.IsBoilerplate("Lunch is on us today."))
+
+	// Sentences differing only in digits match.
+	for i := 0; i < 3; i++ {
+		detector.Add("Something else. Sent on 2023-0" + string(rune('1'+i)) + "-15.")
+	}
+	assert.True(t, detector.IsBoilerplate("Sent on 2024-12-31."))
+
+	text := "Jane Doe met Google executives in Paris. " + footer
+	doc, err := NewDocument(text)
+	require.NoError(t, err)
+	filtered, err := NewDocument(text, WithoutBoilerplate(detector))
+	require.NoError(t, err)
+	names := func(doc *Document) []string {
+		texts := []string{}
+		for _, ent := range doc.Entities() {
+			texts = append(texts, ent.Text)
+		}
+		return texts
+	}
+	assert.Contains(t, names(doc), "John Smith")
+	assert.NotContains(t, names(filtered), "John Smith")
+	assert.Contains(t, names(filtered), "Jane Doe")
+
+	data := []EntityContext{
+		{Text: "Jane Doe met Google executives."},
+		{Text: footer},
+		{Text: "Jane  Doe met Google executives."},
+		{Text: "Lunch is on us today."},
+	}
+	kept := detector.Filter(data)
+	require.Len(t, kept, 2)
+	assert.Equal(t, data[0].Text, kept[0].Text)
+	assert.Equal(t, data[3].Text, kept[1].Text)
+}
@@ -0,0 +1,366 @@
+package prose
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// A DocumentCache stores processed Documents (see WithDocumentCache).
+// Implementations must be safe for concurrent use.
+type DocumentCache interface {
+	// Get returns the Document stored under `key`, if any.
+	Get(key string) (*Document, bool)
+	// Put stores `doc` under `key`.
+	Put(key string, doc *Document)
+}
+
+// WithDocumentCache makes NewDocument return the Document stored in `cache`
+// for the same text, configuration, and model (by content), if any, and
+// store the Documents it creates.
+//
+// Documents created from pre-tokenized input (see UsingTokens), or with a
+// BoilerplateDetector, CaseDictionary, Gazetteer, or a tokenizer or
+// segmenter whose configuration can't be compared (see cacheKey), aren't
+// cached.
+func WithDocumentCache(cache DocumentCache) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Cache = cache
+	}
+}
+
+// cacheFormat is the version of the cached Documents' format; it changes
+// the keys whenever older Documents would be incomplete.
+const cacheFormat = 4
+
+// cacheKey returns the key under which the Document for `text` is cached,
+// or "" if it can't be.
+//
+// The key covers every Config field that affects the Document's contents.
+// The Model, KnownEntities, and Cache fields don't: the model is keyed by
+// its fingerprint, and a cached Document is given the Config (and so the
+// Model and KnownEntities) it's requested with.
+func cacheKey(text string, model *Model, c Config) string {
+	if c.Tokens != nil || c.Boilerplate != nil || c.CaseDictionary != nil ||
+		c.Gazetteer != nil {
+		return ""
+	}
+	tokenizer, segmenter := tokenizerKey(c.Tokenizer), segmenterKey(c.SentenceTokenizer)
+	if tokenizer == "" || segmenter == "" {
+		return ""
+	}
+	boundaries := ""
+	if c.Boundaries != nil {
+		boundaries = c.Boundaries.String()
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d %q %q %q %q %q\n", cacheFormat, model.fingerprint(), text, tokenizer, segmenter, boundaries)
+	fmt.Fprintf(h, "%v %v %v %v %v %v %v %v %v %v %v %v %v %v %v %v %v %v\n",
+		c.Segment, c.Tag, c.Extract, c.Guard, c.BlockContext, c.Confusables,
+		c.Unicode, c.Tables, c.ListItems, c.Trim, c.Sentiment, c.Caps,
+		c.MinConfidence, c.Consistency, c.Focus, c.Trace, c.Quality, c.StrictTokenizer)
+	fmt.Fprintf(h, "%v\n", c.AbbreviationTagging)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// tokenizerKey identifies the configuration of `tokenizer` for cacheKey, or
+// returns "" if it can't be compared: custom Tokenizers without a
+// Fingerprint method, and iterTokenizers with a custom TokenTester or
+// sanitizer, can't.
+func tokenizerKey(tokenizer Tokenizer) string {
+	switch t := tokenizer.(type) {
+	case nil:
+		return "none"
+	case *iterTokenizer:
+		if t.isUnsplittable != nil || t.sanitizer != sanitizer {
+			return ""
+		}
+		return fmt.Sprintf("%s %v", t.Fingerprint(), t.whitespace)
+	case interface{ Fingerprint() string }:
+		return t.Fingerprint()
+	}
+	return ""
+}
+
+// segmenterKey identifies the configuration of `tokenizer` for cacheKey,
+// or returns "" if it can't be compared, as with custom SentenceTokenizers.
+func segmenterKey(tokenizer SentenceTokenizer) string {
+	switch t := tokenizer.(type) {
+	case nil:
+		return "punkt"
+	case *punktSentenceTokenizer:
+		return fmt.Sprintf("punkt %q %q", t.opts.add, t.opts.remove)
+	case *ruleSentenceTokenizer:
+		return fmt.Sprintf("rule %q", sortedKeys(t.abbreviations))
+	case *sentenceSegmenter:
+		inner := segmenterKey(t.tokenizer)
+		if inner == "" || t.tagger == nil {
+			return inner
+		}
+		abbreviations := make([]string, 0, len(t.abbreviations))
+		for abbr := range t.abbreviations {
+			abbreviations = append(abbreviations, abbr)
+		}
+		sort.Strings(abbreviations)
+		return fmt.Sprintf("%s tagged %s %q", inner, t.tagger.model.fingerprint(), abbreviations)
+	}
+	return ""
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// digestMu guards the digests of models' components.
+var digestMu sync.Mutex
+
+// fingerprint summarizes the contents of `m`, so that Documents cached for
+// one model aren't returned for another of the same name (or for the same
+// model after it's pruned or distilled). Each component's digest is
+// computed once; the embedded ones are known.
+func (m *Model) fingerprint() string {
+	tagger, extracter := "", ""
+	if m.tagger != nil {
+		tagger = m.tagger.model.fingerprint()
+	}
+	if m.extracter != nil {
+		extracter = m.extracter.fingerprint()
+	}
+	return tagger + " " + extracter
+}
+
+func (m *averagedPerceptron) fingerprint() string {
+	digestMu.Lock()
+	defer digestMu.Unlock()
+	if m.embedded {
+		return "embedded"
+	} else if m.digest != "" {
+		return m.digest
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%q\n", m.classes)
+	words := make([]string, 0, len(m.tagMap))
+	for word := range m.tagMap {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	for _, word := range words {
+		fmt.Fprintf(h, "%q %q\n", word, m.tagMap[word])
+	}
+	features := make([]string, 0, len(m.linearWeights))
+	for feature := range m.linearWeights {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	for _, feature := range features {
+		fmt.Fprintf(h, "%q ", feature)
+		binary.Write(h, binary.LittleEndian, m.linearWeights[feature])
+	}
+	m.digest = hex.EncodeToString(h.Sum(nil))
+	return m.digest
+}
+
+func (e *entityExtracter) fingerprint() string {
+	digestMu.Lock()
+	defer digestMu.Unlock()
+	if e.digest != "" {
+		return e.digest
+	}
+
+	m := e.model
+	h := sha256.New()
+	fmt.Fprintf(h, "%d %d %q %q %q\n", m.cardinality, m.hashSize, m.labels, e.scheme, e.tokenizer)
+	entries := make([]string, 0, len(m.mapping))
+	for entry := range m.mapping {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	for _, entry := range entries {
+		fmt.Fprintf(h, "%q %d\n", entry, m.mapping[entry])
+	}
+	binary.Write(h, binary.LittleEndian, m.weights)
+	labels := make([]string, 0, len(m.templates))
+	for label := range m.templates {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		fmt.Fprintf(h, "%q %v\n", label, m.templates[label])
+	}
+	keys := make([]string, 0, len(e.lookup))
+	for key := range e.lookup {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(h, "%q %q\n", key, e.lookup[key])
+	}
+	e.digest = hex.EncodeToString(h.Sum(nil))
+	return e.digest
+}
+
+// memoryCache is a DocumentCache that keeps Documents in memory.
+type memoryCache struct {
+	mu   sync.RWMutex
+	docs map[string]*Document
+}
+
+// NewMemoryCache returns an empty DocumentCache that keeps Documents in
+// memory.
+func NewMemoryCache() DocumentCache {
+	return &memoryCache{docs: map[string]*Document{}}
+}
+
+// Get returns a copy of the Document stored under `key`, so that changes to
+// it (e.g., to its tokens' labels) don't reach the cache.
+func (c *memoryCache) Get(key string) (*Document, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	doc, found := c.docs[key]
+	if !found {
+		return nil, false
+	}
+	return copyDocument(doc), true
+}
+
+// Put stores a copy of `doc` under `key`.
+func (c *memoryCache) Put(key string, doc *Document) {
+	doc = copyDocument(doc)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.docs[key] = doc
+}
+
+// copyDocument returns a copy of `doc` that shares none of its tokens,
+// entities, or other slices.
+func copyDocument(doc *Document) *Document {
+	record := marshalDocument(doc)
+	record.Sentences = append(record.Sentences[:0:0], record.Sentences...)
+	record.SentStarts = append(record.SentStarts[:0:0], record.SentStarts...)
+	record.ListItems = append(record.ListItems[:0:0], record.ListItems...)
+	record.Warnings = append(record.Warnings[:0:0], record.Warnings...)
+	record.Sentiments = append(record.Sentiments[:0:0], record.Sentiments...)
+	record.Trace = append(record.Trace[:0:0], record.Trace...)
+	record.Quality = append(record.Quality[:0:0], record.Quality...)
+	record.Tables = append(record.Tables[:0:0], record.Tables...)
+	for i := range record.Tables {
+		rows := record.Tables[i].Rows[:0:0]
+		for _, row := range record.Tables[i].Rows {
+			rows = append(rows, append(row[:0:0], row...))
+		}
+		record.Tables[i].Rows = rows
+	}
+	return unmarshalDocument(record)
+}
+
+// cachedDocument is the serializable form of a Document.
+type cachedDocument struct {
+	Text       string
+	Tokens     []Token
+	Entities   []Entity // Without their Tokens; see EntityTokens.
+	Sentences  []Sentence
+	SentStarts []int
+	Tables     []Table
+	ListItems  []ListItem
+	Offsets    [][]cachedEdit // From the last transformation to the first.
+	Warnings   []string
+	Sentiment  Sentiment
+	Sentiments []Sentiment
+	Trace      []NERStep
+	Quality    []QualityWarning
+
+	// EntityTokens holds the indices (in Tokens) of each entity's tokens.
+	EntityTokens [][]int
+}
+
+type cachedEdit struct {
+	Start, End         int
+	OrigStart, OrigEnd int
+}
+
+// marshalDocument returns the serializable form of `doc`.
+func marshalDocument(doc *Document) cachedDocument {
+	record := cachedDocument{
+		Text:       doc.Text,
+		Tokens:     doc.Tokens(),
+		Sentences:  doc.sentences,
+		SentStarts: doc.sentStarts,
+		Tables:     doc.tables,
+		ListItems:  doc.listItems,
+		Warnings:   doc.warnings,
+		Sentiment:  doc.sentiment,
+		Sentiments: doc.sentiments,
+		Trace:      doc.trace,
+		Quality:    doc.quality,
+	}
+
+	index := make(map[*Token]int, len(doc.tokens))
+	for i, tok := range doc.tokens {
+		index[tok] = i
+	}
+	for k, ent := range doc.entities {
+		indices := []int{}
+		for _, tok := range doc.entParts[k] {
+			if i, found := index[tok]; found {
+				indices = append(indices, i)
+			}
+		}
+		record.Entities = append(record.Entities, ent)
+		record.EntityTokens = append(record.EntityTokens, indices)
+	}
+
+	for m := doc.offsets; m != nil; m = m.prev {
+		edits := make([]cachedEdit, len(m.edits))
+		for i, e := range m.edits {
+			edits[i] = cachedEdit{e.start, e.end, e.origStart, e.origEnd}
+		}
+		record.Offsets = append(record.Offsets, edits)
+	}
+	return record
+}
+
+// unmarshalDocument returns the Document serialized as `record`.
+func unmarshalDocument(record cachedDocument) *Document {
+	doc := &Document{
+		Text:       record.Text,
+		sentences:  record.Sentences,
+		sentStarts: record.SentStarts,
+		tables:     record.Tables,
+		listItems:  record.ListItems,
+		warnings:   record.Warnings,
+		sentiment:  record.Sentiment,
+		sentiments: record.Sentiments,
+		trace:      record.Trace,
+		quality:    record.Quality,
+	}
+	for i := range record.Tokens {
+		doc.tokens = append(doc.tokens, &record.Tokens[i])
+	}
+	for i, ent := range record.Entities {
+		parts := []*Token{}
+		for _, j := range record.EntityTokens[i] {
+			parts = append(parts, doc.tokens[j])
+		}
+		doc.entities = append(doc.entities, ent)
+		doc.entParts = append(doc.entParts, parts)
+	}
+	for i := len(record.Offsets) - 1; i >= 0; i-- {
+		m := &OffsetMap{prev: doc.offsets}
+		for _, e := range record.Offsets[i] {
+			m.edits = append(m.edits, offsetEdit{e.Start, e.End, e.OrigStart, e.OrigEnd})
+		}
+		doc.offsets = m
+	}
+	doc.indexSentences()
+	return doc
+}
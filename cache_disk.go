@@ -0,0 +1,59 @@
+package prose
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// diskCache is a DocumentCache that stores Documents as files in a
+// directory.
+type diskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DocumentCache that stores Documents in the
+// directory `dir`, one file per Document, creating it if needed. The
+// directory can be shared by processes and reused across runs.
+//
+// Errors reading or writing the cache are treated as misses.
+func NewDiskCache(dir string) (DocumentCache, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create cache directory: %w", err)
+	}
+	return &diskCache{dir: dir}, nil
+}
+
+func (c *diskCache) Get(key string) (*Document, bool) {
+	file, err := os.Open(filepath.Join(c.dir, key+".gob"))
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	var record cachedDocument
+	if err = gob.NewDecoder(file).Decode(&record); err != nil {
+		return nil, false
+	}
+	return unmarshalDocument(record), true
+}
+
+func (c *diskCache) Put(key string, doc *Document) {
+	// Write to a temporary file first so that readers never see a partial
+	// Document.
+	temp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	err = gob.NewEncoder(temp).Encode(marshalDocument(doc))
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), filepath.Join(c.dir, key+".gob"))
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+	}
+}
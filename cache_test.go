@@ -0,0 +1,87 @@
+package prose
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cacheText = "Apple hired Tim Cook in Cupertino.  “Résumé” text!\n\nIt went well."
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache()
+
+	first, err := NewDocument(cacheText, WithDocumentCache(cache))
+	require.NoError(t, err)
+	second, err := NewDocument(cacheText, WithDocumentCache(cache))
+	require.NoError(t, err)
+	assert.Equal(t, first.Entities(), second.Entities())
+	assert.Equal(t, first.tokens, second.tokens)
+
+	// A different configuration is a miss.
+	other, err := NewDocument(cacheText, WithDocumentCache(cache), WithExtraction(false))
+	require.NoError(t, err)
+	assert.Empty(t, other.Entities())
+
+	// Changes to a Document don't reach the cache.
+	second.tokens[0].Label = "B-CHANGED"
+	second.entParts[0][0].Text = "Changed"
+	third, err := NewDocument(cacheText, WithDocumentCache(cache))
+	require.NoError(t, err)
+	assert.Equal(t, first.tokens, third.tokens)
+	for i := range third.entities {
+		assert.Contains(t, third.tokens, third.entParts[i][0])
+	}
+
+	// Pre-tokenized input isn't cached, and neither is text tokenized with a
+	// custom TokenTester.
+	assert.Equal(t, "", cacheKey(cacheText, first.Model, Config{Tokens: []Token{}}))
+	tester := UsingIsUnsplittable(func(string) bool { return false })
+	assert.Equal(t, "", cacheKey(cacheText, first.Model, Config{Tokenizer: NewIterTokenizer(tester)}))
+
+	// Models with the same name but different contents have different keys,
+	// as does a model after it's pruned.
+	product, err := ModelFromDisk(filepath.Join(testdata, "PRODUCT"))
+	require.NoError(t, err)
+	renamed := *product
+	renamed.Name = first.Model.Name
+	config := DefaultConfig()
+	before := cacheKey(cacheText, product, config)
+	assert.NotEqual(t, cacheKey(cacheText, first.Model, config), cacheKey(cacheText, &renamed, config))
+	_, err = product.Prune(0.5)
+	require.NoError(t, err)
+	assert.NotEqual(t, before, cacheKey(cacheText, product, config))
+
+	config.StrictTokenizer = true
+	assert.NotEqual(t, cacheKey(cacheText, first.Model, DefaultConfig()), cacheKey(cacheText, first.Model, config))
+}
+
+func TestDiskCache(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir())
+	require.NoError(t, err)
+
+	opts := []DocOpt{WithDocumentCache(cache), WithUnicodeNormalization(UnicodeNFKC)}
+	first, err := NewDocument(cacheText, opts...)
+	require.NoError(t, err)
+
+	key := cacheKey(cacheText, first.Model, first.Config())
+	stored, found := cache.Get(key)
+	require.True(t, found)
+	assert.Equal(t, first.Tokens(), stored.Tokens())
+	assert.Equal(t, first.Sentences(), stored.Sentences())
+
+	second, err := NewDocument(cacheText, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, first.Entities(), second.Entities())
+	assert.Equal(t, first.Sentences(), second.Sentences())
+	assert.Equal(t, first.OffsetMap().Transformed(40), second.OffsetMap().Transformed(40))
+	for i := range second.Entities() {
+		require.NotEmpty(t, second.entParts[i])
+		assert.Contains(t, second.tokens, second.entParts[i][0])
+	}
+
+	_, found = cache.Get("missing")
+	assert.False(t, found)
+}
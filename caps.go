@@ -0,0 +1,136 @@
+package prose
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A CapsPolicy determines how NewDocument handles runs of text written in
+// all capitals, such as headings and legal boilerplate, which the tagger
+// and NER models (trained on mixed-case text) handle poorly.
+type CapsPolicy int
+
+const (
+	// CapsIgnore processes all-caps text as-is (the default).
+	CapsIgnore CapsPolicy = iota
+	// CapsTruecase tags and classifies all-caps runs as if they were
+	// written in mixed case: common words are lowercased and everything else
+	// is capitalized. Tokens and entities keep their original text.
+	CapsTruecase
+)
+
+// minCapsRun is the number of words an all-caps run must have for it to be
+// truecased; shorter runs are usually acronyms ("IBM", "NASA CEO").
+const minCapsRun = 3
+
+// WithCapsPolicy sets the CapsPolicy used by NewDocument.
+func WithCapsPolicy(policy CapsPolicy) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Caps = policy
+	}
+}
+
+// truecaseTokens truecases the all-caps runs of each section's tokens in
+// place, returning a function that restores their original text.
+func truecaseTokens(tagger *PerceptronTagger, sections [][]*Token) func() {
+	originals := map[*Token]string{}
+	for _, tokens := range sections {
+		start := 0
+		for start < len(tokens) {
+			end, words := start, 0
+			for end < len(tokens) && !hasLower(tokens[end].Text) {
+				if capsLetters(tokens[end].Text) >= 2 {
+					words++
+				}
+				end++
+			}
+			if words >= minCapsRun {
+				for i := start; i < end; i++ {
+					text := tokens[i].Text
+					if capsLetters(text) == 0 {
+						continue
+					}
+					initial := i == 0 || isSentenceEnd(tokens[i-1].Text)
+					originals[tokens[i]] = text
+					tokens[i].Text = truecase(tagger, text, initial)
+				}
+				// Words such as "New" (York) or "United" (Nations) start
+				// names.
+				for i := end - 2; i >= start; i-- {
+					next := tokens[i+1].Text
+					if nameStarts[tokens[i].Text] && next != strings.ToLower(next) {
+						tokens[i].Text = strings.Title(tokens[i].Text)
+					}
+				}
+			}
+			start = end + 1
+		}
+	}
+
+	return func() {
+		for tok, text := range originals {
+			tok.Text = text
+		}
+	}
+}
+
+// truecase guesses the mixed-case form of the all-caps word `word`.
+func truecase(tagger *PerceptronTagger, word string, initial bool) string {
+	lower := strings.ToLower(word)
+	if !initial && isCommonWord(tagger, lower) {
+		return lower
+	}
+	runes := []rune(lower)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// isCommonWord determines if `word`, or its stem, is a common lowercase
+// word.
+func isCommonWord(tagger *PerceptronTagger, word string) bool {
+	for _, suffix := range []string{"", "s", "es", "ed", "d", "ing"} {
+		stem := strings.TrimSuffix(word, suffix)
+		if suffix != "" && (stem == word || len(stem) < 3) {
+			continue
+		} else if _, found := tagger.model.tagMap[stem]; found {
+			return true
+		} else if stringInSlice(stem, enWordList) {
+			return true
+		}
+	}
+	return false
+}
+
+// capsLetters returns the number of letters in `text` if they're all
+// capitals, and zero otherwise.
+func capsLetters(text string) int {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsUpper(r) {
+			letters++
+		} else if unicode.IsLetter(r) {
+			return 0
+		}
+	}
+	return letters
+}
+
+func hasLower(text string) bool {
+	for _, r := range text {
+		if unicode.IsLower(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSentenceEnd(text string) bool {
+	return text == "." || text == "!" || text == "?"
+}
+
+// nameStarts are common words that frequently start multi-word names.
+var nameStarts = map[string]bool{
+	"new": true, "north": true, "south": true, "east": true, "west": true,
+	"united": true, "great": true, "saint": true, "san": true, "los": true,
+	"general": true, "national": true, "royal": true, "first": true,
+}
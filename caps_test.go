@@ -0,0 +1,47 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapsTruecase(t *testing.T) {
+	text := "JOHN SMITH WORKS AT GOOGLE IN NEW YORK."
+
+	doc, err := NewDocument(text, WithCapsPolicy(CapsTruecase))
+	require.NoError(t, err)
+	tokens := doc.Tokens()
+	assert.Equal(t, "WORKS", tokens[2].Text, "tokens keep their text")
+	assert.Equal(t, "VBZ", tokens[2].Tag)
+	assert.Equal(t, "IN", tokens[3].Tag)
+
+	labels := map[string]string{}
+	for _, ent := range doc.Entities() {
+		labels[ent.Text] = ent.Label
+	}
+	assert.Equal(t, "PERSON", labels["JOHN SMITH"])
+	assert.Contains(t, labels, "NEW YORK")
+
+	// Short all-caps runs are usually acronyms.
+	sections := [][]*Token{{{Text: "IBM"}, {Text: "CEO"}, {Text: "hired"}, {Text: "THE"}}}
+	restore := truecaseTokens(doc.Model.tagger, sections)
+	assert.Equal(t, "IBM", sections[0][0].Text)
+	assert.Equal(t, "THE", sections[0][3].Text)
+	restore()
+
+	sections = [][]*Token{{{Text: "THE"}, {Text: "NEW"}, {Text: "YORK"}, {Text: "OFFICE"}}}
+	restore = truecaseTokens(doc.Model.tagger, sections)
+	assert.Equal(t, []string{"The", "New", "York", "office"}, tokenTexts(sections[0]))
+	restore()
+	assert.Equal(t, []string{"THE", "NEW", "YORK", "OFFICE"}, tokenTexts(sections[0]))
+}
+
+func tokenTexts(tokens []*Token) []string {
+	texts := make([]string, len(tokens))
+	for i, tok := range tokens {
+		texts[i] = tok.Text
+	}
+	return texts
+}
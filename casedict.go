@@ -0,0 +1,166 @@
+package prose
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A CaseDictionary maps the variants of words found in noisy text to their
+// usual form, such as "acme" (or "ACME") to "Acme" and "iNDEMNITY" to
+// "Indemnity", so that the models see text written as they were trained.
+type CaseDictionary struct {
+	forms map[string]string // The usual form of each lowercase variant.
+}
+
+// NewCaseDictionary creates an empty CaseDictionary.
+func NewCaseDictionary() *CaseDictionary {
+	return &CaseDictionary{forms: make(map[string]string)}
+}
+
+// LearnCaseDictionary learns the usual form of each word that occurs at
+// least `minCount` times in `texts`: the most common of its forms, ignoring
+// those at the start of a sentence or written in all capitals, which don't
+// reflect how the word is usually written.
+func LearnCaseDictionary(texts []string, minCount int) *CaseDictionary {
+	tokenizer := NewIterTokenizer()
+	counts := map[string]map[string]int{}
+	for _, text := range texts {
+		tokens := tokenizer.Tokenize(text)
+		for i, tok := range tokens {
+			if i == 0 || isSentenceEnd(tokens[i-1].Text) || capsLetters(tok.Text) > 1 {
+				continue
+			} else if strings.IndexFunc(tok.Text, unicode.IsLetter) < 0 {
+				continue
+			}
+			key := strings.ToLower(tok.Text)
+			if counts[key] == nil {
+				counts[key] = map[string]int{}
+			}
+			counts[key][tok.Text]++
+		}
+	}
+
+	dict := NewCaseDictionary()
+	for key, forms := range counts {
+		best, total := "", 0
+		for form, count := range forms {
+			total += count
+			// Ties go to the form that sorts first, for determinism.
+			if count > forms[best] || (count == forms[best] && form < best) {
+				best = form
+			}
+		}
+		if total >= minCount {
+			dict.forms[key] = best
+		}
+	}
+	return dict
+}
+
+// Add makes `form` the usual form of `variant` (and of the other variants
+// that only differ from it in case), e.g., to correct a spelling.
+func (d *CaseDictionary) Add(variant, form string) {
+	d.forms[strings.ToLower(variant)] = form
+}
+
+// Lookup returns the usual form of `word`, if it's known.
+func (d *CaseDictionary) Lookup(word string) (string, bool) {
+	form, found := d.forms[strings.ToLower(word)]
+	return form, found
+}
+
+// Len returns the number of variants in the dictionary.
+func (d *CaseDictionary) Len() int {
+	return len(d.forms)
+}
+
+// normalize returns the usual form of `word`, or `word` if it isn't known;
+// if `initial` is true, the word starts a sentence and stays capitalized.
+func (d *CaseDictionary) normalize(word string, initial bool) string {
+	form, found := d.Lookup(word)
+	if !found {
+		return word
+	} else if initial && form != "" {
+		first, size := utf8.DecodeRuneInString(form)
+		form = string(unicode.ToUpper(first)) + form[size:]
+	}
+	return form
+}
+
+// WriteTo writes the dictionary to `w` as ReadCaseDictionary reads it: one
+// "variant<TAB>form" pair per line, sorted by variant.
+func (d *CaseDictionary) WriteTo(w io.Writer) (int64, error) {
+	variants := make([]string, 0, len(d.forms))
+	for variant := range d.forms {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+
+	counter := &countingWriter{w: w}
+	buf := bufio.NewWriter(counter)
+	for _, variant := range variants {
+		buf.WriteString(variant + "\t" + d.forms[variant] + "\n")
+	}
+	if err := buf.Flush(); err != nil {
+		return counter.n, fmt.Errorf("unable to write case dictionary: %w", err)
+	}
+	return counter.n, nil
+}
+
+// ReadCaseDictionary reads a CaseDictionary written by WriteTo. Blank lines
+// and lines starting with "#" are ignored.
+func ReadCaseDictionary(r io.Reader) (*CaseDictionary, error) {
+	dict := NewCaseDictionary()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			return nil, fmt.Errorf("unable to read case dictionary: line %d: expected \"variant<TAB>form\"", line)
+		}
+		dict.Add(fields[0], fields[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read case dictionary: %w", err)
+	}
+	return dict, nil
+}
+
+// WithCaseDictionary tags and classifies tokens as if they were written in
+// their usual form according to `dict`. Tokens and entities keep their
+// original text. Documents created with it aren't cached, since the
+// dictionary may change.
+func WithCaseDictionary(dict *CaseDictionary) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.CaseDictionary = dict
+	}
+}
+
+// normalizeTokens replaces the text of each section's tokens in place with
+// its usual form according to `dict`, returning a function that restores
+// their original text.
+func normalizeTokens(dict *CaseDictionary, sections [][]*Token) func() {
+	originals := map[*Token]string{}
+	for _, tokens := range sections {
+		for i, tok := range tokens {
+			initial := i == 0 || isSentenceEnd(tokens[i-1].Text)
+			if form := dict.normalize(tok.Text, initial); form != tok.Text {
+				originals[tok] = tok.Text
+				tok.Text = form
+			}
+		}
+	}
+	return func() {
+		for tok, text := range originals {
+			tok.Text = text
+		}
+	}
+}
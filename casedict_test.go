@@ -0,0 +1,63 @@
+package prose
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var caseCorpus = []string{
+	"Yesterday, Acme Corp signed a deal with John Smith in Boston.",
+	"The deal between Acme Corp and John Smith includes an Indemnity clause.",
+	"Lawyers for Acme Corp said the Indemnity was standard. ACME CORP declined to comment.",
+}
+
+func TestLearnCaseDictionary(t *testing.T) {
+	dict := LearnCaseDictionary(caseCorpus, 2)
+	for variant, form := range map[string]string{
+		"acme": "Acme", "CORP": "Corp", "iNDEMNITY": "Indemnity", "deal": "deal"} {
+		got, found := dict.Lookup(variant)
+		assert.True(t, found, variant)
+		assert.Equal(t, form, got, variant)
+	}
+	_, found := dict.Lookup("boston")
+	assert.False(t, found, "rare words aren't learned")
+	_, found = dict.Lookup("yesterday")
+	assert.False(t, found, "sentence-initial words aren't learned")
+
+	assert.Equal(t, "Deal", dict.normalize("deal", true))
+	dict.Add("colour", "color")
+	assert.Equal(t, "color", dict.normalize("COLOUR", false))
+
+	var buf bytes.Buffer
+	_, err := dict.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "acme\tAcme\n")
+	read, err := ReadCaseDictionary(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, dict.forms, read.forms)
+
+	_, err = ReadCaseDictionary(strings.NewReader("# comment\nacme Acme\n"))
+	assert.Error(t, err)
+}
+
+func TestWithCaseDictionary(t *testing.T) {
+	dict := LearnCaseDictionary(caseCorpus, 2)
+	text := "yesterday, acme corp signed a deal with john smith in boston."
+
+	doc, err := NewDocument(text)
+	require.NoError(t, err)
+	assert.Empty(t, doc.Entities())
+
+	doc, err = NewDocument(text, WithCaseDictionary(dict))
+	require.NoError(t, err)
+	entities := []string{}
+	for _, ent := range doc.Entities() {
+		entities = append(entities, ent.Text)
+	}
+	assert.Equal(t, []string{"acme corp", "john smith"}, entities)
+	assert.Equal(t, "acme", doc.Tokens()[2].Text, "tokens keep their text")
+}
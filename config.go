@@ -0,0 +1,63 @@
+package prose
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultConfig returns the Config used by NewDocument when no options are
+// given.
+func DefaultConfig() Config {
+	return defaultOpts
+}
+
+// UsingConfig replaces the Document's configuration with `config`; options
+// given after it adjust it further.
+//
+// For example,
+//
+//	config := prose.DefaultConfig()
+//	config.Extract = false
+//	doc, err := prose.NewDocument("...", prose.UsingConfig(config))
+func UsingConfig(config Config) DocOpt {
+	return func(doc *Document, opts *Config) {
+		err := opts.err
+		*opts = config
+		// An earlier invalid option still fails the Document.
+		opts.err = err
+	}
+}
+
+// Validate reports the first invalid setting in `c`, if any.
+func (c Config) Validate() error {
+	switch {
+	case c.err != nil:
+		return c.err
+	case c.Guard < PolicyIgnore || c.Guard > PolicyError:
+		return fmt.Errorf("unknown input policy %d", c.Guard)
+	case c.Unicode < UnicodeNone || c.Unicode > UnicodeNFKC:
+		return fmt.Errorf("unknown unicode form %d", c.Unicode)
+	case c.Tables < TablesIgnore || c.Tables > TablesRows:
+		return fmt.Errorf("unknown table policy %d", c.Tables)
+	case c.Trim&^(TrimPunctuation|TrimDeterminers) != 0:
+		return fmt.Errorf("unknown entity trim %d", c.Trim)
+	case c.Caps < CapsIgnore || c.Caps > CapsTruecase:
+		return fmt.Errorf("unknown caps policy %d", c.Caps)
+	case c.Consistency < ConsistencyOff || c.Consistency > ConsistencyFirst:
+		return fmt.Errorf("unknown consistency policy %d", c.Consistency)
+	case c.MinConfidence < 0 || c.MinConfidence > 1:
+		return fmt.Errorf("minimum confidence %v is outside of [0, 1]", c.MinConfidence)
+	case validateSpans(c.Focus) != nil:
+		return validateSpans(c.Focus)
+	case validateTokens(c.Tokens) != nil:
+		return validateTokens(c.Tokens)
+	case c.Tokens != nil && c.Boundaries != nil:
+		return errors.New("boundaries can't be used with pre-tokenized input")
+	}
+	return nil
+}
+
+// Config returns the configuration `doc` was created with.
+func (doc *Document) Config() Config {
+	return doc.config
+}
@@ -0,0 +1,49 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	config := DefaultConfig()
+	require.NoError(t, config.Validate())
+
+	config.Extract = false
+	config.Tag = false
+	doc, err := NewDocument("Jane met Bob in Paris.", UsingConfig(config), WithSentiment(true))
+	require.NoError(t, err)
+	assert.Empty(t, doc.Entities())
+	assert.False(t, doc.Config().Extract)
+	assert.True(t, doc.Config().Sentiment)
+
+	doc, err = NewDocument("Jane met Bob.", WithExtraction(false),
+		UsingKnownEntities(map[string]bool{"Jane": true}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"Jane": true}, doc.Config().KnownEntities)
+
+	// Every option, including the model, is reflected in Config.
+	model, err := ModelFromData("empty")
+	require.NoError(t, err)
+	doc, err = NewDocument("Jane met Bob.", UsingModel(model), WithExtraction(false))
+	require.NoError(t, err)
+	assert.Same(t, model, doc.Config().Model)
+	assert.Same(t, model, doc.Model)
+
+	invalid := []Config{
+		{Guard: PolicyError + 1},
+		{Unicode: UnicodeNFKC + 1},
+		{Tables: -1},
+		{Trim: TrimDeterminers << 1},
+	}
+	for _, c := range invalid {
+		assert.Error(t, c.Validate())
+		_, err = NewDocument("Hello.", UsingConfig(c))
+		assert.Error(t, err)
+	}
+
+	_, err = NewDocument("Hello.", Preset("bogus"), UsingConfig(DefaultConfig()))
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package prose
+
+import "strings"
+
+// A ConsistencyPolicy determines how NewDocument reconciles the labels of
+// entities that are written the same way, following the "one sense per
+// discourse" heuristic: within a document, "Mercury" is usually either a
+// company or a planet, not both.
+type ConsistencyPolicy int
+
+const (
+	// ConsistencyOff keeps each entity's label as predicted (the default).
+	ConsistencyOff ConsistencyPolicy = iota
+	// ConsistencyMajority relabels entities with the most common label of
+	// their surface form; forms without a single most common label are left
+	// as-is.
+	ConsistencyMajority
+	// ConsistencyFirst relabels entities with the label of their surface
+	// form's first mention, which is often the one introduced with the most
+	// context.
+	ConsistencyFirst
+)
+
+// WithConsistency sets the ConsistencyPolicy used by NewDocument. Entities
+// with labels that were given rather than predicted (by UsingTokens or a
+// gazetteer) are left as-is, and don't count towards the others' labels.
+func WithConsistency(policy ConsistencyPolicy) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Consistency = policy
+	}
+}
+
+// reconcileLabels relabels `entities`, and their tokens (`parts`), according
+// to `policy`; entities with any of the `given` tokens are skipped.
+func reconcileLabels(entities []Entity, parts [][]*Token, given map[*Token]bool, policy ConsistencyPolicy) {
+	if policy == ConsistencyOff {
+		return
+	}
+
+	forms := []string{}
+	mentions := map[string][]int{}
+	for i := range entities {
+		if hasGiven(parts[i], given) {
+			continue
+		}
+		form := surfaceForm(parts[i])
+		if _, found := mentions[form]; !found {
+			forms = append(forms, form)
+		}
+		mentions[form] = append(mentions[form], i)
+	}
+
+	for _, form := range forms {
+		indices := mentions[form]
+		label := entities[indices[0]].Label
+		if policy == ConsistencyMajority {
+			label = majorityLabel(entities, indices)
+		}
+		if label == "" {
+			continue
+		}
+		for _, i := range indices {
+			relabelEntity(&entities[i], parts[i], label)
+		}
+	}
+}
+
+// givenLabels returns the tokens of `sections` that are labeled before
+// the NER runs.
+func givenLabels(sections [][]*Token) map[*Token]bool {
+	given := map[*Token]bool{}
+	for _, tokens := range sections {
+		for _, tok := range tokens {
+			if tok.Label != "" {
+				given[tok] = true
+			}
+		}
+	}
+	return given
+}
+
+// hasGiven determines if any of `parts` is one of the `given` tokens.
+func hasGiven(parts []*Token, given map[*Token]bool) bool {
+	for _, tok := range parts {
+		if given[tok] {
+			return true
+		}
+	}
+	return false
+}
+
+// surfaceForm returns the text by which mentions of the same entity are
+// identified.
+func surfaceForm(parts []*Token) string {
+	words := make([]string, len(parts))
+	for i, tok := range parts {
+		words[i] = tok.Text
+	}
+	return strings.Join(words, " ")
+}
+
+// majorityLabel returns the most common label of the entities at `indices`,
+// or "" if there's a tie.
+func majorityLabel(entities []Entity, indices []int) string {
+	counts := map[string]int{}
+	for _, i := range indices {
+		counts[entities[i].Label]++
+	}
+	label, best, tied := "", 0, false
+	for _, i := range indices {
+		candidate := entities[i].Label
+		if n := counts[candidate]; n > best {
+			label, best, tied = candidate, n, false
+		} else if n == best && candidate != label {
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return label
+}
+
+// relabelEntity changes the label of `ent` and its tokens (`parts`) to
+// `label`.
+func relabelEntity(ent *Entity, parts []*Token, label string) {
+	if ent.Label == label {
+		return
+	}
+	ent.Label = label
+	for _, tok := range parts {
+		if i := strings.Index(tok.Label, "-"); i >= 0 {
+			tok.Label = tok.Label[:i+1] + label
+		}
+	}
+}
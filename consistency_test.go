@@ -0,0 +1,71 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func consistencyTokens() []Token {
+	tokens := []Token{}
+	for _, label := range []string{"PERSON", "ORG", "ORG", "PERSON", "ORG"} {
+		tokens = append(tokens, Token{Text: "Mercury", Label: "B-" + label},
+			Token{Text: "rose", Label: "O"}, Token{Text: ".", Label: "O"})
+	}
+	return tokens
+}
+
+func TestConsistency(t *testing.T) {
+	labels := func(policy ConsistencyPolicy, given map[*Token]bool) []string {
+		tokens := consistencyTokens()
+		entities, parts := []Entity{}, [][]*Token{}
+		for i := 0; i < len(tokens); i += 3 {
+			entities = append(entities, Entity{Label: tokens[i].Label[2:]})
+			parts = append(parts, []*Token{&tokens[i]})
+		}
+		if given != nil {
+			given[parts[0][0]] = true
+		}
+		reconcileLabels(entities, parts, given, policy)
+
+		labels := []string{}
+		for i, ent := range entities {
+			labels = append(labels, ent.Label)
+			assert.Equal(t, "B-"+ent.Label, parts[i][0].Label)
+		}
+		return labels
+	}
+
+	assert.Equal(t, []string{"PERSON", "ORG", "ORG", "PERSON", "ORG"}, labels(ConsistencyOff, nil))
+	assert.Equal(t, []string{"ORG", "ORG", "ORG", "ORG", "ORG"}, labels(ConsistencyMajority, nil))
+	assert.Equal(t, []string{"PERSON", "PERSON", "PERSON", "PERSON", "PERSON"}, labels(ConsistencyFirst, nil))
+
+	// Given labels are kept, and don't count.
+	assert.Equal(t, []string{"PERSON", "ORG", "ORG", "ORG", "ORG"},
+		labels(ConsistencyFirst, map[*Token]bool{}))
+
+	// So labels given by UsingTokens are never changed.
+	doc, err := NewDocument("", UsingTokens(consistencyTokens()),
+		WithSegmentation(false), WithConsistency(ConsistencyMajority))
+	require.NoError(t, err)
+	for i, ent := range doc.Entities() {
+		assert.Equal(t, consistencyTokens()[3*i].Label, "B-"+ent.Label)
+	}
+
+	_, err = NewDocument("", WithConsistency(ConsistencyPolicy(9)))
+	assert.Error(t, err)
+}
+
+func TestConsistencyTie(t *testing.T) {
+	entities := []Entity{{Label: "ORG"}, {Label: "PERSON"}, {Label: "GPE"}}
+	parts := [][]*Token{
+		{{Text: "Mercury", Label: "B-ORG"}},
+		{{Text: "Mercury", Label: "B-PERSON"}},
+		{{Text: "Paris", Label: "B-GPE"}},
+	}
+	reconcileLabels(entities, parts, nil, ConsistencyMajority)
+	assert.Equal(t, "ORG", entities[0].Label)
+	assert.Equal(t, "PERSON", entities[1].Label)
+	assert.Equal(t, "GPE", entities[2].Label)
+}
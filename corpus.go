@@ -0,0 +1,115 @@
+package prose
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// An EncodedCorpus is labeled data featurized as the NER sees it, so that a
+// NER's weights can be trained by an external optimizer (e.g., liblinear's
+// multinomial logistic regression) and loaded back with UsingWeights.
+type EncodedCorpus struct {
+	// X is a sparse binary matrix with a row per token: the indices (into
+	// Features) of the features that are present.
+	X [][]int
+
+	// Y holds the index (into Labels) of each token's label.
+	Y []int
+
+	// Features names the columns of X, as "name-value" (e.g., "word-Acme");
+	// "bias-True" is present in every row, so no separate intercept is
+	// needed.
+	Features []string
+
+	// Labels lists the IOB labels of the tokens (e.g., "B-ORG").
+	Labels []string
+
+	tokenizer string
+}
+
+// EncodeCorpus featurizes `data` as UsingEntities does for training: it's
+// tokenized by NewIterTokenizer and tagged by the embedded POS tagger.
+func EncodeCorpus(data []EntityContext) (*EncodedCorpus, error) {
+	tagger, err := NewPerceptronTagger()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load default POS tagger: %w", err)
+	}
+	tokenizer := NewIterTokenizer()
+
+	encoded := &EncodedCorpus{
+		X: [][]int{}, Y: []int{}, Features: []string{}, Labels: []string{},
+		tokenizer: tokenizerFingerprint(tokenizer)}
+	features, labels := map[string]int{}, map[string]int{}
+	for _, entry := range makeCorpus(data, tagger, tokenizer, TrainingOptions{}) {
+		row := []int{}
+		for i, name := range featureOrder {
+			if absentFeature(i, entry.features[i]) {
+				continue
+			}
+			key := name + "-" + entry.features[i]
+			if _, found := features[key]; !found {
+				features[key] = len(encoded.Features)
+				encoded.Features = append(encoded.Features, key)
+			}
+			row = append(row, features[key])
+		}
+		if _, found := labels[entry.label]; !found {
+			labels[entry.label] = len(encoded.Labels)
+			encoded.Labels = append(encoded.Labels, entry.label)
+		}
+		encoded.X = append(encoded.X, row)
+		encoded.Y = append(encoded.Y, labels[entry.label])
+	}
+	return encoded, nil
+}
+
+// UsingWeights creates a NER from weights trained on `corpus`: `weights`
+// has a row per label and a column per feature (weights[i][j] is the weight
+// of corpus.Features[j] for corpus.Labels[i]), in natural-log units, as
+// multinomial logistic regression produces. Features whose weights are all
+// zero are dropped.
+func UsingWeights(corpus *EncodedCorpus, weights [][]float64) DataSource {
+	return func(model *Model) {
+		classifier, err := corpus.classifier(weights)
+		if err != nil {
+			model.err = err
+			return
+		}
+		model.extracter = newTrainedEntityExtracter(classifier)
+		model.extracter.tokenizer = corpus.tokenizer
+	}
+}
+
+// classifier builds the binaryMaxentClassifier with the joint-features of
+// `weights` (see UsingWeights).
+func (c *EncodedCorpus) classifier(weights [][]float64) (*binaryMaxentClassifier, error) {
+	if len(weights) != len(c.Labels) {
+		return nil, fmt.Errorf("%d rows of weights for %d labels", len(weights), len(c.Labels))
+	}
+	mapping := map[string]int{}
+	scaled := []float64{}
+	for i, row := range weights {
+		if len(row) != len(c.Features) {
+			return nil, fmt.Errorf("%d weights for %d features in row %d",
+				len(row), len(c.Features), i)
+		}
+		for j, w := range row {
+			if math.IsNaN(w) || math.IsInf(w, 0) {
+				return nil, fmt.Errorf("invalid weight %v in row %d, column %d", w, i, j)
+			} else if w == 0 {
+				continue
+			}
+			mapping[strings.Join([]string{c.Features[j], c.Labels[i]}, "-")] = len(scaled)
+			// The classifier's scores are base-2 logarithms.
+			scaled = append(scaled, w/math.Ln2)
+		}
+	}
+	if len(mapping) == 0 {
+		return nil, errors.New("all weights are zero")
+	}
+	// The GIS correction feature is only used in training.
+	scaled = append(scaled, 0)
+	return newMaxentClassifier(scaled, mapping, append([]string{}, c.Labels...)), nil
+}
@@ -0,0 +1,72 @@
+package prose
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trainSoftmax fits multinomial logistic regression to `corpus` by gradient
+// descent, standing in for an external optimizer.
+func trainSoftmax(corpus *EncodedCorpus, iterations int, rate float64) [][]float64 {
+	weights := make([][]float64, len(corpus.Labels))
+	for i := range weights {
+		weights[i] = make([]float64, len(corpus.Features))
+	}
+	for it := 0; it < iterations; it++ {
+		for n, row := range corpus.X {
+			scores := make([]float64, len(weights))
+			total := 0.0
+			for i := range weights {
+				for _, j := range row {
+					scores[i] += weights[i][j]
+				}
+				scores[i] = math.Exp(scores[i])
+				total += scores[i]
+			}
+			for i := range weights {
+				gradient := scores[i] / total
+				if i == corpus.Y[n] {
+					gradient--
+				}
+				for _, j := range row {
+					weights[i][j] -= rate * gradient
+				}
+			}
+		}
+	}
+	return weights
+}
+
+func TestEncodeCorpus(t *testing.T) {
+	data, err := ReadCoNLL2003(strings.NewReader(conll2003))
+	require.NoError(t, err)
+	corpus, err := EncodeCorpus(data)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, corpus.X)
+	require.Len(t, corpus.Y, len(corpus.X))
+	assert.Contains(t, corpus.Labels, "B-PER")
+	assert.Contains(t, corpus.Features, "word-Blackburn")
+	for _, row := range corpus.X {
+		assert.Equal(t, "bias-True", corpus.Features[row[0]])
+	}
+	assert.Equal(t, "B-ORG", corpus.Labels[corpus.Y[0]])
+
+	model, err := ModelFromData("external", UsingWeights(corpus, trainSoftmax(corpus, 50, 0.5)))
+	require.NoError(t, err)
+	doc, err := NewDocument("Peter Blackburn visited New York.", UsingModel(model))
+	require.NoError(t, err)
+	labels := map[string]string{}
+	for _, ent := range doc.Entities() {
+		labels[ent.Text] = ent.Label
+	}
+	assert.Equal(t, "PER", labels["Peter Blackburn"])
+	assert.Equal(t, "LOC", labels["New York"])
+
+	_, err = ModelFromData("external", UsingWeights(corpus, [][]float64{{1}}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package prose
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// SplitEntityData randomly divides `data` into training and test sets, with
+// about `testFraction` of the examples in the test set. The split is
+// stratified by label: each example is grouped by its rarest label, and each
+// group is split separately, so that a label with at least two examples
+// appears in both sets. The same `seed` always gives the same split, and
+// both sets keep the order of `data`.
+func SplitEntityData(data []EntityContext, testFraction float64, seed int64) (train, test []EntityContext) {
+	inTest := make([]bool, len(data))
+	rng := rand.New(rand.NewSource(seed))
+	for _, group := range entityStrata(data, rng) {
+		n := int(math.Round(clamp(testFraction, 0, 1) * float64(len(group))))
+		if len(group) > 1 && testFraction > 0 && testFraction < 1 {
+			if n < 1 {
+				n = 1
+			} else if n > len(group)-1 {
+				n = len(group) - 1
+			}
+		}
+		for _, i := range group[:n] {
+			inTest[i] = true
+		}
+	}
+
+	train, test = []EntityContext{}, []EntityContext{}
+	for i, example := range data {
+		if inTest[i] {
+			test = append(test, example)
+		} else {
+			train = append(train, example)
+		}
+	}
+	return train, test
+}
+
+// CrossValidate estimates how well a NER trained on `data` with `opts`
+// generalizes, by k-fold cross-validation: `data` is divided into `k`
+// folds, stratified by label as in SplitEntityData, and each fold is
+// evaluated (see Evaluate) with a model trained on the other folds. The
+// folds are the same for the same data.
+func CrossValidate(data []EntityContext, k int, opts TrainingOptions) ([]EvalResult, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("invalid number of folds %d", k)
+	} else if k > len(data) {
+		return nil, errors.New("unable to cross-validate: fewer examples than folds")
+	}
+
+	fold := make([]int, len(data))
+	next := 0
+	for _, group := range entityStrata(data, rand.New(rand.NewSource(0))) {
+		for _, i := range group {
+			fold[i] = next
+			next = (next + 1) % k
+		}
+	}
+
+	evalOpts := []EvalOpt{}
+	if opts.Tokenizer != nil {
+		evalOpts = append(evalOpts, UsingEvalTokenizer(opts.Tokenizer))
+	}
+	results := make([]EvalResult, k)
+	for f := range results {
+		train, test := []EntityContext{}, []EntityContext{}
+		for i, example := range data {
+			if fold[i] == f {
+				test = append(test, example)
+			} else {
+				train = append(train, example)
+			}
+		}
+
+		name := fmt.Sprintf("fold-%d", f)
+		model, err := ModelFromData(name, UsingEntitiesWithOptions(train, opts))
+		if err != nil {
+			return nil, fmt.Errorf("unable to train fold %d: %w", f, err)
+		}
+		if results[f], err = Evaluate(model, test, evalOpts...); err != nil {
+			return nil, fmt.Errorf("unable to evaluate fold %d: %w", f, err)
+		}
+	}
+	return results, nil
+}
+
+// entityStrata groups the indices of `data` by the rarest label of each
+// example (examples without entities form their own group), shuffling each
+// group with `rng`. The groups are ordered from the rarest label up.
+func entityStrata(data []EntityContext, rng *rand.Rand) [][]int {
+	counts := map[string]int{}
+	for _, example := range data {
+		for _, span := range example.Spans {
+			counts[span.Label]++
+		}
+	}
+
+	strata := map[string][]int{}
+	for i, example := range data {
+		key := ""
+		for _, span := range example.Spans {
+			if key == "" || counts[span.Label] < counts[key] ||
+				(counts[span.Label] == counts[key] && span.Label < key) {
+				key = span.Label
+			}
+		}
+		strata[key] = append(strata[key], i)
+	}
+
+	keys := make([]string, 0, len(strata))
+	for key := range strata {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(strata[keys[i]]) != len(strata[keys[j]]) {
+			return len(strata[keys[i]]) < len(strata[keys[j]])
+		}
+		return keys[i] < keys[j]
+	})
+
+	groups := make([][]int, len(keys))
+	for i, key := range keys {
+		group := strata[key]
+		rng.Shuffle(len(group), func(a, b int) { group[a], group[b] = group[b], group[a] })
+		groups[i] = group
+	}
+	return groups
+}
@@ -0,0 +1,77 @@
+package prose
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func splitData() []EntityContext {
+	data := []EntityContext{}
+	for i, name := range []string{"Alice", "Bob", "Carol", "Dave", "Erin", "Frank", "Grace", "Heidi"} {
+		data = append(data, EntityContext{Accept: true, Text: fmt.Sprintf("%s wrote %d letters .", name, i),
+			Spans: []LabeledEntity{{Start: 0, End: len(name), Label: "PERSON"}}})
+	}
+	for _, org := range []string{"Acme", "Initech"} {
+		data = append(data, EntityContext{Accept: true, Text: "Bob joined " + org + " today .",
+			Spans: []LabeledEntity{{Start: 0, End: 3, Label: "PERSON"}, {Start: 11, End: 11 + len(org), Label: "ORG"}}})
+	}
+	data = append(data,
+		EntityContext{Accept: true, Text: "It rained all day ."},
+		EntityContext{Accept: true, Text: "Nothing happened ."})
+	return data
+}
+
+func TestSplitEntityData(t *testing.T) {
+	data := splitData()
+	train, test := SplitEntityData(data, 0.25, 7)
+	assert.Len(t, train, 8)
+	assert.Len(t, test, 4)
+
+	labels := func(examples []EntityContext) map[string]int {
+		counts := map[string]int{}
+		for _, example := range examples {
+			for _, span := range example.Spans {
+				counts[span.Label]++
+			}
+		}
+		return counts
+	}
+	assert.Equal(t, 1, labels(train)["ORG"])
+	assert.Equal(t, 1, labels(test)["ORG"])
+
+	again, againTest := SplitEntityData(data, 0.25, 7)
+	assert.Equal(t, train, again)
+	assert.Equal(t, test, againTest)
+
+	train, test = SplitEntityData(data, 0, 7)
+	assert.Equal(t, data, train)
+	assert.Empty(t, test)
+	train, test = SplitEntityData(data, 1, 7)
+	assert.Empty(t, train)
+	assert.Equal(t, data, test)
+}
+
+func TestCrossValidate(t *testing.T) {
+	data := splitData()
+	results, err := CrossValidate(data, 3, TrainingOptions{Iterations: 5})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	examples := 0
+	for _, result := range results {
+		examples += result.Examples
+		assert.Equal(t, 4, result.Examples)
+	}
+	assert.Equal(t, len(data), examples)
+
+	again, err := CrossValidate(data, 3, TrainingOptions{Iterations: 5})
+	require.NoError(t, err)
+	assert.Equal(t, results, again)
+
+	_, err = CrossValidate(data, 1, TrainingOptions{})
+	assert.Error(t, err)
+	_, err = CrossValidate(data[:2], 3, TrainingOptions{})
+	assert.Error(t, err)
+}
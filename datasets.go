@@ -0,0 +1,238 @@
+package prose
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReadHFEntities reads named-entity data in the JSONL format used by
+// Hugging Face datasets (e.g., CoNLL-2003 or WikiANN), one example per line:
+//
+//	{"tokens": ["EU", "rejects", "German", "call"], "ner_tags": [3, 0, 7, 0]}
+//
+// `labels` names the integer tags (the dataset's ClassLabel names, such as
+// "O", "B-PER", "I-PER", ...); tags may also be given as strings, in which
+// case `labels` may be nil. Tags use the IOB, IOB2, or BIOES (BILOU)
+// schemes.
+//
+// The text of each example is its tokens joined by spaces, and span offsets
+// are in characters (runes), as elsewhere in LabeledEntity. Tokenizing that
+// text usually, but not always, gives back the same tokens.
+func ReadHFEntities(r io.Reader, labels []string) ([]EntityContext, error) {
+	data := []EntityContext{}
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var example struct {
+			Tokens []string      `json:"tokens"`
+			Tags   []interface{} `json:"ner_tags"`
+		}
+		err := dec.Decode(&example)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to decode example %d: %w", line, err)
+		}
+		if len(example.Tokens) != len(example.Tags) {
+			return nil, fmt.Errorf("example %d has %d tokens but %d tags",
+				line, len(example.Tokens), len(example.Tags))
+		}
+
+		tags := make([]string, len(example.Tags))
+		for i, tag := range example.Tags {
+			switch tag := tag.(type) {
+			case string:
+				tags[i] = tag
+			case float64:
+				if int(tag) < 0 || int(tag) >= len(labels) || float64(int(tag)) != tag {
+					return nil, fmt.Errorf("example %d has unknown tag %v", line, tag)
+				}
+				tags[i] = labels[int(tag)]
+			default:
+				return nil, fmt.Errorf("example %d has invalid tag %v", line, tag)
+			}
+		}
+		data = append(data, hfExample(example.Tokens, tags))
+	}
+	return data, nil
+}
+
+// ReadCoNLL2003 reads named-entity data in the CoNLL-2003 column format: one
+// token per line, followed by its part-of-speech tag, chunk tag, and entity
+// tag, with blank lines between sentences and "-DOCSTART-" lines between
+// documents:
+//
+//	EU NNP B-NP B-ORG
+//	rejects VBZ B-VP O
+//	German JJ B-NP B-MISC
+//
+// Only the first (token) and last (entity tag) columns are used, so files
+// with fewer columns are also accepted. Tags use the IOB, IOB2, or BIOES
+// (BILOU) schemes. As with ReadHFEntities, the text of each sentence is its
+// tokens joined by spaces.
+func ReadCoNLL2003(r io.Reader) ([]EntityContext, error) {
+	data := []EntityContext{}
+	tokens, tags := []string{}, []string{}
+	flush := func() {
+		if len(tokens) > 0 {
+			data = append(data, hfExample(tokens, tags))
+			tokens, tags = []string{}, []string{}
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "-DOCSTART-" {
+			flush()
+			continue
+		} else if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected a token and an entity tag", line)
+		}
+		tokens = append(tokens, fields[0])
+		tags = append(tags, fields[len(fields)-1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read CoNLL data: %w", err)
+	}
+	flush()
+	return data, nil
+}
+
+// hfExample builds the EntityContext of the tokens `tokens` tagged with
+// `tags`.
+func hfExample(tokens, tags []string) EntityContext {
+	example := EntityContext{Accept: true, Spans: []LabeledEntity{}}
+	starts := make([]int, len(tokens))
+	offset := 0
+	for i, tok := range tokens {
+		starts[i] = offset
+		offset += utf8.RuneCountInString(tok) + 1
+	}
+	example.Text = strings.Join(tokens, " ")
+
+	open := -1 // The index of the open span in example.Spans, if any.
+	for i, tag := range tags {
+		prefix, label := "O", ""
+		if j := strings.Index(tag, "-"); j > 0 {
+			prefix, label = tag[:j], tag[j+1:]
+		}
+		end := starts[i] + utf8.RuneCountInString(tokens[i])
+
+		continues := open >= 0 && example.Spans[open].Label == label &&
+			(prefix == "I" || prefix == "E" || prefix == "L")
+		switch {
+		case label == "":
+			open = -1
+		case continues:
+			example.Spans[open].End = end
+		default:
+			example.Spans = append(example.Spans,
+				LabeledEntity{Start: starts[i], End: end, Label: label})
+			open = len(example.Spans) - 1
+		}
+		if prefix == "E" || prefix == "L" || prefix == "S" || prefix == "U" {
+			open = -1
+		}
+	}
+	return example
+}
+
+// prodigySpan is a span of a Prodigy task; its offsets are in characters
+// (runes), as are those of a LabeledEntity.
+type prodigySpan struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text,omitempty"`
+	Label string `json:"label"`
+}
+
+// prodigyTask is an annotated Prodigy task, as exported by db-out.
+type prodigyTask struct {
+	Text   string        `json:"text"`
+	Spans  []prodigySpan `json:"spans"`
+	Answer string        `json:"answer,omitempty"`
+}
+
+// ReadProdigyJSONL reads named-entity data in the JSONL format exported by
+// Prodigy (e.g., by its db-out command), one task per line:
+//
+//	{"text": "Apple updates its iPhone.", "spans": [{"start": 0, "end": 5, "label": "ORG"}], "answer": "accept"}
+//
+// Tasks answered "ignore" are skipped, and those answered "reject" are
+// read with Accept false; tasks without an answer (e.g., pre-annotated
+// input) are accepted. Span offsets are in characters (runes), not bytes,
+// in both Prodigy and EntityContext.
+func ReadProdigyJSONL(r io.Reader) ([]EntityContext, error) {
+	data := []EntityContext{}
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var task prodigyTask
+		err := dec.Decode(&task)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to decode task %d: %w", line, err)
+		}
+
+		example := EntityContext{Text: task.Text, Spans: []LabeledEntity{}}
+		switch task.Answer {
+		case "ignore":
+			continue
+		case "accept", "":
+			example.Accept = true
+		case "reject":
+		default:
+			return nil, fmt.Errorf("task %d has unknown answer %q", line, task.Answer)
+		}
+
+		length := utf8.RuneCountInString(task.Text)
+		for _, span := range task.Spans {
+			if span.Start < 0 || span.Start >= span.End || span.End > length {
+				return nil, fmt.Errorf("task %d has invalid span [%d, %d) in %d characters",
+					line, span.Start, span.End, length)
+			} else if span.Label == "" {
+				return nil, fmt.Errorf("task %d has a span without a label", line)
+			}
+			example.Spans = append(example.Spans,
+				LabeledEntity{Start: span.Start, End: span.End, Label: span.Label})
+		}
+		data = append(data, example)
+	}
+	return data, nil
+}
+
+// WriteProdigyJSONL writes `data` to `w` in the format read by
+// ReadProdigyJSONL (and Prodigy's db-in command), e.g., to review a model's
+// corrections in Prodigy. Examples are answered "accept" or "reject"
+// according to Accept.
+func WriteProdigyJSONL(w io.Writer, data []EntityContext) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for i, example := range data {
+		task := prodigyTask{Text: example.Text, Spans: []prodigySpan{}, Answer: "reject"}
+		if example.Accept {
+			task.Answer = "accept"
+		}
+		runes := []rune(example.Text)
+		for _, span := range example.Spans {
+			if span.Start < 0 || span.Start >= span.End || span.End > len(runes) {
+				return fmt.Errorf("example %d has invalid span [%d, %d) in %d characters",
+					i, span.Start, span.End, len(runes))
+			}
+			task.Spans = append(task.Spans, prodigySpan{
+				Start: span.Start,
+				End:   span.End,
+				Text:  string(runes[span.Start:span.End]),
+				Label: span.Label})
+		}
+		if err := enc.Encode(task); err != nil {
+			return fmt.Errorf("unable to write example %d: %w", i, err)
+		}
+	}
+	return nil
+}
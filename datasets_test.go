@@ -0,0 +1,143 @@
+package prose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadHFEntities(t *testing.T) {
+	labels := []string{"O", "B-PER", "I-PER", "B-ORG", "I-ORG", "B-LOC", "I-LOC", "B-MISC", "I-MISC"}
+	jsonl := `{"id": "0", "tokens": ["EU", "rejects", "German", "call"], "ner_tags": [3, 0, 7, 0]}
+{"id": "1", "tokens": ["Peter", "Blackburn", "of", "New", "York"], "ner_tags": [1, 2, 0, 5, 6]}
+{"tokens": ["Jane", "Doe", "met", "John", "Smith"], "ner_tags": ["B-PER", "E-PER", "O", "B-PER", "E-PER"]}
+`
+	data, err := ReadHFEntities(strings.NewReader(jsonl), labels)
+	require.NoError(t, err)
+	require.Len(t, data, 3)
+
+	assert.Equal(t, "EU rejects German call", data[0].Text)
+	assert.Equal(t, []LabeledEntity{{0, 2, "ORG"}, {11, 17, "MISC"}}, data[0].Spans)
+
+	assert.Equal(t, []LabeledEntity{{0, 15, "PER"}, {19, 27, "LOC"}}, data[1].Spans)
+	for _, span := range data[1].Spans {
+		assert.Contains(t, []string{"Peter Blackburn", "New York"}, data[1].Text[span.Start:span.End])
+	}
+
+	assert.Equal(t, []LabeledEntity{{0, 8, "PER"}, {13, 23, "PER"}}, data[2].Spans)
+
+	_, err = ReadHFEntities(strings.NewReader(`{"tokens": ["a"], "ner_tags": [9]}`), labels)
+	assert.Error(t, err)
+	_, err = ReadHFEntities(strings.NewReader(`{"tokens": ["a", "b"], "ner_tags": [0]}`), labels)
+	assert.Error(t, err)
+
+	_, err = ModelFromData("conll", UsingEntities(data))
+	require.NoError(t, err)
+}
+
+const conll2003 = `-DOCSTART- -X- -X- O
+
+EU NNP B-NP B-ORG
+rejects VBZ B-VP O
+German JJ B-NP B-MISC
+call NN I-NP O
+to TO B-VP O
+boycott VB I-VP O
+British JJ B-NP B-MISC
+lamb NN I-NP O
+. . O O
+
+Peter NNP B-NP B-PER
+Blackburn NNP I-NP I-PER
+visited VBD B-VP O
+New NNP B-NP B-LOC
+York NNP I-NP I-LOC
+. . O O
+`
+
+func TestReadCoNLL2003(t *testing.T) {
+	data, err := ReadCoNLL2003(strings.NewReader(strings.ReplaceAll(conll2003, "\n", "\r\n")))
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+
+	assert.True(t, data[0].Accept)
+	assert.Equal(t, "EU rejects German call to boycott British lamb .", data[0].Text)
+	assert.Equal(t, []LabeledEntity{{0, 2, "ORG"}, {11, 17, "MISC"}, {34, 41, "MISC"}}, data[0].Spans)
+	for _, span := range data[1].Spans {
+		assert.Contains(t, []string{"Peter Blackburn", "New York"}, data[1].Text[span.Start:span.End])
+	}
+
+	// IOB1 tags only start with "B-" between entities of the same type.
+	data, err = ReadCoNLL2003(strings.NewReader("Jane I-PER\nDoe I-PER\nJohn B-PER\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []LabeledEntity{{0, 8, "PER"}, {9, 13, "PER"}}, data[0].Spans)
+
+	// Offsets are in characters.
+	data, err = ReadCoNLL2003(strings.NewReader("Zürich B-ORG\nAG I-ORG\nhired O\nAna B-PER\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []LabeledEntity{{0, 9, "ORG"}, {16, 19, "PER"}}, data[0].Spans)
+
+	_, err = ReadCoNLL2003(strings.NewReader("EU B-ORG\nrejects\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestCoNLL2003Model(t *testing.T) {
+	data, err := ReadCoNLL2003(strings.NewReader(conll2003))
+	require.NoError(t, err)
+	model, err := ModelFromData("conll", UsingEntities(data))
+	require.NoError(t, err)
+
+	doc, err := NewDocument("Peter Blackburn visited New York.", UsingModel(model))
+	require.NoError(t, err)
+	labels := map[string]string{}
+	for _, ent := range doc.Entities() {
+		labels[ent.Text] = ent.Label
+	}
+	assert.Equal(t, "PER", labels["Peter Blackburn"])
+	assert.Equal(t, "LOC", labels["New York"])
+}
+
+func TestProdigyJSONL(t *testing.T) {
+	jsonl := `{"text": "Zürich AG hired Ana.", "spans": [{"start": 0, "end": 9, "text": "Zürich AG", "label": "ORG"}, {"start": 16, "end": 19, "label": "PER"}], "answer": "accept", "_input_hash": 1}
+{"text": "Nothing to see.", "answer": "accept"}
+{"text": "Skip me.", "spans": [{"start": 0, "end": 4, "label": "X"}], "answer": "ignore"}
+{"text": "Read Tale-of-Two-Cities.", "spans": [{"start": 5, "end": 23, "label": "WORK-OF-ART"}], "answer": "reject"}
+{"text": "No answer."}
+`
+	data, err := ReadProdigyJSONL(strings.NewReader(jsonl))
+	require.NoError(t, err)
+	assert.Equal(t, []EntityContext{
+		{Accept: true, Text: "Zürich AG hired Ana.", Spans: []LabeledEntity{{0, 9, "ORG"}, {16, 19, "PER"}}},
+		{Accept: true, Text: "Nothing to see.", Spans: []LabeledEntity{}},
+		{Accept: false, Text: "Read Tale-of-Two-Cities.", Spans: []LabeledEntity{{5, 23, "WORK-OF-ART"}}},
+		{Accept: true, Text: "No answer.", Spans: []LabeledEntity{}},
+	}, data)
+
+	var buf strings.Builder
+	require.NoError(t, WriteProdigyJSONL(&buf, data))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, `{"text":"Zürich AG hired Ana.","spans":[{"start":0,"end":9,"text":"Zürich AG","label":"ORG"},{"start":16,"end":19,"text":"Ana","label":"PER"}],"answer":"accept"}`, lines[0])
+	assert.Equal(t, `{"text":"Nothing to see.","spans":[],"answer":"accept"}`, lines[1])
+	roundTrip, err := ReadProdigyJSONL(strings.NewReader(buf.String()))
+	require.NoError(t, err)
+	assert.Equal(t, data, roundTrip)
+
+	// Labels may contain dashes.
+	assert.Equal(t, "WORK-OF-ART", parseEntities([]string{"B-WORK-OF-ART", "I-WORK-OF-ART"}))
+
+	for input, message := range map[string]string{
+		`{"text": "Short.", "spans": [{"start": 0, "end": 7, "label": "X"}]}`: "task 1 has invalid span [0, 7)",
+		`{"text": "Short.", "spans": [{"start": 0, "end": 5}]}`:               "task 1 has a span without a label",
+		`{"text": "Short.", "answer": "maybe"}`:                               `task 1 has unknown answer "maybe"`,
+		`{"text": 1}`:                                                         "unable to decode task 1",
+	} {
+		_, err = ReadProdigyJSONL(strings.NewReader(input))
+		require.Error(t, err, input)
+		assert.Contains(t, err.Error(), message, input)
+	}
+	assert.Error(t, WriteProdigyJSONL(&buf, []EntityContext{{Text: "Zürich", Spans: []LabeledEntity{{0, 7, "LOC"}}}}))
+}
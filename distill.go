@@ -0,0 +1,61 @@
+package prose
+
+import "fmt"
+
+// Distill runs the Model's NER over `texts` and records the decisions it
+// makes deterministically: every (word, tag, previous label) context seen at
+// least `minCount` times that always received the same label.
+//
+// The resulting table is consulted before the classifier, which remains the
+// fallback for every other context. Since its key ignores the classifier's
+// other features (such as the surrounding words and the word's shape), the
+// table is an approximation: a context that always received the same label
+// in `texts` may not in other text. The table is saved with the Model. It
+// returns the number of table entries.
+func (m *Model) Distill(texts []string, minCount int) (int, error) {
+	if m.tagger == nil || m.extracter == nil {
+		return 0, fmt.Errorf("unable to distill: model has no NER")
+	}
+
+	// Distillation must observe the classifier itself, not a previous table.
+	m.extracter.lookup = nil
+
+	tokenizer := NewIterTokenizer()
+	counts := make(map[string]map[string]int)
+	for _, text := range texts {
+		tokens := m.extracter.classify(m.tagger.Tag(tokenizer.Tokenize(text)), nil, false, nil)
+		history := make([]string, 0, len(tokens))
+		for i, tok := range tokens {
+			key := distillKey(i, tokens, history)
+			if counts[key] == nil {
+				counts[key] = make(map[string]int)
+			}
+			counts[key][tok.Label]++
+			history = append(history, simplePOS(tok.Label))
+		}
+	}
+
+	lookup := make(map[string]string)
+	for key, labels := range counts {
+		if len(labels) != 1 {
+			continue
+		}
+		for label, n := range labels {
+			if n >= minCount {
+				lookup[key] = label
+			}
+		}
+	}
+	m.extracter.lookup, m.extracter.digest = lookup, ""
+
+	return len(lookup), nil
+}
+
+// distillKey identifies the context of the i-th token for distillation.
+func distillKey(i int, tokens []*Token, history []string) string {
+	prev := NoneFeat
+	if i > 0 {
+		prev = history[i-1]
+	}
+	return tokens[i].Text + "\x00" + tokens[i].Tag + "\x00" + prev
+}
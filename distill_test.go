@@ -0,0 +1,60 @@
+package prose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistill(t *testing.T) {
+	content := readDataFile(filepath.Join(testdata, "sherlock.txt"), t)
+	texts := strings.Split(string(content), "\n\n")
+
+	baseline, err := ModelFromData("baseline")
+	require.NoError(t, err)
+	distilled, err := ModelFromData("distilled")
+	require.NoError(t, err)
+
+	n, err := distilled.Distill(texts[:200], 3)
+	require.NoError(t, err)
+	assert.Greater(t, n, 0)
+
+	total, agree := 0.0, 0.0
+	for _, text := range texts[200:260] {
+		expected, err := makeNER(text, baseline)
+		require.NoError(t, err)
+		observed, err := makeNER(text, distilled)
+		require.NoError(t, err)
+		for i, tok := range observed.Tokens() {
+			if tok.Label == expected.Tokens()[i].Label {
+				agree++
+			}
+			total++
+		}
+	}
+	assert.GreaterOrEqual(t, agree/total, 0.99)
+}
+
+func TestDistillRoundTrip(t *testing.T) {
+	content := readDataFile(filepath.Join(testdata, "sherlock.txt"), t)
+	texts := strings.Split(string(content), "\n\n")
+
+	model, err := ModelFromData("distilled")
+	require.NoError(t, err)
+	n, err := model.Distill(texts[:50], 3)
+	require.NoError(t, err)
+
+	temp := filepath.Join(testdata, "temp")
+	_ = os.RemoveAll(temp)
+	require.NoError(t, model.Write(temp))
+	defer os.RemoveAll(temp)
+
+	loaded, err := ModelFromDisk(temp)
+	require.NoError(t, err)
+	assert.Len(t, loaded.extracter.lookup, n)
+	assert.Equal(t, model.extracter.lookup, loaded.extracter.lookup)
+}
@@ -1,34 +1,84 @@
 package prose
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
 
 // A DocOpt represents a setting that changes the document creation process.
 //
 // For example, it might disable named-entity extraction:
 //
 //	doc := prose.NewDocument("...", prose.WithExtraction(false))
-type DocOpt func(doc *Document, opts *DocOpts)
+type DocOpt func(doc *Document, opts *Config)
 
-// DocOpts controls the Document creation process:
-type DocOpts struct {
-	Extract   bool      // If true, include named-entity extraction
-	Segment   bool      // If true, include segmentation
-	Tag       bool      // If true, include POS tagging
-	Tokenizer Tokenizer // If true, include tokenization
+// A Config controls the Document creation process; it's usually built up
+// with DocOpts, but it may also be constructed directly and applied with
+// UsingConfig.
+type Config struct {
+	Model             *Model            // The model; the default model if nil
+	Extract           bool              // If true, include named-entity extraction
+	Segment           bool              // If true, include segmentation
+	Tag               bool              // If true, include POS tagging
+	Tokenizer         Tokenizer         // If true, include tokenization
+	SentenceTokenizer SentenceTokenizer // The segmenter; punkt if nil
+	Guard             InputPolicy       // How to handle invalid or unsupported input
+	BlockContext      bool              // If true, give the NER structural context
+	Confusables       bool              // If true, normalize homoglyphs
+	Unicode           UnicodeForm       // The normalization form of the input
+	Tables            TablePolicy       // How to handle table-like regions
+	ListItems         bool              // If true, detect list items
+	Boundaries        *regexp.Regexp    // Delimiters between independent sections
+	Trim              EntityTrim        // Stray tokens to remove from entities
+	StrictTokenizer   bool              // If true, fail on a tokenizer/model mismatch
+	Tokens            []Token           // Pre-tokenized (and possibly pre-labeled) input
+	Sentiment         bool              // If true, score sentiment
+	Caps              CapsPolicy        // How to handle all-caps text
+	MinConfidence     float64           // The minimum Confidence of entities
+	Consistency       ConsistencyPolicy // How to reconcile labels of repeated entities
+	Focus             []Span            // If set, the only ranges to tag and classify
+	Cache             DocumentCache     // If set, where to look up and store Documents
+	Trace             bool              // If true, record the NER classifier's decisions
+	Quality           bool              // If true, check the text for QualityIssues
+
+	AbbreviationTagging bool // If true, decide if abbreviations end sentences by POS
+
+	Boilerplate    *BoilerplateDetector // If set, finds sentences to exclude entities from
+	CaseDictionary *CaseDictionary      // If set, the usual forms of words to tag and classify
+	Gazetteer      *Gazetteer           // If set, known entities to label as-is
+	KnownEntities  map[string]bool      // Entity texts that Signals doesn't count as novel
+
+	err       error      // An invalid option, if any.
+	sentences []Sentence // The sentences of pre-tokenized input, if any.
 }
 
+// DocOpts is the former name of Config.
+//
+// Deprecated: use Config instead.
+type DocOpts = Config
+
 // UsingTokenizer specifies the Tokenizer to use.
 func UsingTokenizer(include Tokenizer) DocOpt {
-	return func(doc *Document, opts *DocOpts) {
+	return func(doc *Document, opts *Config) {
 		// Tagging and entity extraction both require tokenization.
 		opts.Tokenizer = include
 	}
 }
 
+// UsingSentenceTokenizer specifies the SentenceTokenizer to use; nil disables
+// segmentation.
+func UsingSentenceTokenizer(include SentenceTokenizer) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.SentenceTokenizer = include
+		opts.Segment = include != nil
+	}
+}
+
 // WithTokenization can enable (the default) or disable tokenization.
 // Deprecated: use UsingTokenizer instead.
 func WithTokenization(include bool) DocOpt {
-	return func(doc *Document, opts *DocOpts) {
+	return func(doc *Document, opts *Config) {
 		if !include {
 			opts.Tokenizer = nil
 		}
@@ -37,29 +87,91 @@ func WithTokenization(include bool) DocOpt {
 
 // WithTagging can enable (the default) or disable POS tagging.
 func WithTagging(include bool) DocOpt {
-	return func(doc *Document, opts *DocOpts) {
+	return func(doc *Document, opts *Config) {
 		opts.Tag = include
 	}
 }
 
 // WithSegmentation can enable (the default) or disable sentence segmentation.
 func WithSegmentation(include bool) DocOpt {
-	return func(doc *Document, opts *DocOpts) {
+	return func(doc *Document, opts *Config) {
 		opts.Segment = include
 	}
 }
 
 // WithExtraction can enable (the default) or disable named-entity extraction.
 func WithExtraction(include bool) DocOpt {
-	return func(doc *Document, opts *DocOpts) {
+	return func(doc *Document, opts *Config) {
 		opts.Extract = include
 	}
 }
 
+// WithMinConfidence drops entities whose Confidence is below `threshold`
+// (between 0 and 1); by default, all entities are kept.
+func WithMinConfidence(threshold float64) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.MinConfidence = threshold
+	}
+}
+
+// WithBlockContext can enable or disable (the default) structural context
+// features (see DetectBlocks) during named-entity extraction.
+//
+// This only affects models trained with TrainingOptions.BlockContext.
+func WithBlockContext(include bool) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.BlockContext = include
+	}
+}
+
+// WithConfusableNormalization can enable or disable (the default) mapping
+// homoglyphs to ASCII before processing (see NormalizeConfusables).
+//
+// Document.Text keeps the original text; use Document.OffsetMap to relate
+// the two.
+func WithConfusableNormalization(include bool) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Confusables = include
+	}
+}
+
+// WithUnicodeNormalization normalizes the input to the given form (see
+// NormalizeUnicode) before processing; by default, it's left as-is.
+//
+// Document.Text keeps the original text; use Document.OffsetMap to relate
+// the two.
+func WithUnicodeNormalization(form UnicodeForm) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Unicode = form
+	}
+}
+
+// UsingTokens makes `tokens` the Document's tokens instead of tokenizing its
+// text. Their Tag and Label fields, where set, are treated as hard
+// constraints: they're kept as-is and inform the tags and labels assigned to
+// the other tokens.
+//
+// Labels use the IOB format (e.g., "B-PERSON", "I-PERSON", or "O").
+func UsingTokens(tokens []Token) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Tokens = tokens
+	}
+}
+
+// WithTokenizerCheck determines what happens when the Document's tokenizer
+// differs from the one its model was trained with: if `strict` is true,
+// NewDocument fails; otherwise (the default), the mismatch is reported by
+// Document.Warnings.
+func WithTokenizerCheck(strict bool) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.StrictTokenizer = strict
+	}
+}
+
 // UsingModel can enable (the default) or disable named-entity extraction.
 func UsingModel(model *Model) DocOpt {
-	return func(doc *Document, opts *DocOpts) {
-		doc.Model = model
+	return func(doc *Document, opts *Config) {
+		opts.Model = model
 	}
 }
 
@@ -69,9 +181,21 @@ type Document struct {
 	Text  string
 
 	// TODO: Store offsets (begin, end) instead of `text` field.
-	entities  []Entity
-	sentences []Sentence
-	tokens    []*Token
+	entities   []Entity
+	entParts   [][]*Token // The tokens of each entity.
+	sentences  []Sentence
+	sentStarts []int
+	sentTokens []int // The index of each sentence's first token, then len(tokens).
+	tokens     []*Token
+	tables     []Table
+	listItems  []ListItem
+	config     Config
+	offsets    *OffsetMap
+	warnings   []string
+	sentiment  Sentiment
+	sentiments []Sentiment
+	trace      []NERStep
+	quality    []QualityWarning
 }
 
 // Tokens returns `doc`'s tokens.
@@ -88,12 +212,137 @@ func (doc *Document) Sentences() []Sentence {
 	return doc.sentences
 }
 
+// sentenceStarts maps the offsets of `sents` through `offsets` to the
+// original `text`, which each sentence then takes its text from, returning
+// the offset at which each starts; sentences without offsets start where the
+// previous one did.
+func sentenceStarts(text string, sents []Sentence, offsets *OffsetMap) []int {
+	starts := make([]int, len(sents))
+	cursor := 0
+	for i := range sents {
+		sent := &sents[i]
+		if sent.Start >= 0 {
+			sent.Start = offsets.Original(sent.Start)
+			sent.End = offsets.Original(sent.End)
+			sent.Text = text[sent.Start:sent.End]
+			cursor = sent.Start
+		}
+		starts[i] = cursor
+	}
+	return starts
+}
+
+// SentencesWithTokens returns `doc`'s sentences, each with its tokens.
+//
+// NewDocument tokenizes each sentence separately, so no token crosses a
+// sentence boundary, and tokens are assigned to sentences by their offsets,
+// so nothing is segmented or tokenized again. Without segmentation, there's a single
+// sentence holding all of the tokens; pre-tokenized input without offsets
+// (see UsingTokens) has all of its tokens in the first sentence.
+func (doc *Document) SentencesWithTokens() []TokenizedSentence {
+	sents := make([]TokenizedSentence, doc.NumSentences())
+	for i := range sents {
+		sents[i] = TokenizedSentence{Sentence: doc.Sentence(i), Tokens: doc.TokensInSentence(i)}
+	}
+	return sents
+}
+
+// NumSentences returns the number of sentences Sentence and
+// TokensInSentence accept, as in SentencesWithTokens.
+func (doc *Document) NumSentences() int {
+	if len(doc.sentStarts) == 0 {
+		return 1
+	}
+	return len(doc.sentences)
+}
+
+// Sentence returns `doc`'s i-th sentence, as in SentencesWithTokens; it
+// panics if `i` is out of range. It's safe for concurrent use.
+func (doc *Document) Sentence(i int) Sentence {
+	if len(doc.sentStarts) == 0 && i == 0 {
+		return Sentence{Text: doc.Text, Start: 0, End: len(doc.Text)}
+	}
+	return doc.sentences[i]
+}
+
+// TokensInSentence returns the tokens of `doc`'s i-th sentence, as in
+// SentencesWithTokens, without visiting the other sentences; it panics if
+// `i` is out of range. It's safe for concurrent use.
+func (doc *Document) TokensInSentence(i int) []Token {
+	if i < 0 || i >= doc.NumSentences() {
+		panic(fmt.Sprintf("prose: sentence %d out of range [0, %d)", i, doc.NumSentences()))
+	}
+	tokens := []Token{}
+	for _, tok := range doc.sentenceTokens(i) {
+		tokens = append(tokens, *tok)
+	}
+	return tokens
+}
+
+// sentenceTokens returns the tokens of the i-th sentence (see
+// TokensInSentence) without copying them.
+func (doc *Document) sentenceTokens(i int) []*Token {
+	return doc.tokens[doc.sentTokens[i]:doc.sentTokens[i+1]]
+}
+
+// indexSentences records the index of the first token of each of `doc`'s
+// sentences (see TokensInSentence).
+func (doc *Document) indexSentences() {
+	doc.sentTokens = make([]int, doc.NumSentences()+1)
+	sent := 0
+	for i, tok := range doc.tokens {
+		next := doc.sentenceAt(sent, tok.Start)
+		for sent < next {
+			sent++
+			doc.sentTokens[sent] = i
+		}
+	}
+	for sent+1 < len(doc.sentTokens) {
+		sent++
+		doc.sentTokens[sent] = len(doc.tokens)
+	}
+}
+
+// sentenceAt returns the index of the sentence containing the offset
+// `start`, searching forward from the sentence `sent`.
+func (doc *Document) sentenceAt(sent, start int) int {
+	for sent+1 < len(doc.sentStarts) && start >= doc.sentStarts[sent+1] {
+		sent++
+	}
+	return sent
+}
+
 // Entities returns `doc`'s entities.
 func (doc *Document) Entities() []Entity {
 	return doc.entities
 }
 
-var defaultOpts = DocOpts{
+// EntityTokens returns the tokens of the i-th entity of Entities (e.g., to
+// inspect their POS tags). It panics if `i` is out of range.
+func (doc *Document) EntityTokens(i int) []Token {
+	if i < 0 || i >= len(doc.entities) {
+		panic(fmt.Sprintf("prose: entity %d out of range [0, %d)", i, len(doc.entities)))
+	}
+	tokens := []Token{}
+	for _, tok := range doc.entParts[i] {
+		tokens = append(tokens, *tok)
+	}
+	return tokens
+}
+
+// Warnings returns the problems encountered while processing `doc` that
+// didn't prevent it from being created.
+func (doc *Document) Warnings() []string {
+	return doc.warnings
+}
+
+// OffsetMap relates the text that was processed to `doc`'s Text; it's nil
+// unless the input was normalized.
+func (doc *Document) OffsetMap() *OffsetMap {
+	return doc.offsets
+}
+
+var defaultOpts = Config{
 	Tokenizer: NewIterTokenizer(),
 	Segment:   true,
 	Tag:       true,
@@ -114,30 +363,230 @@ func NewDocument(text string, opts ...DocOpt) (*Document, error) {
 		applyOpt(&doc, &base)
 	}
 
-	if doc.Model == nil {
+	if pipeError = base.Validate(); pipeError != nil {
+		return nil, fmt.Errorf("invalid option: %w", pipeError)
+	}
+	doc.config = base
+
+	if doc.Model = base.Model; doc.Model == nil {
 		doc.Model, pipeError = defaultModel(base.Tag, base.Extract)
 		if pipeError != nil {
 			return nil, fmt.Errorf("unable to load default model: %w", pipeError)
 		}
 	}
 
-	if base.Segment {
-		segmenter, err := newPunktSentenceTokenizer()
+	if base.Extract && base.Tokenizer != nil && base.Tokens == nil {
+		trained := doc.Model.extracter.tokenizer
+		if trained != "" && trained != tokenizerFingerprint(base.Tokenizer) {
+			msg := fmt.Sprintf("tokenizer %s differs from the model's (%s)",
+				tokenizerFingerprint(base.Tokenizer), trained)
+			if base.StrictTokenizer {
+				return nil, fmt.Errorf("unable to use model: %s", msg)
+			}
+			doc.warnings = append(doc.warnings, msg)
+		}
+	}
+
+	key := ""
+	if base.Cache != nil {
+		if key = cacheKey(text, doc.Model, base); key != "" {
+			if cached, found := base.Cache.Get(key); found {
+				hit := *cached
+				hit.Model, hit.config = doc.Model, base
+				return &hit, nil
+			}
+		}
+	}
+
+	text, pipeError = guardText(text, base.Guard)
+	if pipeError != nil {
+		return nil, fmt.Errorf("unable to process input: %w", pipeError)
+	}
+	doc.Text = text
+	if base.Unicode != UnicodeNone {
+		text, doc.offsets = NormalizeUnicode(text, base.Unicode)
+	}
+	if base.Confusables {
+		var offsets *OffsetMap
+		text, offsets = NormalizeConfusables(text)
+		doc.offsets = chainOffsets(doc.offsets, offsets)
+	}
+
+	if base.Tables != TablesIgnore {
+		doc.tables = detectTables(text)
+	}
+	// Tables may be excluded from segmentation (and tokenization), so we
+	// track the text each stage sees.
+	segText, tokText := text, text
+	if base.Tables == TablesSkip || base.Tables == TablesRows {
+		segText = blankTables(text, doc.tables)
+		if base.Tables == TablesSkip {
+			tokText = segText
+		}
+	}
+
+	// Sections are processed independently of one another.
+	delimiters := findDelimiters(text, base.Boundaries)
+	sections := sectionsBetween(text, delimiters)
+	segText, tokText = blankSpans(segText, delimiters), blankSpans(tokText, delimiters)
+
+	if base.ListItems {
+		doc.listItems = detectListItems(segText)
+	}
+
+	// Sentence starts (in the text tokenized) at which tokenization is cut,
+	// so that no token crosses a sentence boundary.
+	cuts := []int{}
+	if base.Segment && base.sentences != nil {
+		// Pre-segmented sentences are already located in the original text.
+		doc.sentences = append([]Sentence{}, base.sentences...)
+		doc.sentStarts = sentenceStarts(doc.Text, doc.sentences, nil)
+	} else if base.Segment {
+		segmenter, err := documentSegmenter(base, doc.Model.tagger)
 		if err != nil {
-			return nil, fmt.Errorf("unable to create punkt segmenter: %w", err)
+			return nil, err
+		}
+		breaks := itemBreaks(doc.listItems)
+		for _, d := range delimiters {
+			breaks = append(breaks, d[0], d[1])
 		}
-		doc.sentences = segmenter.segment(text)
+		doc.sentences = segmenter.segmentBetween(segText, breaks)
+		if base.Tables == TablesRows {
+			doc.sentences = tableRowSentences(doc.sentences, doc.tables)
+		}
+		for _, sent := range doc.sentences {
+			if sent.Start > 0 {
+				cuts = append(cuts, sent.Start)
+			}
+		}
+		sort.Ints(cuts)
+		doc.sentStarts = sentenceStarts(doc.Text, doc.sentences, doc.offsets)
 	}
-	if base.Tokenizer != nil {
-		doc.tokens = append(doc.tokens, base.Tokenizer.Tokenize(text)...)
+
+	sectionTokens := make([][]*Token, len(sections))
+	if base.Tokens != nil {
+		sections, sectionTokens = [][2]int{{0, len(tokText)}}, [][]*Token{{}}
+		for i := range base.Tokens {
+			tok := base.Tokens[i]
+			sectionTokens[0] = append(sectionTokens[0], &tok)
+		}
+		doc.tokens = sectionTokens[0]
+	} else if base.Tokenizer != nil {
+		for i, section := range sections {
+			for _, span := range cutSection(section, cuts) {
+				tokens := base.Tokenizer.Tokenize(tokText[span[0]:span[1]])
+				sectionTokens[i] = append(sectionTokens[i], shiftTokens(tokens, span[0])...)
+			}
+			doc.tokens = append(doc.tokens, sectionTokens[i]...)
+		}
+		for _, tok := range doc.tokens {
+			tok.Start = doc.offsets.Original(tok.Start)
+			tok.End = doc.offsets.Original(tok.End)
+		}
+	}
+	contexts := make([][]string, len(sections))
+	if base.Extract && base.BlockContext {
+		for i, section := range sections {
+			contexts[i] = blockContext(tokText[section[0]:section[1]], sectionTokens[i])
+		}
+	}
+	if base.Focus != nil {
+		sectionTokens, contexts = focusTokens(sectionTokens, contexts, base.Focus)
+	}
+	matchEnds := make([][]int, len(sectionTokens))
+	if base.Gazetteer != nil && base.Extract {
+		for i, tokens := range sectionTokens {
+			matchEnds[i] = labelMatches(base.Gazetteer, tokens)
+		}
+	}
+	restoreCase, restoreCaps := func() {}, func() {}
+	if base.CaseDictionary != nil && (base.Tag || base.Extract) {
+		restoreCase = normalizeTokens(base.CaseDictionary, sectionTokens)
+	}
+	if base.Caps == CapsTruecase && (base.Tag || base.Extract) {
+		restoreCaps = truecaseTokens(doc.Model.tagger, sectionTokens)
 	}
 	if base.Tag || base.Extract {
-		doc.tokens = doc.Model.tagger.Tag(doc.tokens)
+		for _, tokens := range sectionTokens {
+			doc.Model.tagger.tag(tokens, true)
+		}
+	}
+	var given map[*Token]bool
+	if base.Extract {
+		var trace *nerTrace
+		if base.Trace {
+			trace = &nerTrace{}
+		}
+		if base.Consistency != ConsistencyOff {
+			given = givenLabels(sectionTokens)
+		}
+		for i, tokens := range sectionTokens {
+			doc.Model.extracter.classify(tokens, contexts[i], true, trace)
+			closeMatches(tokens, matchEnds[i])
+		}
+		if trace != nil {
+			doc.trace = trace.resolve(doc.tokens)
+		}
 	}
+	restoreCaps()
+	restoreCase()
 	if base.Extract {
-		doc.tokens = doc.Model.extracter.classify(doc.tokens)
-		doc.entities = doc.Model.extracter.chunk(doc.tokens)
+		// Pre-tokenized input may not have offsets into the text; those
+		// given to NewDocumentFromTokens have been validated.
+		located := base.sentences != nil && len(base.Tokens) > 0 && hasOffsets(&base.Tokens[0])
+		source := doc.Text
+		if base.Tokens != nil && !located {
+			source = ""
+		}
+		doc.entities, doc.entParts = []Entity{}, [][]*Token{}
+		for _, parts := range chunkSections(doc.Model.extracter, sectionTokens) {
+			if parts = trimEntity(parts, base.Trim); len(parts) > 0 {
+				entity := coalesce(parts, source)
+				if entity.Confidence >= base.MinConfidence {
+					doc.entities = append(doc.entities, entity)
+					doc.entParts = append(doc.entParts, parts)
+				}
+			}
+		}
+		reconcileLabels(doc.entities, doc.entParts, given, base.Consistency)
+		if base.Boilerplate != nil {
+			dropBoilerplate(&doc, base.Boilerplate)
+		}
+	}
+
+	doc.indexSentences()
+	if base.Sentiment {
+		doc.sentiment = ScoreSentiment(doc.tokens)
+		if base.Tokenizer != nil {
+			doc.sentiments = make([]Sentiment, len(doc.sentences))
+			for i := range doc.sentences {
+				doc.sentiments[i] = ScoreSentiment(doc.sentenceTokens(i))
+			}
+		}
 	}
 
+	for i := range doc.tables {
+		table := &doc.tables[i]
+		table.Start = doc.offsets.Original(table.Start)
+		table.End = doc.offsets.Original(table.End)
+		table.Text = doc.Text[table.Start:table.End]
+	}
+	for i := range doc.listItems {
+		item := &doc.listItems[i]
+		item.Start = doc.offsets.Original(item.Start)
+		item.End = doc.offsets.Original(item.End)
+		item.Text = doc.Text[item.Start:item.End]
+	}
+
+	if base.Quality {
+		doc.quality = checkQuality(&doc)
+		for _, warning := range doc.quality {
+			doc.warnings = append(doc.warnings, warning.Message)
+		}
+	}
+
+	if key != "" && pipeError == nil {
+		base.Cache.Put(key, &doc)
+	}
 	return &doc, pipeError
 }
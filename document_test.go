@@ -1,9 +1,14 @@
 package prose
 
 import (
+	"errors"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func BenchmarkDoc(b *testing.B) {
@@ -32,3 +37,292 @@ func BenchmarkCustomTokenizer(b *testing.B) {
 		}
 	}
 }
+
+func TestPresets(t *testing.T) {
+	text := "Jack Smith moved to Seattle. He works for Ｍicrosoft."
+
+	doc, err := NewDocument(text, Preset(PresetMinimal))
+	require.NoError(t, err)
+	assert.NotEmpty(t, doc.Tokens())
+	assert.Empty(t, doc.Sentences())
+	assert.Empty(t, doc.Entities())
+	assert.Equal(t, "", doc.Tokens()[0].Tag)
+
+	doc, err = NewDocument(text, Preset(PresetFast))
+	require.NoError(t, err)
+	assert.Len(t, doc.Sentences(), 2)
+	assert.NotEmpty(t, doc.Entities())
+
+	doc, err = NewDocument(text, Preset(PresetAccurate))
+	require.NoError(t, err)
+	assert.Equal(t, "Microsoft", doc.Tokens()[len(doc.Tokens())-2].Text)
+	assert.NotNil(t, doc.OffsetMap())
+
+	// Later options override the preset.
+	doc, err = NewDocument(text, Preset(PresetMinimal), WithSegmentation(true))
+	require.NoError(t, err)
+	assert.Len(t, doc.Sentences(), 2)
+
+	_, err = NewDocument(text, Preset("turbo"))
+	assert.Error(t, err)
+}
+
+func TestEntityTokens(t *testing.T) {
+	doc, err := NewDocument("Jack Smith moved to Seattle in 2010.")
+	require.NoError(t, err)
+
+	ents := doc.Entities()
+	require.NotEmpty(t, ents)
+	for i, ent := range ents {
+		texts := []string{}
+		for _, tok := range doc.EntityTokens(i) {
+			texts = append(texts, tok.Text)
+			assert.Equal(t, "NNP", tok.Tag)
+			assert.NotEqual(t, "O", tok.Label)
+		}
+		assert.Equal(t, ent.Text, strings.Join(texts, " "))
+	}
+	assert.Panics(t, func() { doc.EntityTokens(len(ents)) })
+
+	// Entities remain comparable, e.g., for use as map keys.
+	seen := map[Entity]bool{ents[0]: true}
+	assert.True(t, seen[doc.Entities()[0]])
+}
+
+func TestUsingTokens(t *testing.T) {
+	tokens := []Token{
+		{Text: "Jack"}, {Text: "moved"}, {Text: "to"}, {Text: "Seattle", Label: "O"},
+		{Text: "with"}, {Text: "Acme", Tag: "NNP", Label: "B-ORG"},
+		{Text: "Labs", Tag: "NNP", Label: "I-ORG"}, {Text: "."}}
+
+	doc, err := NewDocument("", UsingTokens(tokens), WithSegmentation(false))
+	require.NoError(t, err)
+
+	texts := []string{}
+	for _, tok := range doc.Tokens() {
+		texts = append(texts, tok.Text)
+	}
+	assert.Len(t, texts, len(tokens))
+	assert.Equal(t, "O", doc.Tokens()[3].Label)
+	assert.Equal(t, "NNP", doc.Tokens()[5].Tag)
+
+	labels := map[string]string{}
+	for _, ent := range doc.Entities() {
+		labels[ent.Text] = ent.Label
+	}
+	assert.Equal(t, "ORG", labels["Acme Labs"])
+	assert.NotContains(t, labels, "Seattle")
+
+	// The input isn't modified.
+	assert.Equal(t, "", tokens[0].Tag)
+
+	_, err = NewDocument("", UsingTokens([]Token{{Text: "Jack"}, {}}))
+	assert.Error(t, err)
+}
+
+func TestNewDocumentFromTokens(t *testing.T) {
+	text := "Jack moved to Seattle. He joined Acme Labs."
+	tokenizer := NewIterTokenizer()
+	sentences := [][]*Token{}
+	for _, span := range [][2]int{{0, 22}, {23, 43}} {
+		tokens := tokenizer.Tokenize(text[span[0]:span[1]])
+		shiftTokens(tokens, span[0])
+		sentences = append(sentences, tokens)
+	}
+	sentences[1][2].Label = "B-ORG"
+	sentences[1][3].Label = "I-ORG"
+
+	doc, err := NewDocumentFromTokens(text, sentences)
+	require.NoError(t, err)
+	require.Len(t, doc.Tokens(), 10)
+	assert.Equal(t, []Sentence{
+		{Text: "Jack moved to Seattle.", Start: 0, End: 22},
+		{Text: "He joined Acme Labs.", Start: 23, End: 43}}, doc.Sentences())
+	withTokens := doc.SentencesWithTokens()
+	require.Len(t, withTokens, 2)
+	assert.Len(t, withTokens[1].Tokens, 5)
+	assert.NotEmpty(t, doc.Tokens()[1].Tag)
+
+	spans := map[string][2]int{}
+	for _, ent := range doc.Entities() {
+		spans[ent.Text] = [2]int{ent.Start, ent.End}
+	}
+	assert.Equal(t, [2]int{33, 42}, spans["Acme Labs"])
+	assert.Empty(t, sentences[0][1].Tag, "the input isn't modified")
+
+	doc, err = NewDocumentFromTokens(text, sentences, WithTagging(false), WithExtraction(false))
+	require.NoError(t, err)
+	assert.Empty(t, doc.Tokens()[1].Tag)
+	assert.Empty(t, doc.Entities())
+
+	// Tokens without offsets are joined into sentences.
+	doc, err = NewDocumentFromTokens("", [][]*Token{{{Text: "Hello"}, {Text: "there"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []Sentence{{Text: "Hello there", Start: -1, End: -1}}, doc.Sentences())
+
+	for name, input := range map[string][][]*Token{
+		"empty sentence": {sentences[0], {}},
+		"nil token":      {{sentences[0][0], nil}},
+		"empty token":    {{{Text: ""}}},
+		"mixed offsets":  {{sentences[0][0], {Text: "moved"}}},
+		"out of range":   {{{Text: "Jack", Start: 40, End: 44}}},
+	} {
+		_, err = NewDocumentFromTokens(text, input)
+		assert.Error(t, err, name)
+	}
+	_, err = NewDocumentFromTokens(text, [][]*Token{{{Text: "Jack", Start: 40, End: 44}}})
+	assert.True(t, errors.Is(err, ErrInvalidSpan))
+}
+
+func TestEntityOffsets(t *testing.T) {
+	text := "Google hired Jane Smith yesterday in New York"
+	doc, err := NewDocument(text)
+	require.NoError(t, err)
+
+	spans := map[string][2]int{}
+	for _, ent := range doc.Entities() {
+		assert.Equal(t, ent.Text, text[ent.Start:ent.End])
+		spans[ent.Text] = [2]int{ent.Start, ent.End}
+	}
+	assert.Equal(t, [2]int{0, 6}, spans["Google"], "entity at the start")
+	assert.Equal(t, [2]int{37, 45}, spans["New York"], "entity at the end")
+
+	// Entities keep their original spacing.
+	text = "Jean-Luc Picard met officials in New\nYork."
+	doc, err = NewDocument(text)
+	require.NoError(t, err)
+	ents := doc.Entities()
+	require.NotEmpty(t, ents)
+	assert.Equal(t, "Jean-Luc Picard", ents[0].Text)
+	assert.Equal(t, "New\nYork", ents[len(ents)-1].Text)
+
+	// Tokens split out of a larger span.
+	tokens := NewIterTokenizer().Tokenize("at (Google) now")
+	for _, tok := range tokens {
+		tok.Label = "O"
+	}
+	tokens[2].Label = "B-ORGANIZATION"
+	ent := coalesce(tokens[2:3], "at (Google) now")
+	assert.Equal(t, "Google", ent.Text)
+	assert.Equal(t, [2]int{4, 10}, [2]int{ent.Start, ent.End})
+}
+
+func TestEntityTextFidelity(t *testing.T) {
+	text := "Yesterday, Jane  Smith met José García in “New York”."
+	doc, err := NewDocument(text)
+	require.NoError(t, err)
+	texts := []string{}
+	for _, ent := range doc.Entities() {
+		assert.Equal(t, text[ent.Start:ent.End], ent.Text)
+		texts = append(texts, ent.Text)
+	}
+	assert.Contains(t, texts, "Jane  Smith")
+}
+
+func TestEntityConfidence(t *testing.T) {
+	text := "Yesterday, Jane Smith met the Acme team in New York."
+	doc, err := NewDocument(text)
+	require.NoError(t, err)
+
+	all := map[string]float64{}
+	for i, ent := range doc.Entities() {
+		require.True(t, ent.Confidence > 0 && ent.Confidence <= 1, ent.Text)
+		for _, tok := range doc.EntityTokens(i) {
+			assert.True(t, tok.Confidence >= ent.Confidence)
+		}
+		all[ent.Text] = ent.Confidence
+	}
+	require.Contains(t, all, "Acme")
+	require.Contains(t, all, "New York")
+	require.Less(t, all["Acme"], 0.6)
+	require.Greater(t, all["New York"], 0.6)
+
+	doc, err = NewDocument(text, WithMinConfidence(0.6))
+	require.NoError(t, err)
+	for _, ent := range doc.Entities() {
+		assert.GreaterOrEqual(t, ent.Confidence, 0.6)
+		assert.NotEqual(t, "Acme", ent.Text)
+	}
+
+	_, err = NewDocument(text, WithMinConfidence(1.5))
+	assert.Error(t, err)
+}
+
+func TestSentencesWithTokens(t *testing.T) {
+	text := "Jane Smith lives in Paris. She works for Acme Corp. Really?"
+	doc, err := NewDocument(text)
+	require.NoError(t, err)
+
+	sents := doc.SentencesWithTokens()
+	require.Len(t, sents, len(doc.Sentences()))
+	all := []Token{}
+	for i, sent := range sents {
+		assert.Equal(t, doc.Sentences()[i], sent.Sentence)
+		first, last := sent.Tokens[0], sent.Tokens[len(sent.Tokens)-1]
+		assert.Equal(t, sent.Text, text[first.Start:last.End])
+		all = append(all, sent.Tokens...)
+	}
+	assert.Equal(t, doc.Tokens(), all)
+
+	doc, err = NewDocument(text, WithSegmentation(false))
+	require.NoError(t, err)
+	sents = doc.SentencesWithTokens()
+	require.Len(t, sents, 1)
+	assert.Equal(t, text, sents[0].Text)
+	assert.Equal(t, doc.Tokens(), sents[0].Tokens)
+
+	// Tokens don't cross sentence boundaries.
+	text = "It rained.Then it stopped."
+	doc, err = NewDocument(text, WithExtraction(false), UsingSentenceTokenizer(fixedSegmenter{
+		{Text: "It rained.", Start: 0, End: 10},
+		{Text: "Then it stopped.", Start: 10, End: 26}}))
+	require.NoError(t, err)
+	sents = doc.SentencesWithTokens()
+	require.Len(t, sents, 2)
+	assert.Equal(t, "Then", sents[1].Tokens[0].Text)
+	assert.Equal(t, ".", sents[0].Tokens[len(sents[0].Tokens)-1].Text)
+}
+
+// fixedSegmenter segments any text into the same sentences.
+type fixedSegmenter []Sentence
+
+func (s fixedSegmenter) Segment(string) []Sentence {
+	return append([]Sentence{}, s...)
+}
+
+func TestSentenceAccess(t *testing.T) {
+	text := "Jane Smith lives in Paris. She works for Acme Corp. Really?"
+	doc, err := NewDocument(text)
+	require.NoError(t, err)
+
+	n := doc.NumSentences()
+	require.Equal(t, len(doc.Sentences()), n)
+	var wg sync.WaitGroup
+	all := make([][]Token, n)
+	for i := n - 1; i >= 0; i-- {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			all[i] = doc.TokensInSentence(i)
+		}(i)
+	}
+	wg.Wait()
+	tokens := []Token{}
+	for i, toks := range all {
+		sent := doc.Sentence(i)
+		assert.Equal(t, doc.Sentences()[i], sent)
+		for _, tok := range toks {
+			assert.True(t, tok.Start >= sent.Start && tok.End <= sent.End, tok.Text)
+		}
+		tokens = append(tokens, toks...)
+	}
+	assert.Equal(t, doc.Tokens(), tokens)
+	assert.Panics(t, func() { doc.TokensInSentence(n) })
+	assert.Panics(t, func() { doc.Sentence(-1) })
+
+	doc, err = NewDocument(text, WithSegmentation(false))
+	require.NoError(t, err)
+	require.Equal(t, 1, doc.NumSentences())
+	assert.Equal(t, text, doc.Sentence(0).Text)
+	assert.Equal(t, doc.Tokens(), doc.TokensInSentence(0))
+}
@@ -0,0 +1,38 @@
+package prose
+
+import "errors"
+
+// Errors returned by prose can be matched against these with errors.Is to
+// tell failure modes apart; they're usually wrapped with more details.
+var (
+	// ErrModelNotFound means that a model doesn't exist at the given
+	// location.
+	ErrModelNotFound = errors.New("model not found")
+	// ErrCorruptModel means that a model's files exist but can't be decoded.
+	ErrCorruptModel = errors.New("corrupt model")
+	// ErrInvalidSpan means that a range of text is out of bounds or
+	// inverted.
+	ErrInvalidSpan = errors.New("invalid span")
+	// ErrUnsupportedLanguage means that the input guard (see
+	// WithInputGuard) rejected text that is mostly written in a script
+	// other than Latin, which the bundled English models don't support.
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+	// ErrInvalidUTF8 means that the input guard rejected invalid UTF-8.
+	ErrInvalidUTF8 = errors.New("invalid UTF-8")
+)
+
+// codedError is an error that matches one of the sentinels above, in
+// addition to the error it wraps.
+type codedError struct {
+	code error
+	err  error
+}
+
+func (e *codedError) Error() string        { return e.err.Error() }
+func (e *codedError) Unwrap() error        { return e.err }
+func (e *codedError) Is(target error) bool { return target == e.code }
+
+// withCode makes `err` match `code`.
+func withCode(code, err error) error {
+	return &codedError{code: code, err: err}
+}
@@ -0,0 +1,40 @@
+package prose
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodes(t *testing.T) {
+	_, err := ModelFromDisk(filepath.Join(testdata, "missing"))
+	assert.True(t, errors.Is(err, ErrModelNotFound), err)
+
+	_, err = ModelFromFS("missing", embeddedModel)
+	assert.True(t, errors.Is(err, ErrModelNotFound), err)
+
+	corrupt := fstest.MapFS{
+		"Maxent/labels.gob":  &fstest.MapFile{Data: []byte("garbage")},
+		"Maxent/mapping.gob": &fstest.MapFile{Data: []byte("garbage")},
+		"Maxent/weights.gob": &fstest.MapFile{Data: []byte("garbage")},
+	}
+	_, err = loadModel("corrupt", corrupt, nil)
+	assert.True(t, errors.Is(err, ErrCorruptModel), err)
+	assert.False(t, errors.Is(err, ErrModelNotFound), err)
+
+	_, err = ModelFromReader("corrupt", bytes.NewReader([]byte("garbage")))
+	assert.True(t, errors.Is(err, ErrCorruptModel), err)
+
+	_, err = NewDocument("Hello.", WithFocusSpans([]Span{{Start: 3, End: 1}}))
+	assert.True(t, errors.Is(err, ErrInvalidSpan), err)
+
+	_, err = NewDocument("Это не английский текст.", WithInputGuard(PolicyError))
+	assert.True(t, errors.Is(err, ErrUnsupportedLanguage), err)
+
+	_, err = NewDocument("Bad \xff input.", WithInputGuard(PolicyError))
+	assert.True(t, errors.Is(err, ErrInvalidUTF8), err)
+}
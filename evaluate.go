@@ -0,0 +1,132 @@
+package prose
+
+import (
+	"errors"
+	"strings"
+)
+
+// An EvalResult summarizes how well a Model's NER finds the entities of gold
+// data (see Evaluate): its Score is micro-averaged over every entity, and
+// its Labels score each label.
+type EvalResult struct {
+	SpanReport
+
+	// Examples is the number of gold examples evaluated.
+	Examples int
+}
+
+// EvalOpt is a setting that changes how Evaluate scores a Model.
+type EvalOpt func(opts *EvalOpts)
+
+// EvalOpts controls how Evaluate scores a Model.
+type EvalOpts struct {
+	// Mode determines when a predicted entity matches a gold one; the
+	// default, MatchExact, requires the same span and label, while
+	// MatchOverlap gives credit for overlapping spans with the same label.
+	Mode MatchMode
+
+	// Tokenizer is used to tokenize the data; the default is
+	// NewIterTokenizer(). It should be the one the Model was trained with.
+	Tokenizer Tokenizer
+}
+
+// UsingMatchMode sets the MatchMode that Evaluate scores entities with.
+func UsingMatchMode(mode MatchMode) EvalOpt {
+	return func(opts *EvalOpts) {
+		opts.Mode = mode
+	}
+}
+
+// UsingEvalTokenizer sets the Tokenizer that Evaluate tokenizes data with.
+func UsingEvalTokenizer(tokenizer Tokenizer) EvalOpt {
+	return func(opts *EvalOpts) {
+		opts.Tokenizer = tokenizer
+	}
+}
+
+// Evaluate measures the precision, recall, and F1 of `model`'s NER on the
+// gold data `gold`. Both gold and predicted entities are spans of the
+// tokens the model sees: the gold spans are aligned to the tokens as for
+// training (see UsingEntities), so a gold span that doesn't fall on token
+// boundaries is scored as the tokens it covers. Rejected examples (see
+// EntityContext.Accept) are skipped, since they don't list every entity.
+func Evaluate(model *Model, gold []EntityContext, opts ...EvalOpt) (EvalResult, error) {
+	options := EvalOpts{Mode: MatchExact, Tokenizer: NewIterTokenizer()}
+	for _, applyOpt := range opts {
+		applyOpt(&options)
+	}
+	if model == nil || model.extracter == nil {
+		return EvalResult{}, errors.New("unable to evaluate: model has no NER")
+	} else if model.tagger == nil {
+		return EvalResult{}, errors.New("unable to evaluate: model has no POS tagger")
+	}
+
+	result := EvalResult{SpanReport: SpanReport{Labels: map[string]Score{}}}
+	for i := range gold {
+		example := &gold[i]
+		if !example.Accept {
+			continue
+		}
+		result.Examples++
+
+		tokens := model.tagger.Tag(options.Tokenizer.Tokenize(example.Text))
+		expected := goldEntities(tokens, assignLabels(tokens, example), example.Text)
+
+		predicted := []Entity{}
+		model.extracter.classify(tokens, nil, false, nil)
+		for _, parts := range model.extracter.chunkTokens(tokens) {
+			predicted = append(predicted, coalesce(parts, example.Text))
+		}
+
+		// Both are entities of the example's text.
+		report := ScoreSpans(expected, predicted, options.Mode|MatchByOffset)
+		result.Score = result.Score.add(report.Score)
+		for label, score := range report.Labels {
+			result.Labels[label] = result.Labels[label].add(score)
+		}
+	}
+	return result, nil
+}
+
+// add returns the sum of the counts of `s` and `other`.
+func (s Score) add(other Score) Score {
+	return Score{
+		TruePositives:  s.TruePositives + other.TruePositives,
+		FalsePositives: s.FalsePositives + other.FalsePositives,
+		FalseNegatives: s.FalseNegatives + other.FalseNegatives}
+}
+
+// goldEntities decodes the IOB `labels` of `tokens` (see assignLabels) into
+// entities located in `text`.
+func goldEntities(tokens []*Token, labels []string, text string) []Entity {
+	entities := []Entity{}
+	parts := []*Token{}
+	label := ""
+	flush := func() {
+		if len(parts) > 0 {
+			entity := Entity{
+				Label: label,
+				Text:  JoinTokens(parts),
+				Start: parts[0].Start,
+				End:   parts[len(parts)-1].End}
+			if entity.Start < entity.End && entity.End <= len(text) {
+				entity.Text = text[entity.Start:entity.End]
+			}
+			entities = append(entities, entity)
+			parts = []*Token{}
+		}
+	}
+	for i, tok := range tokens {
+		switch {
+		case strings.HasPrefix(labels[i], "I-") && len(parts) > 0 && labels[i][2:] == label:
+			parts = append(parts, tok)
+		case labels[i] == "O":
+			flush()
+		default:
+			flush()
+			parts, label = []*Token{tok}, labels[i][2:]
+		}
+	}
+	flush()
+	return entities
+}
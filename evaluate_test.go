@@ -0,0 +1,58 @@
+package prose
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	data, err := ReadCoNLL2003(strings.NewReader(conll2003))
+	require.NoError(t, err)
+	model, err := ModelFromData("conll", UsingEntities(data))
+	require.NoError(t, err)
+
+	result, err := Evaluate(model, data)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Examples)
+	assert.Equal(t, Score{TruePositives: 5}, result.Score)
+	assert.Equal(t, 1.0, result.F1())
+	assert.Equal(t, Score{TruePositives: 2}, result.Labels["MISC"])
+
+	// A model that mixes up people and places.
+	var buf bytes.Buffer
+	_, err = model.WriteTo(&buf)
+	require.NoError(t, err)
+	wrong, err := ModelFromReader("wrong", &buf, UsingLabelMap(map[string]string{"PER": "LOC", "LOC": "PER"}))
+	require.NoError(t, err)
+	result, err = Evaluate(wrong, data)
+	require.NoError(t, err)
+	assert.Equal(t, Score{TruePositives: 3, FalsePositives: 2, FalseNegatives: 2}, result.Score)
+	assert.InDelta(t, 0.6, result.Precision(), 1e-9)
+	assert.InDelta(t, 0.6, result.Recall(), 1e-9)
+	assert.InDelta(t, 0.6, result.F1(), 1e-9)
+	assert.Equal(t, Score{FalsePositives: 1, FalseNegatives: 1}, result.Labels["PER"])
+	assert.Equal(t, Score{TruePositives: 1}, result.Labels["ORG"])
+
+	// Gold data that only labels "York" (and rejected examples, which are
+	// skipped).
+	gold := []EntityContext{
+		{Accept: true, Text: "Peter Blackburn visited New York .", Spans: []LabeledEntity{
+			{Start: 0, End: 15, Label: "PER"}, {Start: 28, End: 32, Label: "LOC"}}},
+		{Accept: false, Text: "EU rejects German call .", Spans: []LabeledEntity{
+			{Start: 0, End: 2, Label: "LOC"}}},
+	}
+	result, err = Evaluate(model, gold)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Examples)
+	assert.Equal(t, Score{TruePositives: 1, FalsePositives: 1, FalseNegatives: 1}, result.Score)
+	result, err = Evaluate(model, gold, UsingMatchMode(MatchOverlap))
+	require.NoError(t, err)
+	assert.Equal(t, Score{TruePositives: 2}, result.Score)
+
+	_, err = Evaluate(&Model{}, gold)
+	assert.Error(t, err)
+}
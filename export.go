@@ -0,0 +1,75 @@
+package prose
+
+import "encoding/json"
+
+// docJSON is the schema of a Document's JSON (see Document.MarshalJSON).
+// Its lists are pointers so that those that weren't computed are omitted,
+// while those that were are emitted even if empty.
+type docJSON struct {
+	Text   string       `json:"text"`
+	Sents  *[]spanJSON  `json:"sents,omitempty"`
+	Tokens *[]tokenJSON `json:"tokens,omitempty"`
+	Ents   *[]entJSON   `json:"ents,omitempty"`
+}
+
+type spanJSON struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type tokenJSON struct {
+	Text  string `json:"text"`
+	Tag   string `json:"tag,omitempty"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+type entJSON struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Label string `json:"label"`
+}
+
+// MarshalJSON encodes `doc` in a stable schema similar to spaCy's:
+//
+//	{"text": "...", "sents": [{"start": 0, "end": 12}],
+//	 "tokens": [{"text": "Go", "tag": "NNP", "start": 0, "end": 2}],
+//	 "ents": [{"start": 0, "end": 2, "label": "PRODUCT"}]}
+//
+// Offsets are in bytes, as elsewhere in the package, and those that are
+// unknown (e.g., of tokens given to NewDocumentFromTokens without offsets)
+// are -1. Results that weren't computed, such as "ents" with
+// WithExtraction(false) or tags with WithTagging(false), are omitted.
+func (doc *Document) MarshalJSON() ([]byte, error) {
+	out := docJSON{Text: doc.Text}
+	if doc.config.Segment {
+		sents := make([]spanJSON, 0, len(doc.sentences))
+		for _, sent := range doc.sentences {
+			sents = append(sents, spanJSON{Start: sent.Start, End: sent.End})
+		}
+		out.Sents = &sents
+	}
+	if doc.config.Tokenizer != nil || doc.config.Tokens != nil {
+		tokens := make([]tokenJSON, 0, len(doc.tokens))
+		for _, tok := range doc.tokens {
+			start, end := tok.Start, tok.End
+			if !hasOffsets(tok) {
+				start, end = -1, -1
+			}
+			tokens = append(tokens, tokenJSON{Text: tok.Text, Tag: tok.Tag, Start: start, End: end})
+		}
+		out.Tokens = &tokens
+	}
+	if doc.config.Extract {
+		ents := make([]entJSON, 0, len(doc.entities))
+		for _, ent := range doc.entities {
+			start, end := ent.Start, ent.End
+			if end <= start {
+				start, end = -1, -1
+			}
+			ents = append(ents, entJSON{Start: start, End: end, Label: ent.Label})
+		}
+		out.Ents = &ents
+	}
+	return json.Marshal(out)
+}
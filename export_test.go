@@ -0,0 +1,55 @@
+package prose
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestDocumentJSON(t *testing.T) {
+	text := "Go is a language from Google. Robert Griesemer designed it in 2007!"
+	tokens := [][]*Token{
+		{{Text: "Ann"}, {Text: "met"}, {Text: "Bob"}, {Text: "."}},
+		{{Text: "Bye"}, {Text: "."}}}
+	for name, create := range map[string]func() (*Document, error){
+		"default": func() (*Document, error) {
+			return NewDocument(text)
+		},
+		"no-extraction": func() (*Document, error) {
+			return NewDocument(text, WithExtraction(false))
+		},
+		"tokens-only": func() (*Document, error) {
+			return NewDocument(text, WithExtraction(false), WithTagging(false), WithSegmentation(false))
+		},
+		"sentences-only": func() (*Document, error) {
+			return NewDocument(text, WithExtraction(false), WithTokenization(false))
+		},
+		"empty": func() (*Document, error) {
+			return NewDocument("")
+		},
+		"pretokenized": func() (*Document, error) {
+			return NewDocumentFromTokens("", tokens)
+		},
+	} {
+		doc, err := create()
+		require.NoError(t, err, name)
+		actual, err := json.MarshalIndent(doc, "", "  ")
+		require.NoError(t, err, name)
+		actual = append(actual, '\n')
+
+		golden := filepath.Join(testdata, "json", name+".json")
+		if *update {
+			require.NoError(t, ioutil.WriteFile(golden, actual, 0644))
+		}
+		expected, err := ioutil.ReadFile(golden)
+		require.NoError(t, err, name)
+		assert.Equal(t, string(expected), string(actual), "%s (run with -update to accept changes)", name)
+	}
+}
@@ -1,49 +1,18 @@
 package prose
 
 import (
-	"encoding/gob"
+	"errors"
 	"fmt"
+	"io"
 	"math"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
 
-	"gonum.org/v1/gonum/mat"
+	"github.com/zuvaai/prose/v3/internal/gis"
 )
 
-var maxLogDiff = math.Log2(1e-30)
-
-type mappedProbDist struct {
-	dict map[string]*probEnc
-	log  bool
-}
-
-func newMappedProbDist(dict map[string]*probEnc, normalize bool) *mappedProbDist {
-	if normalize {
-		values := make([]float64, len(dict))
-		i := 0
-		for _, v := range dict {
-			values[i] = v.prob
-			i++
-		}
-		sum := sumLogs(values)
-		if sum <= math.Inf(-1) {
-			p := math.Log2(1.0 / float64(len(dict)))
-			for _, pe := range dict {
-				pe.prob = p
-			}
-		} else {
-			for _, pe := range dict {
-				pe.prob -= sum
-			}
-		}
-	}
-	return &mappedProbDist{dict: dict, log: true}
-}
-
 type encodedValue struct {
 	key   int
 	value int
@@ -51,15 +20,89 @@ type encodedValue struct {
 
 type feature struct {
 	label    string
-	features [17]string
+	features [numFeatures]string
 }
 
 type featureSet []feature
 
+// numFeatures is the number of input-features extracted for each token.
+const numFeatures = 18
+
+// numCoreFeatures is the number of input-features that every model has;
+// those after them in featureOrder are optional.
+const numCoreFeatures = 17
+
+// featureOrder names the input-features. Optional features (such as "block")
+// with an empty value are absent, which lets them coexist with models trained
+// without them; core features are encoded even when empty, as they always
+// have been, so that existing models score tokens as they were trained to.
 var featureOrder = []string{
 	"bias", "en-wordlist", "nextpos", "nextword", "pos", "pos+prevtag",
 	"prefix3", "prevpos", "prevtag", "prevword", "shape", "shape+prevtag",
-	"suffix3", "word", "word+nextpos", "word.lower", "wordlen"}
+	"suffix3", "word", "word+nextpos", "word.lower", "wordlen", "block"}
+
+// absentFeature determines if the i-th input-feature, whose value is `val`,
+// is absent (see featureOrder).
+func absentFeature(i int, val string) bool {
+	return val == "" && i >= numCoreFeatures
+}
+
+// allFeatures holds the indices of every input-feature.
+var allFeatures = func() []int {
+	indices := make([]int, numFeatures)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}()
+
+// labelTemplates maps each of `labels` whose entity label (or "O") has an
+// entry in `labelFeatures` (see TrainingOptions.LabelFeatures) to the
+// indices of its input-features.
+func labelTemplates(labelFeatures map[string][]string, labels []string) (map[string][]int, error) {
+	if len(labelFeatures) == 0 {
+		return nil, nil
+	}
+	templates := map[string][]int{}
+	for _, label := range labels {
+		names, found := labelFeatures[entityLabel(label)]
+		if !found {
+			continue
+		}
+		indices, err := featureIndices(names)
+		if err != nil {
+			return nil, fmt.Errorf("invalid features for %s: %w", entityLabel(label), err)
+		}
+		templates[label] = indices
+	}
+	return templates, nil
+}
+
+// featureIndices returns the indices of the input-features `names` in
+// featureOrder.
+func featureIndices(names []string) ([]int, error) {
+	indices := []int{}
+	for i, name := range featureOrder {
+		if stringInSlice(name, names) {
+			indices = append(indices, i)
+		}
+	}
+	for _, name := range names {
+		if !stringInSlice(name, featureOrder) {
+			return nil, fmt.Errorf("unknown feature %q", name)
+		}
+	}
+	return indices, nil
+}
+
+// entityLabel returns the entity label of the IOB label `label` (e.g.,
+// "ORG" for "B-ORG"), or "O" outside of entities.
+func entityLabel(label string) string {
+	if parts := strings.SplitN(label, "-", 2); len(parts) == 2 {
+		return parts[1]
+	}
+	return label
+}
 
 // binaryMaxentClassifier is a feature encoding that generates vectors
 // containing binary joint-features of the form:
@@ -73,12 +116,20 @@ var featureOrder = []string{
 //
 // See https://www.nltk.org/_modules/nltk/classify/maxent.html for more
 // information.
+//
+// If `hashSize` is positive, joint-features are hashed into a fixed number of
+// weights rather than being looked up in `mapping`.
 type binaryMaxentClassifier struct {
 	cardinality int
+	hashSize    int
 	labels      []string
 	mapping     map[string]int
 	weights     []float64
-	buf         []byte
+
+	// templates restricts the input-features of some labels to those at the
+	// given indices of featureOrder (see TrainingOptions.LabelFeatures);
+	// other labels use every input-feature.
+	templates map[string][]int
 }
 
 // newMaxentClassifier creates a new binaryMaxentClassifier from the provided
@@ -88,6 +139,12 @@ func newMaxentClassifier(
 	mapping map[string]int,
 	labels []string) *binaryMaxentClassifier {
 
+	if len(mapping) == 0 && len(weights) > 0 {
+		// A hashed model stores no mapping, only its weights (plus the GIS
+		// correction feature).
+		return newHashedMaxentClassifier(weights, len(weights)-1, labels)
+	}
+
 	set := make(map[string]struct{})
 	for label := range mapping {
 		k := strings.Split(label, "-")[0]
@@ -96,49 +153,189 @@ func newMaxentClassifier(
 
 	return &binaryMaxentClassifier{
 		len(set) + 1,
+		0,
 		labels,
 		mapping,
 		weights,
-		[]byte{}}
+		nil}
 }
 
-// marshal saves the model to disk.
-func (m *binaryMaxentClassifier) marshal(path string) error {
-	folder := filepath.Join(path, "Maxent")
-	err := os.Mkdir(folder, os.ModePerm)
-	if err != nil {
-		return fmt.Errorf("unable to create directory: %w", err)
+// newHashedMaxentClassifier creates a new binaryMaxentClassifier that hashes
+// its joint-features into `size` weights.
+func newHashedMaxentClassifier(
+	weights []float64,
+	size int,
+	labels []string) *binaryMaxentClassifier {
+	return &binaryMaxentClassifier{
+		len(featureOrder) + 1,
+		size,
+		labels,
+		map[string]int{},
+		weights,
+		nil}
+}
+
+// features returns the indices of the input-features used by `label`.
+func (m *binaryMaxentClassifier) features(label string) []int {
+	if indices, found := m.templates[label]; found {
+		return indices
 	}
-	for i, entry := range []string{"labels", "mapping", "weights"} {
-		component, _ := os.Create(filepath.Join(folder, entry+".gob"))
-		encoder := gob.NewEncoder(component)
-		if i == 0 {
-			err = encoder.Encode(m.labels)
-			if err != nil {
-				err = fmt.Errorf("unable to marshal labels: %w", err)
-			}
-		} else if i == 1 {
-			err = encoder.Encode(m.mapping)
-			if err != nil {
-				err = fmt.Errorf("unable to marshal mapping: %w", err)
+	return allFeatures
+}
+
+// size returns the number of joint-features known to the classifier.
+func (m *binaryMaxentClassifier) size() int {
+	if m.hashSize > 0 {
+		return m.hashSize
+	}
+	return len(m.mapping)
+}
+
+// check determines if the classifier's weights are consistent with its
+// features and labels, so that a corrupt model is rejected when it's loaded
+// rather than when it's used.
+func (m *binaryMaxentClassifier) check() error {
+	if len(m.labels) == 0 {
+		return withCode(ErrCorruptModel, errors.New("no labels"))
+	} else if len(m.weights) <= m.size() {
+		// Every feature, plus the correction feature, needs a weight.
+		return withCode(ErrCorruptModel, fmt.Errorf("%d weights for %d features",
+			len(m.weights), m.size()))
+	}
+	for feature, idx := range m.mapping {
+		if idx < 0 || idx >= len(m.weights) {
+			return withCode(ErrCorruptModel, fmt.Errorf("feature %q has invalid index %d",
+				feature, idx))
+		}
+	}
+	return nil
+}
+
+// relabel renames the entity types of the classifier's IOB labels according
+// to `names` (e.g., "PER" -> "PERSON" turns "B-PER" into "B-PERSON"),
+// rewriting the joint-features that embed them.
+func (m *binaryMaxentClassifier) relabel(names map[string]string) error {
+	if m.hashSize > 0 {
+		return fmt.Errorf("hashed models can't be relabeled")
+	}
+
+	renamed := make(map[string]string, len(m.labels))
+	seen := make(map[string]bool, len(m.labels))
+	for _, label := range m.labels {
+		parts := strings.SplitN(label, "-", 2)
+		if len(parts) == 2 {
+			if name, found := names[parts[1]]; found {
+				renamed[label] = parts[0] + "-" + name
 			}
+		}
+		to := label
+		if name, found := renamed[label]; found {
+			to = name
+		}
+		if seen[to] {
+			return fmt.Errorf("duplicate label %q", to)
+		}
+		seen[to] = true
+	}
 
-		} else {
-			err = encoder.Encode(m.weights)
-			if err != nil {
-				return fmt.Errorf("unable to marshal weights: %w", err)
+	mapping := make(map[string]int, len(m.mapping))
+	for key, idx := range m.mapping {
+		for from, to := range renamed {
+			if strings.HasSuffix(key, "-"+from) {
+				key = strings.TrimSuffix(key, from) + to
+				break
 			}
 		}
+		mapping[key] = idx
+	}
+	// The templates are rebuilt, rather than renamed in place, so that
+	// swapped labels keep theirs.
+	var templates map[string][]int
+	if m.templates != nil {
+		templates = make(map[string][]int, len(m.templates))
+		for label, indices := range m.templates {
+			if name, found := renamed[label]; found {
+				label = name
+			}
+			templates[label] = indices
+		}
+	}
+	for i, label := range m.labels {
+		if name, found := renamed[label]; found {
+			m.labels[i] = name
+		}
 	}
+	m.mapping = mapping
+	m.templates = templates
+
 	return nil
 }
 
+// prune removes joint-features whose weights have an absolute value below
+// `threshold`, returning the number of features before and after and the
+// total absolute weight removed.
+//
+// The GIS correction feature and the classifier's cardinality are preserved,
+// so probabilities remain comparable with the unpruned model.
+func (m *binaryMaxentClassifier) prune(threshold float64) (int, int, float64) {
+	before, mass := m.size(), 0.0
+	if m.hashSize > 0 {
+		// Hashed weights are positional, so we can only zero them out.
+		after := 0
+		for i := 0; i < m.hashSize; i++ {
+			if math.Abs(m.weights[i]) < threshold {
+				mass += math.Abs(m.weights[i])
+				m.weights[i] = 0
+			} else if m.weights[i] != 0 {
+				after++
+			}
+		}
+		return before, after, mass
+	}
+
+	mapping := make(map[string]int, len(m.mapping))
+	weights := make([]float64, 0, len(m.weights))
+	for entry, idx := range m.mapping {
+		w := m.weights[idx]
+		if math.Abs(w) < threshold {
+			mass += math.Abs(w)
+			continue
+		}
+		mapping[entry] = len(weights)
+		weights = append(weights, w)
+	}
+	weights = append(weights, m.weights[len(m.weights)-1])
+
+	m.mapping = mapping
+	m.weights = weights
+	return before, len(mapping), mass
+}
+
 // entityExtracter is a maximum entropy classifier.
 //
 // See https://www.nltk.org/_modules/nltk/classify/maxent.html for more
 // information.
 type entityExtracter struct {
 	model *binaryMaxentClassifier
+
+	// lookup holds decisions distilled from the model (see Model.Distill).
+	lookup map[string]string
+
+	// tokenizer is the fingerprint of the tokenizer the model was trained
+	// with, if known.
+	tokenizer string
+
+	// scheme is the declared set of entity labels (see
+	// TrainingOptions.Scheme), if any.
+	scheme []string
+
+	// manifest describes the training data (see TrainingOptions.Snapshot),
+	// if known.
+	manifest *DataManifest
+
+	// digest summarizes the contents of the extracter, once computed (see
+	// Model.fingerprint).
+	digest string
 }
 
 // newEntityExtracter creates a new entityExtracter using the default model.
@@ -147,34 +344,21 @@ func newEntityExtracter() (*entityExtracter, error) {
 	var weights []float64
 	var labels []string
 
-	dec, err := getAsset("Maxent", "mapping.gob")
-	if err != nil {
-		return nil, fmt.Errorf("unable to load mapping.gob: %w", err)
+	if err := decodeAsset("Maxent/mapping.gob", &mapping); err != nil {
+		return nil, fmt.Errorf("unable to load mapping: %w", err)
 	}
-	err = dec.Decode(&mapping)
-	if err != nil {
-		return nil, fmt.Errorf("unable to decode mapping.gob: %w", err)
+	if err := decodeAsset("Maxent/weights.gob", &weights); err != nil {
+		return nil, fmt.Errorf("unable to load weights: %w", err)
 	}
-
-	dec, err = getAsset("Maxent", "weights.gob")
-	if err != nil {
-		return nil, fmt.Errorf("unable to load weights.gob: %w", err)
-	}
-	err = dec.Decode(&weights)
-	if err != nil {
-		return nil, fmt.Errorf("unable to decode weights.gob: %w", err)
+	if err := decodeAsset("Maxent/labels.gob", &labels); err != nil {
+		return nil, fmt.Errorf("unable to load labels: %w", err)
 	}
 
-	dec, err = getAsset("Maxent", "labels.gob")
-	if err != nil {
-		return nil, fmt.Errorf("unable to load labels.gob: %w", err)
-	}
-	err = dec.Decode(&labels)
-	if err != nil {
-		return nil, fmt.Errorf("unable to decode labels.gob: %w", err)
+	model := newMaxentClassifier(weights, mapping, labels)
+	if err := model.check(); err != nil {
+		return nil, fmt.Errorf("invalid embedded NER: %w", err)
 	}
-
-	return &entityExtracter{model: newMaxentClassifier(weights, mapping, labels)}, nil
+	return &entityExtracter{model: model, digest: "embedded"}, nil
 }
 
 // newTrainedEntityExtracter creates a new EntityExtracter using the given
@@ -186,6 +370,15 @@ func newTrainedEntityExtracter(model *binaryMaxentClassifier) *entityExtracter {
 // chunk finds named-entity "chunks" from the given, pre-labeled tokens.
 func (e *entityExtracter) chunk(tokens []*Token) []Entity {
 	entities := []Entity{}
+	for _, parts := range e.chunkTokens(tokens) {
+		entities = append(entities, coalesce(parts, ""))
+	}
+	return entities
+}
+
+// chunkTokens groups the labeled `tokens` into the tokens of each entity.
+func (e *entityExtracter) chunkTokens(tokens []*Token) [][]*Token {
+	groups := [][]*Token{}
 	end := ""
 
 	parts := []*Token{}
@@ -204,38 +397,47 @@ func (e *entityExtracter) chunk(tokens []*Token) []Entity {
 			if label != "O" {
 				parts = append(parts, tok)
 			}
-			entities = append(entities, coalesce(parts))
+			groups = append(groups, parts)
 
 			end = ""
 			parts = []*Token{}
 			idx = 0
 		}
 	}
+	if len(parts) > 0 {
+		// The text ended inside an entity.
+		groups = append(groups, parts)
+	}
 
-	return entities
+	return groups
 }
 
-func (m *binaryMaxentClassifier) byteJoin(a, b, c string) string {
-	jc := byte('-')
-	n := len(a) + len(b) + len(c) + 2
-	if len(m.buf) < n {
-		m.buf = make([]byte, n)
-	}
-	copy(m.buf[0:], []byte(a))
-	m.buf[len(a)] = jc
-	copy(m.buf[len(a)+1:], []byte(b))
-	m.buf[len(a)+len(b)+1] = jc
-	copy(m.buf[len(a)+len(b)+2:], []byte(c))
-	res := string(m.buf[:n])
-	return res
+// byteJoin joins `a`, `b`, and `c` with dashes into `buf`, which it returns
+// (grown, if necessary).
+func byteJoin(buf []byte, a, b, c string) []byte {
+	buf = append(buf[:0], a...)
+	buf = append(buf, '-')
+	buf = append(buf, b...)
+	buf = append(buf, '-')
+	return append(buf, c...)
 }
 
-func (m *binaryMaxentClassifier) encode(features [17]string, label string) []encodedValue {
+// encode returns the joint-features of `features` and `label`. It's safe
+// for concurrent use: the classifier isn't modified.
+func (m *binaryMaxentClassifier) encode(features [numFeatures]string, label string) []encodedValue {
 	encoding := make([]encodedValue, 0, 18)
-	for i, key := range featureOrder {
+	buf := make([]byte, 0, 64)
+	for _, i := range m.features(label) {
 		val := features[i]
-		entry := m.byteJoin(key, val, label)
-		if ret, found := m.mapping[entry]; found {
+		if absentFeature(i, val) {
+			continue
+		}
+		buf = byteJoin(buf, featureOrder[i], val, label)
+		if m.hashSize > 0 {
+			encoding = append(encoding, encodedValue{
+				key:   hashFeature(buf, m.hashSize),
+				value: 1})
+		} else if ret, found := m.mapping[string(buf)]; found {
 			encoding = append(encoding, encodedValue{
 				key:   ret,
 				value: 1})
@@ -244,9 +446,9 @@ func (m *binaryMaxentClassifier) encode(features [17]string, label string) []enc
 	return encoding
 }
 
-func (m *binaryMaxentClassifier) encodeGIS(features [17]string, label string) []encodedValue {
+func (m *binaryMaxentClassifier) encodeGIS(features [numFeatures]string, label string) []encodedValue {
 	encoding := m.encode(features, label)
-	length := len(m.mapping)
+	length := m.size()
 
 	total := 0
 	for _, v := range encoding {
@@ -259,30 +461,15 @@ func (m *binaryMaxentClassifier) encodeGIS(features [17]string, label string) []
 	return encoding
 }
 
-func adjustPos(text string, start, end int) (int, int) {
-	index, left, right := -1, 0, 0
-	_ = strings.Map(func(r rune) rune {
-		index++
-		if unicode.IsSpace(r) {
-			if index < start {
-				left++
-			}
-			if index < end {
-				right++
-			}
-			return -1
-		}
-		return r
-	}, text)
-	return start - left, end - right
-}
-
-func extractFeatures(tokens []*Token, history []string) []feature {
+func extractFeatures(tokens []*Token, history, context []string) []feature {
 	features := make([]feature, len(tokens))
 	for i := range tokens {
 		features[i] = feature{
 			label:    history[i],
 			features: extract(i, tokens, history)}
+		if context != nil {
+			features[i].features[17] = context[i]
+		}
 	}
 	return features
 }
@@ -294,8 +481,9 @@ func assignLabels(tokens []*Token, entity *EntityContext) []string {
 	}
 
 	if entity.Accept {
+		mapper := NewStreamMapper(entity.Text)
 		for _, span := range entity.Spans {
-			start, end := adjustPos(entity.Text, span.Start, span.End)
+			start, end := mapper.ToStream(span.Start), mapper.ToStream(span.End)
 			index := 0
 			for i, tok := range tokens {
 				if index == start {
@@ -311,90 +499,229 @@ func assignLabels(tokens []*Token, entity *EntityContext) []string {
 	return history
 }
 
-func makeCorpus(data []EntityContext, tagger *PerceptronTagger, tokenizer Tokenizer) featureSet {
+func makeCorpus(data []EntityContext, tagger *PerceptronTagger, tokenizer Tokenizer, opts TrainingOptions) featureSet {
 	corpus := featureSet{}
+	negatives, kept := 0, 0
 	for i := range data {
 		entry := &data[i]
 		tokens := tagger.Tag(tokenizer.Tokenize(entry.Text))
 		history := assignLabels(tokens, entry)
-		for _, element := range extractFeatures(tokens, history) {
+		if opts.NegativeRate > 0 && opts.NegativeRate < 1 && !hasEntity(history) {
+			negatives++
+			if float64(kept) >= opts.NegativeRate*float64(negatives) {
+				continue
+			}
+			kept++
+		}
+		var context []string
+		if opts.BlockContext {
+			context = blockContext(entry.Text, tokens)
+		}
+		for _, element := range extractFeatures(tokens, history, context) {
+			if opts.Debug != nil && (opts.DebugEvery <= 1 || len(corpus)%opts.DebugEvery == 0) {
+				dumpFeature(opts.Debug, element)
+			}
 			corpus = append(corpus, element)
 		}
 	}
 	return corpus
 }
 
-func extracterFromData(corpus featureSet) *entityExtracter {
-	encoding := encode(corpus)
-	cInv := 1.0 / float64(encoding.cardinality)
+// dumpFeature writes the label and features of `element` to `w` as a single
+// line.
+func dumpFeature(w io.Writer, element feature) {
+	fields := []string{element.label}
+	for i, name := range featureOrder {
+		if value := element.features[i]; value != "" {
+			fields = append(fields, name+"="+value)
+		}
+	}
+	fmt.Fprintln(w, strings.Join(fields, "\t"))
+}
 
-	empfreq := empiricalCount(corpus, encoding)
-	rows, _ := empfreq.Dims()
+// hasEntity determines if any of the labels in `history` is part of an
+// entity.
+func hasEntity(history []string) bool {
+	for _, label := range history {
+		if label != "O" {
+			return true
+		}
+	}
+	return false
+}
 
-	unattested := []int{}
-	for index := 0; index < rows; index++ {
-		if empfreq.At(index, 0) == 0.0 {
-			unattested = append(unattested, index)
+func extracterFromData(corpus featureSet, opts TrainingOptions, base *entityExtracter) (*entityExtracter, error) {
+	var encoding *binaryMaxentClassifier
+	if opts.Resume != nil {
+		// The checkpoint's weights (including frozen ones) are kept as-is.
+		resumed := *opts.Resume.extracter.model
+		resumed.weights = append([]float64{}, resumed.weights...)
+		encoding, base = &resumed, opts.Resume.extracter
+	} else {
+		labels := corpusLabels(corpus)
+		templates, err := labelTemplates(opts.LabelFeatures, labels)
+		if err != nil {
+			return nil, err
+		}
+		if opts.HashSize > 0 {
+			encoding = newHashedMaxentClassifier([]float64{}, opts.HashSize, labels)
+			encoding.templates = templates
+			base = nil
+		} else {
+			encoding = encode(corpus, labels, templates)
+		}
+		if base != nil {
+			warmStart(encoding, base.model)
 		}
-		empfreq.SetVec(index, math.Log2(empfreq.At(index, 0)))
+	}
+	// Unattested features are frozen: features inherited from a warm-start
+	// model keep their weights, unseen features can collide with unattested
+	// buckets in a hashed model (so those stay neutral rather than vetoing a
+	// label), and everything else vetoes its label outright.
+	if len(encoding.weights) != encoding.size()+1 {
+		encoding.weights = make([]float64, encoding.size()+1)
+	}
+	frozen := func(index int) float64 {
+		if encoding.hashSize > 0 {
+			return 0
+		} else if base != nil {
+			return encoding.weights[index]
+		}
+		return math.Inf(-1)
 	}
 
-	weights := make([]float64, rows)
-	for _, idx := range unattested {
-		weights[idx] = math.Inf(-1)
+	iterations := opts.Iterations
+	if iterations <= 0 {
+		iterations = 100
 	}
-	encoding.weights = weights
 
 	classifier := newTrainedEntityExtracter(encoding)
-	for index := 0; index < 100; index++ {
-		est := estCount(classifier, corpus, encoding)
-		for _, idx := range unattested {
-			est.SetVec(idx, est.AtVec(idx)+1)
+	err := gis.Train(newGISCorpus(corpus, encoding), encoding.weights, gis.Options{
+		Cardinality: encoding.cardinality,
+		Start:       opts.ResumeIteration,
+		Iterations:  iterations,
+		Tolerance:   opts.Tolerance,
+		Frozen:      frozen,
+		Before: func(iteration int) error {
+			if opts.Context == nil {
+				return nil
+			} else if err := opts.Context.Err(); err != nil {
+				return fmt.Errorf("training canceled after %d iterations: %w", iteration, err)
+			}
+			return nil
+		},
+		Progress: opts.Progress,
+		After: func(done int) error {
+			if opts.CheckpointEvery <= 0 || opts.Checkpoint == nil || done%opts.CheckpointEvery != 0 {
+				return nil
+			}
+			checkpoint := &Model{Name: "checkpoint", extracter: classifier}
+			if err := opts.Checkpoint(checkpoint, done); err != nil {
+				return fmt.Errorf("unable to checkpoint iteration %d: %w", done, err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return classifier, nil
+}
+
+// warmStart extends `encoding` with the joint-features and labels of `base`
+// and initializes its weights from those of `base`.
+func warmStart(encoding, base *binaryMaxentClassifier) {
+	for entry := range base.mapping {
+		if _, found := encoding.mapping[entry]; !found {
+			encoding.mapping[entry] = len(encoding.mapping)
 		}
-		rows, _ := est.Dims()
-		for index := 0; index < rows; index++ {
-			est.SetVec(index, math.Log2(est.At(index, 0)))
+	}
+	for _, label := range base.labels {
+		if !stringInSlice(label, encoding.labels) {
+			encoding.labels = append(encoding.labels, label)
 		}
-		weights = classifier.model.weights
+	}
 
-		est.SubVec(empfreq, est)
-		est.ScaleVec(cInv, est)
+	weights := make([]float64, len(encoding.mapping)+1)
+	for entry, idx := range base.mapping {
+		weights[encoding.mapping[entry]] = base.weights[idx]
+	}
+	weights[len(encoding.mapping)] = base.weights[len(base.mapping)]
+	encoding.weights = weights
+}
 
-		for index := 0; index < len(weights); index++ {
-			weights[index] += est.AtVec(index)
-		}
+// gisCorpus adapts a training corpus, encoded by a classifier, to the GIS
+// trainer.
+type gisCorpus struct {
+	corpus   featureSet
+	encoding *binaryMaxentClassifier
+	labels   map[string]int
+}
 
-		classifier.model.weights = weights
+func newGISCorpus(corpus featureSet, encoding *binaryMaxentClassifier) *gisCorpus {
+	labels := make(map[string]int, len(encoding.labels))
+	for i, label := range encoding.labels {
+		labels[label] = i
 	}
+	return &gisCorpus{corpus: corpus, encoding: encoding, labels: labels}
+}
 
-	return classifier
+func (c *gisCorpus) Len() int    { return len(c.corpus) }
+func (c *gisCorpus) Labels() int { return len(c.encoding.labels) }
+
+func (c *gisCorpus) Label(i int) int {
+	if j, found := c.labels[c.corpus[i].label]; found {
+		return j
+	}
+	return -1
 }
 
-func estCount(
-	classifier *entityExtracter,
-	corpus featureSet,
-	encoder *binaryMaxentClassifier,
-) *mat.VecDense {
-	count := mat.NewVecDense(len(encoder.mapping)+1, nil)
-	for _, entry := range corpus {
-		pdist := classifier.probClassify(entry.features)
-		for _, pe := range pdist.dict {
-			prob := math.Pow(2, pe.prob)
-			for _, enc := range pe.vec {
-				out := count.AtVec(enc.key) + (prob * float64(enc.value))
-				count.SetVec(enc.key, out)
-			}
-		}
+func (c *gisCorpus) Encode(i, j int) []gis.Feature {
+	vec := c.encoding.encodeGIS(c.corpus[i].features, c.encoding.labels[j])
+	features := make([]gis.Feature, len(vec))
+	for k, enc := range vec {
+		features[k] = gis.Feature{Index: enc.key, Value: float64(enc.value)}
 	}
-	return count
+	return features
 }
 
-func (e *entityExtracter) classify(tokens []*Token) []*Token {
+// classify labels `tokens`; `context`, if non-nil, holds the kind of
+// structural block (see blockContext) containing each token. If `keep` is
+// true, tokens that already have a label keep it. Decisions are recorded in
+// `trace`, if non-nil.
+func (e *entityExtracter) classify(tokens []*Token, context []string, keep bool, trace *nerTrace) []*Token {
 	length := len(tokens)
 	history := make([]string, 0, length)
+	previous := func(i int) string {
+		if i == 0 {
+			return NoneFeat
+		}
+		return history[i-1]
+	}
 	for i := 0; i < length; i++ {
+		if keep && tokens[i].Label != "" {
+			if tokens[i].Confidence == 0 {
+				tokens[i].Confidence = 1
+			}
+			history = append(history, simplePOS(tokens[i].Label))
+			trace.record(tokens[i], SourceInput, previous(i), history[i])
+			continue
+		}
+		if e.lookup != nil {
+			if label, found := e.lookup[distillKey(i, tokens, history)]; found {
+				tokens[i].Label = label
+				tokens[i].Confidence = 1
+				history = append(history, simplePOS(label))
+				trace.record(tokens[i], SourceLookup, previous(i), history[i])
+				continue
+			}
+		}
 		scores := make(map[string]float64)
 		features := extract(i, tokens, history)
+		if context != nil {
+			features[17] = context[i]
+		}
 		for _, label := range e.model.labels {
 			total := 0.0
 			for _, encoded := range e.model.encode(features, label) {
@@ -404,11 +731,28 @@ func (e *entityExtracter) classify(tokens []*Token) []*Token {
 		}
 		label := maxMap(scores)
 		tokens[i].Label = label
+		tokens[i].Confidence = labelProbability(scores, e.model.labels, label)
 		history = append(history, simplePOS(label))
+		trace.record(tokens[i], SourceModel, previous(i), history[i])
 	}
 	return tokens
 }
 
+// labelProbability returns the probability of `label` in the distribution
+// given by the (log2) `scores` of `labels`, normalized in a fixed order, for
+// reproducibility.
+func labelProbability(scores map[string]float64, labels []string, label string) float64 {
+	values := make([]float64, len(labels))
+	for i, l := range labels {
+		values[i] = scores[l]
+	}
+	sum := gis.SumLogs(values)
+	if sum <= math.Inf(-1) {
+		return 1.0 / float64(len(labels))
+	}
+	return math.Pow(2, scores[label]-sum)
+}
+
 func maxMap(scores map[string]float64) string {
 	var class string
 	max := math.Inf(-1)
@@ -421,53 +765,44 @@ func maxMap(scores map[string]float64) string {
 	return class
 }
 
-type probEnc struct {
-	prob float64
-	vec  []encodedValue
-}
-
-func (e *entityExtracter) probClassify(features [17]string) *mappedProbDist {
-	scores := make(map[string]*probEnc, len(e.model.labels))
-	for _, label := range e.model.labels {
-		vec := e.model.encodeGIS(features, label)
-		total := 0.0
-		for _, entry := range vec {
-			total += e.model.weights[entry.key] * float64(entry.value)
-		}
-		scores[label] = &probEnc{prob: total, vec: vec}
-	}
-
-	//&mappedProbDist{dict: scores, log: true}
-	return newMappedProbDist(scores, true)
-}
-
 func parseEntities(ents []string) string {
 	if stringInSlice("B-PERSON", ents) && len(ents) == 2 {
 		// PERSON takes precedence because it's hard to identify.
 		return "PERSON"
 	}
-	return strings.Split(ents[0], "-")[1]
+	return strings.SplitN(ents[0], "-", 2)[1]
 }
 
-func coalesce(parts []*Token) Entity {
+// coalesce builds the entity made up of `parts`. Its Text is sliced from
+// `text`, which the tokens' offsets refer to, so that it's exactly as
+// written; without a `text` (or offsets), it's reconstructed by JoinTokens.
+func coalesce(parts []*Token, text string) Entity {
 	length := len(parts)
 	labels := make([]string, length)
-	tokens := make([]string, length)
 	for i, tok := range parts {
-		tokens[i] = tok.Text
 		labels[i] = tok.Label
 	}
-	return Entity{
-		Label: parseEntities(labels),
-		Text:  strings.Join(tokens, " "),
+	entity := Entity{
+		Label:      parseEntities(labels),
+		Text:       JoinTokens(parts),
+		Start:      parts[0].Start,
+		End:        parts[length-1].End,
+		Confidence: 1,
 	}
+	for _, tok := range parts {
+		entity.Confidence = math.Min(entity.Confidence, tok.Confidence)
+	}
+	if entity.Start < entity.End && entity.End <= len(text) {
+		entity.Text = text[entity.Start:entity.End]
+	}
+	return entity
 }
 
 const NoneFeat = "None"
 
-func extract(i int, ctx []*Token, history []string) [17]string {
+func extract(i int, ctx []*Token, history []string) [numFeatures]string {
 	//feats := make(map[string]string)
-	feats := [17]string{}
+	feats := [numFeatures]string{}
 	word := ctx[i].Text
 	prevShape := NoneFeat
 
@@ -502,25 +837,37 @@ func extract(i int, ctx []*Token, history []string) [17]string {
 		feats[2] = strings.ToLower(ctx[i+1].Tag)
 	}
 
-	feats[14] = strings.Join(
-		[]string{feats[15], feats[2]}, "+")
-	feats[5] = strings.Join(
-		[]string{feats[4], feats[8]}, "+")
-	feats[11] = strings.Join(
-		[]string{prevShape, feats[8]}, "+")
+	// Build the three conjunctions in a single allocation.
+	pairs := [3][2]string{{feats[15], feats[2]}, {feats[4], feats[8]}, {prevShape, feats[8]}}
+	var b strings.Builder
+	b.Grow(len(feats[15]) + len(feats[2]) + len(feats[4]) + len(prevShape) + 2*len(feats[8]) + 3)
+	ends := [3]int{}
+	for k, pair := range pairs {
+		b.WriteString(pair[0])
+		b.WriteByte('+')
+		b.WriteString(pair[1])
+		ends[k] = b.Len()
+	}
+	joined := b.String()
+	feats[14], feats[5], feats[11] = joined[:ends[0]], joined[ends[0]:ends[1]], joined[ends[1]:]
 
 	return feats
 }
 
+var (
+	trailingPunctRE = regexp.MustCompile(`\W+$`)
+	trailingWordRE  = regexp.MustCompile(`\w+$`)
+)
+
 func shape(word string) string {
 	if isNumeric(word) {
 		return "number"
-	} else if match, _ := regexp.MatchString(`\W+$`, word); match {
+	} else if trailingPunctRE.MatchString(word) {
 		return "punct"
-	} else if match, _ := regexp.MatchString(`\w+$`, word); match {
+	} else if trailingWordRE.MatchString(word) {
 		if strings.ToLower(word) == word {
 			return "downcase"
-		} else if strings.Title(word) == word {
+		} else if isTitled(word) {
 			return "upcase"
 		} else {
 			return "mixedcase"
@@ -529,6 +876,33 @@ func shape(word string) string {
 	return "other"
 }
 
+// isTitled determines if `word` is unchanged by (the deprecated)
+// strings.Title: every letter that starts a word is in title case.
+func isTitled(word string) bool {
+	prev := ' '
+	for _, r := range word {
+		if isTitleSeparator(prev) && unicode.ToTitle(r) != r {
+			return false
+		}
+		prev = r
+	}
+	return true
+}
+
+// isTitleSeparator matches the word boundaries of strings.Title.
+func isTitleSeparator(r rune) bool {
+	if r <= 0x7F {
+		switch {
+		case '0' <= r && r <= '9', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', r == '_':
+			return false
+		}
+		return true
+	} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return false
+	}
+	return unicode.IsSpace(r)
+}
+
 func simplePOS(pos string) string {
 	if strings.HasPrefix(pos, "V") {
 		return "v"
@@ -536,60 +910,49 @@ func simplePOS(pos string) string {
 	return strings.Split(pos, "-")[0]
 }
 
-func encode(corpus featureSet) *binaryMaxentClassifier {
-	mapping := make(map[string]int) // maps (fname-fval-label) -> fid
-	count := make(map[string]int)   // maps (fname, fval) -> count
-	weights := []float64{}
+// hashFeature maps a joint-feature onto one of `size` weights.
+func hashFeature(entry []byte, size int) int {
+	// FNV-1a, inlined to avoid allocating a hash.Hash32 per feature.
+	h := uint32(2166136261)
+	for _, c := range entry {
+		h ^= uint32(c)
+		h *= 16777619
+	}
+	return int(h % uint32(size))
+}
 
+// corpusLabels returns the labels of `corpus`, in order of appearance.
+func corpusLabels(corpus featureSet) []string {
 	labels := []string{}
 	for _, entry := range corpus {
-		label := entry.label
-		if !stringInSlice(label, labels) {
-			labels = append(labels, label)
+		if !stringInSlice(entry.label, labels) {
+			labels = append(labels, entry.label)
 		}
+	}
+	return labels
+}
+
+// encode creates a classifier with the joint-features of `corpus`, whose
+// labels are `labels`, restricted by `templates` (see labelTemplates).
+func encode(corpus featureSet, labels []string, templates map[string][]int) *binaryMaxentClassifier {
+	mapping := make(map[string]int) // maps (fname-fval-label) -> fid
+	weights := []float64{}
+	encoding := &binaryMaxentClassifier{templates: templates}
 
-		for i, fname := range featureOrder {
+	for _, entry := range corpus {
+		label := entry.label
+		for _, i := range encoding.features(label) {
 			fval := entry.features[i]
-			key := strings.Join([]string{fname, fval}, "-")
-			count[key]++
-			entry := strings.Join([]string{fname, fval, label}, "-")
+			if absentFeature(i, fval) {
+				continue
+			}
+			entry := strings.Join([]string{featureOrder[i], fval, label}, "-")
 			if _, found := mapping[entry]; !found {
 				mapping[entry] = len(mapping)
 			}
-
 		}
 	}
-	return newMaxentClassifier(weights, mapping, labels)
-}
-
-func empiricalCount(corpus featureSet, encoding *binaryMaxentClassifier) *mat.VecDense {
-	count := mat.NewVecDense(len(encoding.mapping)+1, nil)
-	for _, entry := range corpus {
-		for _, encoded := range encoding.encodeGIS(entry.features, entry.label) {
-			idx := encoded.key
-			count.SetVec(idx, count.AtVec(idx)+float64(encoded.value))
-		}
-	}
-	return count
-}
-
-func addLogs(x, y float64) float64 {
-	if x < y+maxLogDiff {
-		return y
-	} else if y < x+maxLogDiff {
-		return x
-	}
-	base := math.Min(x, y)
-	return base + math.Log2(math.Pow(2, x-base)+math.Pow(2, y-base))
-}
-
-func sumLogs(logs []float64) float64 {
-	if len(logs) == 0 {
-		return math.Inf(-1)
-	}
-	sum := logs[0]
-	for _, log := range logs[1:] {
-		sum = addLogs(sum, log)
-	}
-	return sum
+	encoding = newMaxentClassifier(weights, mapping, labels)
+	encoding.templates = templates
+	return encoding
 }
@@ -3,11 +3,16 @@ package prose
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"hash/fnv"
 	"io"
 	"io/ioutil"
-	"math"
+	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -60,13 +65,6 @@ func split(data []prodigyOuput) ([]EntityContext, []prodigyOuput) {
 	return train, test
 }
 
-func TestSumLogs(t *testing.T) {
-	s := sumLogs([]float64{math.Log2(3), math.Log2(5)})
-	if s != 3.0 {
-		t.Errorf("sumLogs() expected = %v, got = %v", 3.0, s)
-	}
-}
-
 func TestNERProdigy(t *testing.T) {
 	data := filepath.Join(testdata, "reddit_product.jsonl")
 
@@ -101,3 +99,253 @@ func TestNERProdigy(t *testing.T) {
 		t.Errorf("NERProdigy() expected >= 0.819444, got = %v", r)
 	}
 }
+
+func TestNERHashed(t *testing.T) {
+	data := filepath.Join(testdata, "reddit_product.jsonl")
+
+	file, e := ioutil.ReadFile(data)
+	require.NoError(t, e)
+
+	train, _ := split(readProdigy(file))
+	train = train[:300]
+
+	model, err := ModelFromData("PRODUCT",
+		UsingEntitiesWithOptions(train, TrainingOptions{HashSize: 1 << 12}))
+	require.NoError(t, err)
+	require.Equal(t, 1<<12, model.extracter.model.hashSize)
+	require.Len(t, model.extracter.model.weights, 1<<12+1)
+
+	doc, err := makeNER("Windows 10 is an operating system", model)
+	require.NoError(t, err)
+	for _, tok := range doc.Tokens() {
+		require.NotEmpty(t, tok.Label)
+	}
+
+	loaded := newMaxentClassifier(
+		model.extracter.model.weights,
+		model.extracter.model.mapping,
+		model.extracter.model.labels)
+	require.Equal(t, 1<<12, loaded.hashSize)
+}
+
+func TestNERIterations(t *testing.T) {
+	data := filepath.Join(testdata, "reddit_product.jsonl")
+
+	file, e := ioutil.ReadFile(data)
+	require.NoError(t, e)
+
+	train, _ := split(readProdigy(file))
+	train = train[:50]
+
+	weights := func(opts TrainingOptions) []float64 {
+		model, err := ModelFromData("PRODUCT", UsingEntitiesWithOptions(train, opts))
+		require.NoError(t, err)
+		return model.extracter.model.weights
+	}
+
+	one := weights(TrainingOptions{Iterations: 1})
+	require.NotEqual(t, one, weights(TrainingOptions{Iterations: 5}))
+
+	// A loose tolerance stops training after the first iteration.
+	require.Equal(t, one, weights(TrainingOptions{Iterations: 5, Tolerance: 1e9}))
+}
+
+func TestNERCheckpoint(t *testing.T) {
+	data := filepath.Join(testdata, "reddit_product.jsonl")
+
+	file, e := ioutil.ReadFile(data)
+	require.NoError(t, e)
+
+	train, _ := split(readProdigy(file))
+	train = train[:50]
+
+	full, err := ModelFromData("PRODUCT",
+		UsingEntitiesWithOptions(train, TrainingOptions{Iterations: 6}))
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "checkpoint")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	dir = filepath.Join(dir, "PRODUCT")
+
+	// Interrupt the training after the second checkpoint.
+	save := CheckpointToDisk(dir)
+	opts := TrainingOptions{
+		Iterations:      6,
+		CheckpointEvery: 2,
+		Checkpoint: func(checkpoint *Model, iteration int) error {
+			if err := save(checkpoint, iteration); err != nil {
+				return err
+			}
+			if iteration == 4 {
+				return errors.New("interrupted")
+			}
+			return nil
+		},
+	}
+	_, err = ModelFromData("PRODUCT", UsingEntitiesWithOptions(train, opts))
+	require.Error(t, err)
+
+	opts = TrainingOptions{Iterations: 6}
+	require.NoError(t, ResumeFromDisk(dir, &opts))
+	require.Equal(t, 4, opts.ResumeIteration)
+	resumed, err := ModelFromData("PRODUCT", UsingEntitiesWithOptions(train, opts))
+	require.NoError(t, err)
+	require.Equal(t, full.extracter.model.weights, resumed.extracter.model.weights)
+
+	// Without a checkpoint, training starts from scratch.
+	opts = TrainingOptions{}
+	require.NoError(t, ResumeFromDisk(filepath.Join(dir, "missing"), &opts))
+	require.Nil(t, opts.Resume)
+}
+
+func TestNERConcurrent(t *testing.T) {
+	model, err := defaultModel(true, true)
+	require.NoError(t, err)
+
+	text := "Windows 10 was released by Microsoft in Redmond, Washington, " +
+		"and Satya Nadella announced it in New York."
+	labels := func() []string {
+		doc, err := makeNER(text, model)
+		require.NoError(t, err)
+		labels := []string{}
+		for _, tok := range doc.Tokens() {
+			labels = append(labels, tok.Label)
+		}
+		return labels
+	}
+	serial := labels()
+
+	var wg sync.WaitGroup
+	results := make([][]string, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				results[i] = labels()
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range results {
+		require.Equal(t, serial, result)
+	}
+}
+
+func TestNERWarmStart(t *testing.T) {
+	data := filepath.Join(testdata, "reddit_product.jsonl")
+
+	file, e := ioutil.ReadFile(data)
+	require.NoError(t, e)
+
+	train, _ := split(readProdigy(file))
+	train = train[:50]
+
+	model, err := ModelFromData("PRODUCT",
+		UsingEntitiesWithOptions(train, TrainingOptions{WarmStart: true}))
+	require.NoError(t, err)
+	require.Contains(t, model.extracter.model.labels, "B-PERSON")
+	require.Contains(t, model.extracter.model.labels, "B-PRODUCT")
+
+	// Features absent from the new data keep the embedded model's weights.
+	base, err := newEntityExtracter()
+	require.NoError(t, err)
+	for _, entry := range []string{"bias-True-B-PERSON", "pos-NNP-B-PERSON"} {
+		idx, found := base.model.mapping[entry]
+		require.True(t, found, entry)
+		require.Equal(t,
+			base.model.weights[idx],
+			model.extracter.model.weights[model.extracter.model.mapping[entry]])
+	}
+}
+
+func TestNERNegativeRate(t *testing.T) {
+	tagger, err := NewPerceptronTagger()
+	require.NoError(t, err)
+
+	data := []EntityContext{{
+		Accept: true,
+		Text:   "Jane Doe left.",
+		Spans:  []LabeledEntity{{Start: 0, End: 8, Label: "PERSON"}}}}
+	for i := 0; i < 10; i++ {
+		data = append(data, EntityContext{Accept: true, Text: "Nobody left early."})
+	}
+
+	full := makeCorpus(data, tagger, NewIterTokenizer(), TrainingOptions{})
+	require.Len(t, full, 11*4)
+
+	sampled := makeCorpus(data, tagger, NewIterTokenizer(), TrainingOptions{NegativeRate: 0.3})
+	require.Len(t, sampled, 4*4)
+	require.Equal(t, "B-PERSON", sampled[0].label)
+}
+
+func TestNERDebugFeatures(t *testing.T) {
+	tagger, err := NewPerceptronTagger()
+	require.NoError(t, err)
+
+	data := []EntityContext{{
+		Accept: true,
+		Text:   "Jane Doe left.",
+		Spans:  []LabeledEntity{{Start: 0, End: 8, Label: "PERSON"}}}}
+
+	var buf bytes.Buffer
+	makeCorpus(data, tagger, NewIterTokenizer(), TrainingOptions{Debug: &buf, DebugEvery: 2})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "B-PERSON\tbias=True\t"))
+	require.Contains(t, lines[0], "\tword=Jane\t")
+	require.True(t, strings.HasPrefix(lines[1], "O\t"))
+	require.Contains(t, lines[1], "\tword=left\t")
+}
+
+func BenchmarkClassify(b *testing.B) {
+	model, err := defaultModel(true, true)
+	require.NoError(b, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(testdata, "sherlock.txt"))
+	require.NoError(b, err)
+	doc, err := NewDocument(string(content[:20000]), WithExtraction(false))
+	require.NoError(b, err)
+	tokens := doc.tokens
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		model.extracter.classify(tokens, nil, false, nil)
+	}
+}
+
+func TestShapeHelpers(t *testing.T) {
+	for _, word := range []string{
+		"Apple", "apple", "APPLE", "McDonald", "o'Neil", "O'Neil", "Jean-Luc",
+		"jean-Luc", "Émile", "émile", "ǆungla", "Ǆungla", "A_b", "X1y", "",
+	} {
+		require.Equal(t, strings.Title(word) == word, isTitled(word), word)
+	}
+
+	for _, word := range []string{
+		"1", "-2.5", "1e3", "0x1p-2", "1_000", "Inf", "-infinity", "NaN", "nan",
+		"apple", "in", "na", "+", "", "--inf",
+	} {
+		_, err := strconv.ParseFloat(word, 64)
+		require.Equal(t, err == nil, isNumeric(word), word)
+	}
+
+	for _, entry := range []string{"", "a", "bias-True-PERSON", "wordlower-apple-O"} {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(entry))
+		require.Equal(t, int(h.Sum32()%1000003), hashFeature([]byte(entry), 1000003), entry)
+	}
+}
+
+func TestEncodeEmptyFeatures(t *testing.T) {
+	// Models trained before optional features keep joint-features for
+	// empty core values (e.g., "nextword--O" at the end of a sentence).
+	model := newMaxentClassifier([]float64{0.5, 0.25}, map[string]int{
+		"nextword--O": 0, "block--O": 1}, []string{"O"})
+	features := [numFeatures]string{}
+	encoded := model.encode(features, "O")
+	require.Equal(t, []encodedValue{{key: 0, value: 1}}, encoded)
+}
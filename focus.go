@@ -0,0 +1,88 @@
+package prose
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A Span is a range of a Document's Text, from Start (inclusive) to End
+// (exclusive).
+type Span struct {
+	Start int
+	End   int
+}
+
+// WithFocusSpans restricts POS tagging and named-entity extraction to the
+// tokens that overlap `spans` (ranges of the Document's Text), such as the
+// clauses of a long contract that a caller cares about. The whole text is
+// still segmented and tokenized; the other tokens have no Tag or Label.
+//
+// Each contiguous run of focused tokens is processed independently, so
+// spans that cover whole sentences give the best results.
+func WithFocusSpans(spans []Span) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Focus = spans
+	}
+}
+
+func validateSpans(spans []Span) error {
+	for _, span := range spans {
+		if span.Start < 0 || span.End < span.Start {
+			return fmt.Errorf("%w [%d, %d)", ErrInvalidSpan, span.Start, span.End)
+		}
+	}
+	return nil
+}
+
+// mergeSpans returns `spans` sorted, with overlapping and adjacent spans
+// merged.
+func mergeSpans(spans []Span) []Span {
+	sorted := append([]Span{}, spans...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+	merged := []Span{}
+	for _, span := range sorted {
+		if n := len(merged); n > 0 && span.Start <= merged[n-1].End {
+			if span.End > merged[n-1].End {
+				merged[n-1].End = span.End
+			}
+		} else {
+			merged = append(merged, span)
+		}
+	}
+	return merged
+}
+
+// focusTokens splits each section's tokens (and their block contexts, if
+// any) into the runs of tokens that overlap `spans`.
+func focusTokens(sections [][]*Token, contexts [][]string, spans []Span) ([][]*Token, [][]string) {
+	spans = mergeSpans(spans)
+	runs, runContexts := [][]*Token{}, [][]string{}
+	s := 0
+	for i, tokens := range sections {
+		start := -1
+		for j := 0; j <= len(tokens); j++ {
+			focused := false
+			if j < len(tokens) {
+				tok := tokens[j]
+				for s < len(spans) && spans[s].End <= tok.Start {
+					s++
+				}
+				focused = s < len(spans) && spans[s].Start < tok.End
+			}
+			if focused && start < 0 {
+				start = j
+			} else if !focused && start >= 0 {
+				runs = append(runs, tokens[start:j])
+				if contexts[i] != nil {
+					runContexts = append(runContexts, contexts[i][start:j])
+				} else {
+					runContexts = append(runContexts, nil)
+				}
+				start = -1
+			}
+		}
+	}
+	return runs, runContexts
+}
@@ -0,0 +1,43 @@
+package prose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFocusSpans(t *testing.T) {
+	first := "Jane Smith lives in Paris."
+	text := first + " John Doe moved to London."
+	second := strings.Index(text, "John")
+
+	all, err := NewDocument(text)
+	require.NoError(t, err)
+	focused, err := NewDocument(text, WithFocusSpans([]Span{{Start: second, End: len(text)}}))
+	require.NoError(t, err)
+
+	assert.Equal(t, all.Sentences(), focused.Sentences())
+	require.Equal(t, len(all.Tokens()), len(focused.Tokens()))
+	for _, tok := range focused.Tokens() {
+		if tok.End <= second {
+			assert.Empty(t, tok.Tag)
+			assert.Empty(t, tok.Label)
+		} else {
+			assert.NotEmpty(t, tok.Tag)
+		}
+	}
+	require.NotEmpty(t, focused.Entities())
+	for _, ent := range focused.Entities() {
+		assert.GreaterOrEqual(t, ent.Start, second)
+	}
+
+	_, err = NewDocument(text, WithFocusSpans([]Span{{Start: 5, End: 2}}))
+	assert.Error(t, err)
+}
+
+func TestMergeSpans(t *testing.T) {
+	spans := mergeSpans([]Span{{10, 12}, {0, 3}, {2, 5}, {12, 14}})
+	assert.Equal(t, []Span{{0, 5}, {10, 14}}, spans)
+}
@@ -0,0 +1,91 @@
+package prose
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RareZipf is the Zipf score below which FrequencyTable.IsRare considers a
+// word rare: about one occurrence per million words. It's only meaningful
+// for tables counted from a large corpus (tens of millions of words or more).
+const RareZipf = 3.0
+
+// A FrequencyTable records how often words occur in a reference corpus.
+// Words are case-insensitive.
+//
+// prose doesn't bundle an English table (nor package-level ZipfScore and
+// IsRareWord helpers over one): Zipf scores are only reliable when counted
+// from a corpus far larger than the package could reasonably embed, so
+// callers supply their own, e.g., with ReadFrequencyTable.
+type FrequencyTable struct {
+	counts map[string]int
+	total  int
+}
+
+// NewFrequencyTable creates a FrequencyTable from the number of times each
+// word occurs in a corpus of `total` words (e.g., from CorpusStats); if
+// `total` is zero, it's the sum of `counts`.
+func NewFrequencyTable(counts map[string]int, total int) *FrequencyTable {
+	ft := &FrequencyTable{counts: make(map[string]int, len(counts)), total: total}
+	sum := 0
+	for word, count := range counts {
+		ft.counts[strings.ToLower(word)] += count
+		sum += count
+	}
+	if ft.total == 0 {
+		ft.total = sum
+	}
+	return ft
+}
+
+// ReadFrequencyTable reads a FrequencyTable from `r`, which holds one
+// "word count" pair per line. Blank lines and lines starting with "#" are
+// ignored.
+func ReadFrequencyTable(r io.Reader) (*FrequencyTable, error) {
+	counts := map[string]int{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("unable to read frequencies: line %d: expected \"word count\"", line)
+		}
+		count, err := strconv.Atoi(fields[1])
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("unable to read frequencies: line %d: invalid count %q", line, fields[1])
+		}
+		counts[fields[0]] += count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read frequencies: %w", err)
+	}
+	return NewFrequencyTable(counts, 0), nil
+}
+
+// Count returns the number of times `word` occurs in the corpus.
+func (ft *FrequencyTable) Count(word string) int {
+	return ft.counts[strings.ToLower(word)]
+}
+
+// Zipf returns the Zipf score of `word`: the base-10 logarithm of its
+// frequency per billion words, from about 1 (very rare) to 7 or more ("the").
+// Words that don't occur in the corpus score zero.
+func (ft *FrequencyTable) Zipf(word string) float64 {
+	count := ft.Count(word)
+	if count == 0 || ft.total == 0 {
+		return 0
+	}
+	return math.Log10(float64(count) / float64(ft.total) * 1e9)
+}
+
+// IsRare determines if `word`'s Zipf score is below RareZipf.
+func (ft *FrequencyTable) IsRare(word string) bool {
+	return ft.Zipf(word) < RareZipf
+}
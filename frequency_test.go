@@ -0,0 +1,32 @@
+package prose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrequencyTable(t *testing.T) {
+	ft, err := ReadFrequencyTable(strings.NewReader("# counts\nthe 990\n\nContract 9\nclause 1\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 9, ft.Count("contract"))
+	assert.InDelta(t, 9.0, ft.Zipf("the"), 0.01)
+	assert.InDelta(t, 6.0, ft.Zipf("clause"), 0.01)
+	assert.False(t, ft.IsRare("clause"))
+	assert.True(t, ft.IsRare("indemnity"))
+
+	_, err = ReadFrequencyTable(strings.NewReader("the\n"))
+	assert.Error(t, err)
+	_, err = ReadFrequencyTable(strings.NewReader("the many\n"))
+	assert.Error(t, err)
+
+	stats := NewCorpusStats(UsingLowercase())
+	doc, err := NewDocument("The buyer pays. The seller ships.", WithExtraction(false))
+	require.NoError(t, err)
+	stats.Add(doc)
+	ft = NewFrequencyTable(stats.Vocabulary(), stats.Tokens)
+	assert.Equal(t, 2, ft.Count("the"))
+	assert.InDelta(t, 8.4, ft.Zipf("the"), 0.01)
+}
@@ -0,0 +1,133 @@
+package prose
+
+import (
+	"sort"
+	"strings"
+)
+
+// A Gazetteer holds known entities, such as a list of customers or
+// products, whose occurrences NewDocument labels as-is (see WithGazetteer)
+// instead of leaving them to the models.
+type Gazetteer struct {
+	opts    GazetteerOptions
+	entries map[string][]gazetteerEntry // By first token, longest first.
+}
+
+// GazetteerOptions controls how a Gazetteer matches text.
+type GazetteerOptions struct {
+	// IgnoreCase, if true, matches entries regardless of case.
+	IgnoreCase bool
+
+	// ProperNouns, if true, also tags the tokens of matches as proper nouns
+	// (NNP), which informs the tags of the tokens around them.
+	ProperNouns bool
+}
+
+type gazetteerEntry struct {
+	tokens []string
+	label  string
+}
+
+// NewGazetteer creates an empty Gazetteer.
+func NewGazetteer(opts GazetteerOptions) *Gazetteer {
+	return &Gazetteer{opts: opts, entries: make(map[string][]gazetteerEntry)}
+}
+
+// Add adds the entity `phrase` (e.g., "Acme Corp"), labeled `label` (e.g.,
+// "ORG"). Phrases are tokenized by NewIterTokenizer, and match the same
+// tokens in the text.
+func (g *Gazetteer) Add(phrase, label string) {
+	tokens := []string{}
+	for _, tok := range NewIterTokenizer().Tokenize(phrase) {
+		tokens = append(tokens, g.key(tok.Text))
+	}
+	if len(tokens) == 0 {
+		return
+	}
+	entries := append(g.entries[tokens[0]], gazetteerEntry{tokens: tokens, label: label})
+	sort.SliceStable(entries, func(i, j int) bool {
+		return len(entries[i].tokens) > len(entries[j].tokens)
+	})
+	g.entries[tokens[0]] = entries
+}
+
+func (g *Gazetteer) key(text string) string {
+	if g.opts.IgnoreCase {
+		return strings.ToLower(text)
+	}
+	return text
+}
+
+// match returns the longest entry matching `tokens` from their start, if
+// any.
+func (g *Gazetteer) match(tokens []*Token) (gazetteerEntry, bool) {
+	for _, entry := range g.entries[g.key(tokens[0].Text)] {
+		if len(entry.tokens) > len(tokens) {
+			continue
+		}
+		found := true
+		for i, text := range entry.tokens[1:] {
+			found = found && g.key(tokens[i+1].Text) == text
+		}
+		if found {
+			return entry, true
+		}
+	}
+	return gazetteerEntry{}, false
+}
+
+// WithGazetteer labels the occurrences of the entities in `g` before
+// tagging and classifying the text, when extracting entities; the models
+// respect the labels (see UsingTokens), and no entity the NER finds
+// overlaps them. Tokens that already have a label (from UsingTokens) are
+// left alone. Documents created with it aren't cached, since the gazetteer
+// may change.
+func WithGazetteer(g *Gazetteer) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Gazetteer = g
+	}
+}
+
+// labelMatches labels the leftmost-longest, non-overlapping matches of `g`
+// in `tokens`, returning the index of the token following each.
+func labelMatches(g *Gazetteer, tokens []*Token) []int {
+	ends := []int{}
+	for i := 0; i < len(tokens); i++ {
+		entry, found := g.match(tokens[i:])
+		if !found || !unlabeled(tokens[i:i+len(entry.tokens)]) {
+			continue
+		}
+		for j := range entry.tokens {
+			tok := tokens[i+j]
+			tok.Label = "I-" + entry.label
+			if j == 0 {
+				tok.Label = "B-" + entry.label
+			}
+			if g.opts.ProperNouns && tok.Tag == "" {
+				tok.Tag = "NNP"
+			}
+		}
+		i += len(entry.tokens) - 1
+		ends = append(ends, i+1)
+	}
+	return ends
+}
+
+func unlabeled(tokens []*Token) bool {
+	for _, tok := range tokens {
+		if tok.Label != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// closeMatches ensures that the NER didn't extend the matches ending before
+// each of `ends` (see labelMatches) into the tokens that follow.
+func closeMatches(tokens []*Token, ends []int) {
+	for _, end := range ends {
+		if end < len(tokens) && strings.HasPrefix(tokens[end].Label, "I-") {
+			tokens[end].Label = "B-" + tokens[end].Label[2:]
+		}
+	}
+}
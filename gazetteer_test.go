@@ -0,0 +1,62 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGazetteer(t *testing.T) {
+	text := "Yesterday, the apple pie company signed with acme widgets in Boston."
+	g := NewGazetteer(GazetteerOptions{IgnoreCase: true, ProperNouns: true})
+	g.Add("Acme Widgets", "ORG")
+	g.Add("Acme", "PRODUCT")
+	g.Add("apple pie company", "ORG")
+
+	entities := func(doc *Document) map[string]string {
+		labels := map[string]string{}
+		for _, ent := range doc.Entities() {
+			labels[ent.Text] = ent.Label
+		}
+		return labels
+	}
+
+	doc, err := NewDocument(text)
+	require.NoError(t, err)
+	assert.NotContains(t, entities(doc), "acme widgets")
+
+	doc, err = NewDocument(text, WithGazetteer(g))
+	require.NoError(t, err)
+	labels := entities(doc)
+	assert.Equal(t, "ORG", labels["acme widgets"], "the longest match wins")
+	assert.Equal(t, "ORG", labels["apple pie company"])
+	assert.Equal(t, "GPE", labels["Boston"], "the NER labels the rest")
+	tokens := doc.Tokens()
+	assert.Equal(t, "NNP", tokens[3].Tag)
+	assert.Equal(t, "B-ORG", tokens[3].Label)
+
+	// Matches are case-sensitive by default, and don't change tags unless
+	// asked to.
+	strict := NewGazetteer(GazetteerOptions{})
+	strict.Add("Acme Widgets", "ORG")
+	doc, err = NewDocument(text, WithGazetteer(strict))
+	require.NoError(t, err)
+	assert.NotContains(t, entities(doc), "acme widgets")
+	doc, err = NewDocument("He joined Acme Widgets.", WithGazetteer(strict))
+	require.NoError(t, err)
+	assert.Equal(t, "ORG", entities(doc)["Acme Widgets"])
+	assert.NotEqual(t, "", doc.Tokens()[2].Tag)
+
+	// Pre-labeled tokens take precedence.
+	doc, err = NewDocument("", WithGazetteer(strict), UsingTokens([]Token{
+		{Text: "Acme", Label: "O"}, {Text: "Widgets", Label: "O"}}))
+	require.NoError(t, err)
+	assert.Empty(t, doc.Entities())
+
+	// The NER can't extend a match.
+	tokens2 := []*Token{{Text: "Acme", Label: "B-ORG"}, {Text: "Widgets", Label: "I-ORG"},
+		{Text: "Inc", Label: "I-ORG"}}
+	closeMatches(tokens2, []int{2})
+	assert.Equal(t, "B-ORG", tokens2[2].Label)
+}
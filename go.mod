@@ -4,7 +4,7 @@ go 1.16
 
 require (
 	github.com/neurosnap/sentences v1.0.6 // indirect
-	github.com/stretchr/testify v1.8.1 // indirect
-	gonum.org/v1/gonum v0.7.0
+	github.com/stretchr/testify v1.8.1
+	golang.org/x/text v0.13.0
 	gopkg.in/neurosnap/sentences.v1 v1.0.6
 )
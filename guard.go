@@ -0,0 +1,108 @@
+package prose
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// An InputPolicy determines how NewDocument handles sentences that contain
+// invalid UTF-8 or that appear to be written in an unsupported language.
+type InputPolicy int
+
+const (
+	// PolicyIgnore processes all input as-is (the default).
+	PolicyIgnore InputPolicy = iota
+	// PolicyReplace replaces invalid UTF-8 with U+FFFD; unsupported
+	// sentences are kept.
+	PolicyReplace
+	// PolicySkip removes offending sentences from the Document's text.
+	PolicySkip
+	// PolicyError makes NewDocument fail on the first offending sentence.
+	PolicyError
+)
+
+// WithInputGuard checks each sentence for invalid UTF-8 and for text that
+// is mostly written in a non-Latin script, handling offenders according to
+// `policy`.
+//
+// When the guard changes the input, Document.Text holds the text that was
+// actually processed.
+func WithInputGuard(policy InputPolicy) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Guard = policy
+	}
+}
+
+// guardText applies `policy` to `text`, returning the text to process.
+func guardText(text string, policy InputPolicy) (string, error) {
+	if policy == PolicyIgnore {
+		return text, nil
+	}
+	if policy == PolicyReplace {
+		return strings.ToValidUTF8(text, "\uFFFD"), nil
+	}
+
+	segmenter, err := NewPunktSentenceTokenizer()
+	if err != nil {
+		return "", fmt.Errorf("unable to create punkt segmenter: %w", err)
+	}
+
+	var kept strings.Builder
+	cursor := 0
+	for i, sent := range segmenter.Segment(text) {
+		start := strings.Index(text[cursor:], sent.Text)
+		if start < 0 {
+			continue
+		}
+		start += cursor
+		end := start + len(sent.Text)
+
+		var problem error
+		if !utf8.ValidString(sent.Text) {
+			problem = ErrInvalidUTF8
+		} else if latinShare(sent.Text) < minLatinShare {
+			problem = ErrUnsupportedLanguage
+		}
+
+		if problem == nil {
+			kept.WriteString(text[cursor:end])
+		} else if policy == PolicyError {
+			return "", fmt.Errorf("sentence %d: %w", i, problem)
+		} else {
+			kept.WriteString(text[cursor:start])
+		}
+		cursor = end
+	}
+	kept.WriteString(text[cursor:])
+
+	return kept.String(), nil
+}
+
+// minLatinShare is the share of letters written in the Latin script below
+// which text is considered to be in a language that the bundled English
+// models don't support.
+const minLatinShare = 0.5
+
+// latinShare returns the share of the letters in `text` that are written in
+// the Latin script, or 1 if it has no letters. This is a script check, not
+// language detection: it tells English from Russian or Chinese, but not from
+// French or German.
+func latinShare(text string) float64 {
+	latin, other := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+		} else {
+			other++
+		}
+	}
+	if latin+other == 0 {
+		return 1
+	}
+	return float64(latin) / float64(latin+other)
+}
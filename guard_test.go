@@ -0,0 +1,32 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputGuard(t *testing.T) {
+	text := "This is fine. Это предложение на русском. Bad \xff byte here. The end."
+
+	doc, err := NewDocument(text, WithInputGuard(PolicyIgnore))
+	require.NoError(t, err)
+	assert.Equal(t, text, doc.Text)
+
+	doc, err = NewDocument(text, WithInputGuard(PolicyReplace))
+	require.NoError(t, err)
+	assert.Contains(t, doc.Text, "Bad � byte here.")
+	assert.Contains(t, doc.Text, "русском")
+
+	doc, err = NewDocument(text, WithInputGuard(PolicySkip))
+	require.NoError(t, err)
+	sents := []string{}
+	for _, sent := range doc.Sentences() {
+		sents = append(sents, sent.Text)
+	}
+	assert.Equal(t, []string{"This is fine.", "The end."}, sents)
+
+	_, err = NewDocument(text, WithInputGuard(PolicyError))
+	assert.Error(t, err)
+}
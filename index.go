@@ -0,0 +1,155 @@
+package prose
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// An Index is an in-memory inverted index over the tokens of Documents,
+// supporting term, phrase, and proximity queries. It's safe for concurrent
+// use.
+type Index struct {
+	mu       sync.RWMutex
+	term     func(Token) string
+	tokens   Tokenizer
+	postings map[string][]Posting
+	docs     int
+}
+
+// A Posting is an occurrence of a term: the Document's ID (as returned by
+// Index.Add) and the position of the token within Document.Tokens.
+type Posting struct {
+	Doc      int
+	Position int
+}
+
+// IndexOptFunc configures an Index.
+type IndexOptFunc func(*Index)
+
+// UsingTermFunc sets the function that maps tokens to the terms they're
+// indexed (and queried) by; the default is the token's lowercased text.
+//
+// For example, a lemmatizer or stemmer lets queries match inflected forms.
+// Query tokens have no Tag or Label.
+func UsingTermFunc(term func(Token) string) IndexOptFunc {
+	return func(idx *Index) {
+		idx.term = term
+	}
+}
+
+// NewIndex returns an empty Index.
+func NewIndex(opts ...IndexOptFunc) *Index {
+	idx := &Index{
+		term:     func(tok Token) string { return strings.ToLower(tok.Text) },
+		tokens:   NewIterTokenizer(),
+		postings: map[string][]Posting{},
+	}
+	for _, applyOpt := range opts {
+		applyOpt(idx)
+	}
+	return idx
+}
+
+// Add indexes the tokens of `doc`, returning its ID.
+func (idx *Index) Add(doc *Document) int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	id := idx.docs
+	for i, tok := range doc.tokens {
+		if term := idx.term(*tok); term != "" {
+			idx.postings[term] = append(idx.postings[term], Posting{Doc: id, Position: i})
+		}
+	}
+	idx.docs++
+	return id
+}
+
+// Len returns the number of Documents in `idx`.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.docs
+}
+
+// Lookup returns the occurrences of the word `word`.
+func (idx *Index) Lookup(word string) []Posting {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return append([]Posting{}, idx.postings[idx.term(Token{Text: word})]...)
+}
+
+// Phrase returns the occurrences of `phrase`, at the position of its first
+// token.
+func (idx *Index) Phrase(phrase string) []Posting {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matches := []Posting{}
+	terms := idx.terms(phrase)
+	if len(terms) == 0 {
+		return matches
+	}
+	for _, start := range idx.postings[terms[0]] {
+		found := true
+		for offset, term := range terms[1:] {
+			want := Posting{Doc: start.Doc, Position: start.Position + offset + 1}
+			if !idx.contains(term, want) {
+				found = false
+				break
+			}
+		}
+		if found {
+			matches = append(matches, start)
+		}
+	}
+	return matches
+}
+
+// Near returns the occurrences of the word `a` that have an occurrence of
+// the word `b` within `distance` tokens of them, in either direction.
+func (idx *Index) Near(a, b string, distance int) []Posting {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matches := []Posting{}
+	others := idx.postings[idx.term(Token{Text: b})]
+	for _, p := range idx.postings[idx.term(Token{Text: a})] {
+		// Postings are sorted by (Doc, Position), so we can search for the
+		// first candidate.
+		lo := sort.Search(len(others), func(i int) bool {
+			o := others[i]
+			return o.Doc > p.Doc || (o.Doc == p.Doc && o.Position >= p.Position-distance)
+		})
+		for i := lo; i < len(others) && others[i].Doc == p.Doc; i++ {
+			if others[i].Position > p.Position+distance {
+				break
+			} else if others[i].Position != p.Position {
+				matches = append(matches, p)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// terms returns the terms of the tokens of `text`.
+func (idx *Index) terms(text string) []string {
+	tokens := idx.tokens.Tokenize(text)
+	terms := make([]string, len(tokens))
+	for i, tok := range tokens {
+		terms[i] = idx.term(*tok)
+	}
+	return terms
+}
+
+// contains determines if `want` is a posting of `term`.
+func (idx *Index) contains(term string, want Posting) bool {
+	postings := idx.postings[term]
+	i := sort.Search(len(postings), func(i int) bool {
+		p := postings[i]
+		return p.Doc > want.Doc || (p.Doc == want.Doc && p.Position >= want.Position)
+	})
+	return i < len(postings) && postings[i] == want
+}
@@ -0,0 +1,50 @@
+package prose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex(t *testing.T) {
+	idx := NewIndex()
+	for _, text := range []string{
+		"The Supplier shall deliver the Goods.",
+		"The Buyer may reject the goods within ten days.",
+	} {
+		doc, err := NewDocument(text, WithExtraction(false), WithTagging(false))
+		require.NoError(t, err)
+		idx.Add(doc)
+	}
+	assert.Equal(t, 2, idx.Len())
+
+	assert.Equal(t, []Posting{{Doc: 0, Position: 5}, {Doc: 1, Position: 5}}, idx.Lookup("Goods"))
+	assert.Equal(t, []Posting{{Doc: 1, Position: 4}}, idx.Phrase("the goods within"))
+	assert.Empty(t, idx.Phrase("goods the"))
+	assert.Empty(t, idx.Phrase(""))
+
+	assert.Equal(t, []Posting{{Doc: 1, Position: 3}}, idx.Near("reject", "days", 5))
+	assert.Empty(t, idx.Near("reject", "days", 3))
+	assert.Empty(t, idx.Near("supplier", "days", 10))
+
+	stemmed := NewIndex(UsingTermFunc(func(tok Token) string {
+		return stem(tok.Text)
+	}))
+	doc, err := NewDocument("Deliveries delayed.", WithExtraction(false), WithTagging(false))
+	require.NoError(t, err)
+	stemmed.Add(doc)
+	assert.Len(t, stemmed.Lookup("delivery"), 1)
+}
+
+// stem is a toy stemmer.
+func stem(word string) string {
+	word = strings.ToLower(word)
+	for _, suffix := range []string{"ies", "y", "ed", "s"} {
+		if strings.HasSuffix(word, suffix) {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
@@ -0,0 +1,197 @@
+// Package gis implements Generalized Iterative Scaling (GIS), the algorithm
+// used to train prose's maximum entropy classifiers: the named-entity
+// extracter and the general-purpose sequence labelers of package sequence.
+//
+// Weights (and so scores) are in log2 space.
+package gis
+
+import (
+	"math"
+)
+
+var maxLogDiff = math.Log2(1e-30)
+
+// A Feature is an active joint-feature: the index of its weight and its
+// value.
+type Feature struct {
+	Index int
+	Value float64
+}
+
+// A Corpus is a set of training instances, each of which is labeled with one
+// of a fixed set of labels.
+type Corpus interface {
+	// Len returns the number of instances.
+	Len() int
+	// Labels returns the number of labels.
+	Labels() int
+	// Label returns the index of the observed label of instance `i`, or -1
+	// if it isn't one of the labels.
+	Label(i int) int
+	// Encode returns the joint-features of instance `i` for label `j`,
+	// including the correction feature that brings their total up to the
+	// corpus's cardinality.
+	Encode(i, j int) []Feature
+}
+
+// Options controls training.
+type Options struct {
+	// Cardinality is the (constant) total feature count of every instance.
+	Cardinality int
+	// Start is the first iteration, which is non-zero when resuming.
+	Start int
+	// Iterations is the number of the iteration at which training stops.
+	Iterations int
+	// Tolerance stops training early once no weight changes by more than
+	// it in an iteration.
+	Tolerance float64
+	// Frozen returns the fixed weight of the unattested joint-feature
+	// `index` (i.e., one that doesn't occur in the training data). If nil,
+	// unattested features are left at their current weight.
+	Frozen func(index int) float64
+	// Before, if non-nil, is called before each iteration; training stops
+	// with its error, if any.
+	Before func(iteration int) error
+	// Progress, if non-nil, is called with the log-likelihood (in nats) of
+	// the corpus's labels at the start of each iteration.
+	Progress func(iteration int, logLikelihood float64)
+	// After, if non-nil, is called with the number of completed iterations
+	// after each one (except the last); training stops with its error, if
+	// any.
+	After func(done int) error
+}
+
+// Train adjusts `weights`, in place, to fit `corpus`.
+func Train(corpus Corpus, weights []float64, opts Options) error {
+	empirical := make([]float64, len(weights))
+	for i := 0; i < corpus.Len(); i++ {
+		if label := corpus.Label(i); label >= 0 {
+			for _, f := range corpus.Encode(i, label) {
+				empirical[f.Index] += f.Value
+			}
+		}
+	}
+
+	frozen := make(map[int]float64)
+	for index, count := range empirical {
+		if count == 0 {
+			if opts.Frozen != nil {
+				weights[index] = opts.Frozen(index)
+			}
+			frozen[index] = weights[index]
+		} else {
+			empirical[index] = math.Log2(count)
+		}
+	}
+
+	cInv := 1.0 / float64(opts.Cardinality)
+	for iteration := opts.Start; iteration < opts.Iterations; iteration++ {
+		if opts.Before != nil {
+			if err := opts.Before(iteration); err != nil {
+				return err
+			}
+		}
+
+		estimated, logLikelihood := expected(corpus, weights)
+		if opts.Progress != nil {
+			opts.Progress(iteration, logLikelihood)
+		}
+
+		delta := 0.0
+		for index := range weights {
+			if _, found := frozen[index]; found {
+				continue
+			}
+			step := cInv * (empirical[index] - math.Log2(estimated[index]))
+			weights[index] += step
+			delta = math.Max(delta, math.Abs(step))
+		}
+		if delta < opts.Tolerance {
+			break
+		}
+
+		done := iteration + 1
+		if opts.After != nil && done < opts.Iterations {
+			if err := opts.After(done); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expected returns the expected count of each joint-feature of `corpus`
+// under `weights`, and the log-likelihood (in nats) of the corpus's labels.
+func expected(corpus Corpus, weights []float64) ([]float64, float64) {
+	count := make([]float64, len(weights))
+	logLikelihood := 0.0
+
+	n := corpus.Labels()
+	encoded := make([][]Feature, n)
+	scores := make([]float64, n)
+	for i := 0; i < corpus.Len(); i++ {
+		for j := 0; j < n; j++ {
+			encoded[j] = corpus.Encode(i, j)
+			scores[j] = Score(weights, encoded[j])
+		}
+		Normalize(scores)
+		if label := corpus.Label(i); label >= 0 {
+			logLikelihood += scores[label] * math.Ln2
+		}
+		for j, features := range encoded {
+			prob := math.Pow(2, scores[j])
+			for _, f := range features {
+				count[f.Index] += prob * f.Value
+			}
+		}
+	}
+	return count, logLikelihood
+}
+
+// Score returns the (log2) score of `features` under `weights`.
+func Score(weights []float64, features []Feature) float64 {
+	total := 0.0
+	for _, f := range features {
+		total += weights[f.Index] * f.Value
+	}
+	return total
+}
+
+// Normalize turns the (log2) `scores` of each label into log2
+// probabilities, in place. If every score is -Inf, the labels are taken to
+// be equally likely.
+func Normalize(scores []float64) {
+	sum := SumLogs(scores)
+	if sum <= math.Inf(-1) {
+		p := math.Log2(1.0 / float64(len(scores)))
+		for i := range scores {
+			scores[i] = p
+		}
+		return
+	}
+	for i := range scores {
+		scores[i] -= sum
+	}
+}
+
+// SumLogs returns the log2 of the sum of the values whose log2 are `logs`.
+func SumLogs(logs []float64) float64 {
+	if len(logs) == 0 {
+		return math.Inf(-1)
+	}
+	sum := logs[0]
+	for _, log := range logs[1:] {
+		sum = addLogs(sum, log)
+	}
+	return sum
+}
+
+func addLogs(x, y float64) float64 {
+	if x < y+maxLogDiff {
+		return y
+	} else if y < x+maxLogDiff {
+		return x
+	}
+	base := math.Min(x, y)
+	return base + math.Log2(math.Pow(2, x-base)+math.Pow(2, y-base))
+}
@@ -0,0 +1,48 @@
+package gis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSumLogs(t *testing.T) {
+	assert.Equal(t, 3.0, SumLogs([]float64{math.Log2(3), math.Log2(5)}))
+	assert.True(t, math.IsInf(SumLogs(nil), -1))
+}
+
+// coinCorpus has two labels and a single (always active) feature per label;
+// label 0 is observed three times as often as label 1.
+type coinCorpus struct{}
+
+func (coinCorpus) Len() int    { return 4 }
+func (coinCorpus) Labels() int { return 2 }
+
+func (coinCorpus) Label(i int) int {
+	if i == 3 {
+		return 1
+	}
+	return 0
+}
+
+func (coinCorpus) Encode(i, j int) []Feature {
+	return []Feature{{Index: j, Value: 1}}
+}
+
+func TestTrain(t *testing.T) {
+	weights := make([]float64, 2)
+	iterations := 0
+	err := Train(coinCorpus{}, weights, Options{
+		Cardinality: 1,
+		Iterations:  10,
+		Progress:    func(int, float64) { iterations++ },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 10, iterations)
+
+	scores := []float64{weights[0], weights[1]}
+	Normalize(scores)
+	assert.InDelta(t, 0.75, math.Exp2(scores[0]), 1e-9)
+}
@@ -0,0 +1,47 @@
+package prose
+
+import "strings"
+
+// IOB returns the IOB label ("B-<label>", "I-<label>", or "O") of each of
+// `doc`'s tokens, grouped by sentence (or in a single group, without
+// segmentation).
+//
+// The labels are derived from Entities, so they always form a valid
+// sequence and decoding them reproduces Entities exactly. An entity that
+// crosses a sentence boundary stays with the sentence it starts in.
+func (doc *Document) IOB() [][]string {
+	tags := make([]string, len(doc.tokens))
+	index := make(map[*Token]int, len(doc.tokens))
+	for i, tok := range doc.tokens {
+		tags[i] = "O"
+		index[tok] = i
+	}
+	for k, ent := range doc.entities {
+		for j, tok := range doc.entParts[k] {
+			i, found := index[tok]
+			if !found {
+				continue
+			} else if j == 0 {
+				tags[i] = "B-" + ent.Label
+			} else {
+				tags[i] = "I-" + ent.Label
+			}
+		}
+	}
+
+	groups := make([][]string, len(doc.sentStarts))
+	if len(groups) == 0 {
+		return [][]string{tags}
+	}
+	for i := range groups {
+		groups[i] = []string{}
+	}
+	sent := 0
+	for i, tok := range doc.tokens {
+		if !strings.HasPrefix(tags[i], "I-") {
+			sent = doc.sentenceAt(sent, tok.Start)
+		}
+		groups[sent] = append(groups[sent], tags[i])
+	}
+	return groups
+}
@@ -0,0 +1,58 @@
+package prose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zuvaai/prose/v3/sequence"
+)
+
+func TestIOB(t *testing.T) {
+	product, err := ModelFromFS("PRODUCT", embeddedModel)
+	require.NoError(t, err)
+
+	text := "Jane Smith moved to New York. She bought Windows 10 there. Then Bob Jones left Paris"
+	for _, opts := range [][]DocOpt{
+		{},
+		{UsingModel(product)},
+		{WithSegmentation(false)},
+		{WithMinConfidence(0.7)},
+	} {
+		doc, err := NewDocument(text, opts...)
+		require.NoError(t, err)
+
+		groups := doc.IOB()
+		if len(doc.Sentences()) > 0 {
+			require.Len(t, groups, len(doc.Sentences()))
+		} else {
+			require.Len(t, groups, 1)
+		}
+
+		flat := []string{}
+		for _, tags := range groups {
+			for i, tag := range tags {
+				if strings.HasPrefix(tag, "I-") {
+					// An I- tag continues an entity of the same type.
+					require.Greater(t, i, 0)
+					assert.Equal(t, tag[2:], tags[i-1][2:])
+				}
+			}
+			flat = append(flat, tags...)
+		}
+		tokens := doc.Tokens()
+		require.Len(t, flat, len(tokens))
+
+		ents := doc.Entities()
+		require.NotEmpty(t, ents)
+		spans := sequence.DecodeBIO(flat)
+		require.Len(t, spans, len(ents))
+		for i, span := range spans {
+			assert.Equal(t, ents[i].Label, span.Label)
+			assert.Equal(t, ents[i].Start, tokens[span.Start].Start)
+			assert.Equal(t, ents[i].End, tokens[span.End-1].End)
+			assert.Len(t, doc.EntityTokens(i), span.End-span.Start)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package prose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A LintIssue describes a suspicious annotation in labeled training data.
+type LintIssue struct {
+	Kind    string `json:"kind"`    // "range", "boundary", "overlap", or "label".
+	Context int    `json:"context"` // The index of the EntityContext.
+	Span    int    `json:"span"`    // The index of the span within its EntityContext.
+	Text    string `json:"text"`    // The span's text.
+	Message string `json:"message"` // A human-readable description.
+}
+
+// LintEntities checks labeled training data for suspicious annotations:
+// spans that are out of range, start or end with whitespace or punctuation,
+// or overlap one another, and identical texts given different labels.
+//
+// Span offsets are in characters (runes), as for training.
+func LintEntities(data []EntityContext) []LintIssue {
+	issues := []LintIssue{}
+	labels := map[string]map[string]bool{}
+	firstSeen := map[string][2]int{}
+
+	for i, entry := range data {
+		text := []rune(entry.Text)
+		for j, span := range entry.Spans {
+			if span.Start < 0 || span.End > len(text) || span.Start >= span.End {
+				issues = append(issues, LintIssue{
+					Kind: "range", Context: i, Span: j,
+					Message: fmt.Sprintf("span [%d, %d) is outside of the text", span.Start, span.End)})
+				continue
+			}
+
+			surface := string(text[span.Start:span.End])
+			// A trailing period is allowed, as in "Acme Inc.".
+			first, last := text[span.Start], text[span.End-1]
+			if isEdgeNoise(first) || (isEdgeNoise(last) && last != '.') {
+				issues = append(issues, LintIssue{
+					Kind: "boundary", Context: i, Span: j, Text: surface,
+					Message: "span starts or ends with whitespace or punctuation"})
+			}
+
+			for k := j + 1; k < len(entry.Spans); k++ {
+				other := entry.Spans[k]
+				if span.Start < other.End && other.Start < span.End {
+					issues = append(issues, LintIssue{
+						Kind: "overlap", Context: i, Span: j, Text: surface,
+						Message: fmt.Sprintf("span overlaps span %d", k)})
+				}
+			}
+
+			if !entry.Accept {
+				continue
+			}
+			key := strings.TrimFunc(surface, isEdgeNoise)
+			if labels[key] == nil {
+				labels[key] = map[string]bool{}
+				firstSeen[key] = [2]int{i, j}
+			}
+			labels[key][span.Label] = true
+		}
+	}
+
+	keys := []string{}
+	for key, seen := range labels {
+		if len(seen) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		names := []string{}
+		for label := range labels[key] {
+			names = append(names, label)
+		}
+		sort.Strings(names)
+		at := firstSeen[key]
+		issues = append(issues, LintIssue{
+			Kind: "label", Context: at[0], Span: at[1], Text: key,
+			Message: "text is labeled inconsistently: " + strings.Join(names, ", ")})
+	}
+
+	return issues
+}
+
+// isEdgeNoise determines if `r` is unexpected at the edge of a span.
+func isEdgeNoise(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r)
+}
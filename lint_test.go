@@ -0,0 +1,35 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintEntities(t *testing.T) {
+	data := []EntityContext{
+		{Accept: true, Text: "Acme Inc. hired Jane Doe.", Spans: []LabeledEntity{
+			{Start: 0, End: 9, Label: "ORG"},
+			{Start: 15, End: 25, Label: "PERSON"},
+			{Start: 16, End: 20, Label: "PERSON"},
+		}},
+		{Accept: true, Text: "Jane Doe founded Acme Inc.", Spans: []LabeledEntity{
+			{Start: 0, End: 8, Label: "ORG"},
+			{Start: 17, End: 40, Label: "ORG"},
+		}},
+	}
+
+	kinds := []string{}
+	for _, issue := range LintEntities(data) {
+		kinds = append(kinds, issue.Kind)
+	}
+	assert.Equal(t, []string{"boundary", "overlap", "range", "label"}, kinds)
+
+	issues := LintEntities(data)
+	assert.Equal(t, " Jane Doe.", issues[0].Text)
+	assert.Equal(t, LintIssue{
+		Kind: "label", Context: 0, Span: 1, Text: "Jane Doe",
+		Message: "text is labeled inconsistently: ORG, PERSON"}, issues[3])
+
+	assert.Empty(t, LintEntities(nil))
+}
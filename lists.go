@@ -0,0 +1,52 @@
+package prose
+
+// A ListItem represents a bulleted or enumerated item, such as a clause in a
+// contract.
+type ListItem struct {
+	Start  int    // The byte offset of the item's start.
+	End    int    // The byte offset of the item's end.
+	Text   string // The item's text, including its marker.
+	Marker string // The item's bullet or enumerator (e.g., "-", "1.", "(b)").
+}
+
+// WithListItems can enable or disable (the default) list item detection.
+//
+// When enabled, sentences never span more than one list item and the items
+// are exposed via Document.ListItems.
+func WithListItems(include bool) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.ListItems = include
+	}
+}
+
+// ListItems returns `doc`'s list items; it's nil unless list items were
+// requested with WithListItems.
+func (doc *Document) ListItems() []ListItem {
+	return doc.listItems
+}
+
+// detectListItems finds the list items in `text`.
+func detectListItems(text string) []ListItem {
+	items := []ListItem{}
+	for _, block := range DetectBlocks(text) {
+		if block.Kind != BlockListItem {
+			continue
+		}
+		marker := ""
+		if m := reBullet.FindStringSubmatch(block.Text); m != nil {
+			marker = m[1]
+		}
+		items = append(items, ListItem{
+			Start: block.Start, End: block.End, Text: block.Text, Marker: marker})
+	}
+	return items
+}
+
+// itemBreaks returns the offsets at which `items` start and end.
+func itemBreaks(items []ListItem) []int {
+	breaks := make([]int, 0, 2*len(items))
+	for _, item := range items {
+		breaks = append(breaks, item.Start, item.End)
+	}
+	return breaks
+}
@@ -0,0 +1,42 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListItems(t *testing.T) {
+	text := "The Supplier shall:\n" +
+		"(a) deliver the goods\n" +
+		"(b) invoice the Buyer within thirty days of delivery and\n" +
+		"    keep records\n" +
+		"(c) maintain insurance.\n" +
+		"This applies worldwide."
+
+	doc, err := NewDocument(text, WithListItems(true))
+	require.NoError(t, err)
+
+	markers := []string{}
+	for _, item := range doc.ListItems() {
+		markers = append(markers, item.Marker)
+		assert.Equal(t, item.Text, text[item.Start:item.End])
+	}
+	assert.Equal(t, []string{"(a)", "(b)", "(c)"}, markers)
+	assert.Equal(t,
+		"(b) invoice the Buyer within thirty days of delivery and\n    keep records",
+		doc.ListItems()[1].Text)
+
+	assert.Equal(t, []string{
+		"The Supplier shall:",
+		"(a) deliver the goods",
+		"(b) invoice the Buyer within thirty days of delivery and\n    keep records",
+		"(c) maintain insurance.",
+		"This applies worldwide."}, sentenceTexts(doc))
+
+	doc, err = NewDocument(text)
+	require.NoError(t, err)
+	assert.Nil(t, doc.ListItems())
+	assert.Less(t, len(doc.Sentences()), 5)
+}
@@ -1,11 +1,15 @@
 package prose
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"os"
-	"path/filepath"
+	"path"
+	"sort"
+	"strings"
 )
 
 // A Model holds the structures and data used internally by prose.
@@ -14,11 +18,22 @@ type Model struct {
 
 	tagger    *PerceptronTagger
 	extracter *entityExtracter
+
+	err error // A DataSource's failure, if any.
 }
 
 // DataSource provides training data to a Model.
 type DataSource func(model *Model)
 
+// UsingTagger replaces the Model's POS tagger with `tagger` (e.g., one
+// trained with PerceptronTagger.Train). NERs created by later DataSources
+// are trained with its tags.
+func UsingTagger(tagger *PerceptronTagger) DataSource {
+	return func(model *Model) {
+		model.tagger = tagger
+	}
+}
+
 // UsingEntities creates a NER from labeled data.
 func UsingEntities(data []EntityContext) DataSource {
 	return UsingEntitiesAndTokenizer(data, NewIterTokenizer())
@@ -26,12 +41,181 @@ func UsingEntities(data []EntityContext) DataSource {
 
 // UsingEntities creates a NER from labeled data and custom tokenizer.
 func UsingEntitiesAndTokenizer(data []EntityContext, tokenizer Tokenizer) DataSource {
+	return UsingEntitiesWithOptions(data, TrainingOptions{Tokenizer: tokenizer})
+}
+
+// TrainingOptions controls how a NER is trained from labeled data.
+type TrainingOptions struct {
+	// Iterations is the maximum number of training iterations; the default
+	// is 100. More iterations take longer but fit the data more closely.
+	Iterations int
+
+	// Tolerance, if positive, stops training early once no weight changes
+	// by more than it in an iteration.
+	Tolerance float64
+
+	// Tokenizer is used to tokenize the data; the default is
+	// NewIterTokenizer(). Documents should be tokenized the same way.
+	Tokenizer Tokenizer
+
+	// HashSize, if positive, hashes features into a fixed number of weights
+	// instead of assigning one weight per distinct feature. This bounds the
+	// size of the trained model (and of its weight vector during training)
+	// at a small cost in accuracy; it doesn't bound the memory used by the
+	// training data itself, whose features are still held in memory.
+	HashSize int
+
+	// WarmStart, if true, initializes training from the Model's current NER
+	// (the embedded English model, by default): its features and labels are
+	// kept, and features shared with the new data start from its weights.
+	// WarmStart is ignored when HashSize is set.
+	WarmStart bool
+
+	// BlockContext, if true, adds a feature describing the structural block
+	// (heading, list item, or table) containing each token. Models trained
+	// with it should be used with WithBlockContext.
+	BlockContext bool
+
+	// NegativeRate, if between 0 and 1, is the fraction of sentences without
+	// any entities that are kept for training. Such sentences usually
+	// dominate real data; keeping fewer of them speeds training and tends to
+	// improve recall. The kept sentences are spread evenly over the data.
+	NegativeRate float64
+
+	// Debug, if non-nil, receives the features extracted for every
+	// DebugEvery-th token (every token, if DebugEvery isn't positive), one
+	// line each: the token's label followed by tab-separated name=value
+	// pairs. Write errors are ignored.
+	Debug      io.Writer
+	DebugEvery int
+
+	// Scheme, if set, declares the entity labels the NER is meant to assign
+	// (e.g., OntoNotesLabels); it's saved with the model and reported by
+	// Model.Labels. Data with other labels is rejected.
+	Scheme []string
+
+	// LabelFeatures, if set, restricts the input-features used by some
+	// entity labels (or "O", for tokens outside of entities) to those named
+	// (e.g., "word", "shape", "prevtag"; see the output of Debug). This
+	// shrinks models with many labels and speeds them up; labels without
+	// an entry use every feature. It's ignored when resuming a checkpoint,
+	// which keeps its own.
+	LabelFeatures map[string][]string
+
+	// CheckpointEvery, if positive, passes the NER trained so far to
+	// Checkpoint every CheckpointEvery iterations (e.g., CheckpointToDisk),
+	// so that an interrupted training can be resumed.
+	CheckpointEvery int
+	Checkpoint      func(checkpoint *Model, iteration int) error
+
+	// Resume, if non-nil, continues the training that produced the
+	// checkpoint Resume after ResumeIteration iterations (e.g., as set by
+	// ResumeFromDisk). The data and other options must be the same.
+	Resume          *Model
+	ResumeIteration int
+
+	// Progress, if non-nil, is called at the start of each iteration (counted
+	// from 0) with the log-likelihood, in nats, of the data's labels under
+	// the NER trained so far. It should rise with each iteration; a plateau
+	// means that training has converged.
+	Progress func(iteration int, logLikelihood float64)
+
+	// Context, if non-nil, cancels training when it's done; it's checked
+	// before each iteration, and the error wraps its Err.
+	Context context.Context
+
+	// Snapshot, if true, saves a DataManifest with the model: a hash and
+	// summary of the data, of TestData (the held-out data it's evaluated
+	// with), if any, and the names of the data's Sources (e.g., file names
+	// or dataset versions). Neither TestData nor Sources affect training.
+	Snapshot bool
+	TestData []EntityContext
+	Sources  []string
+}
+
+// UsingEntitiesWithOptions creates a NER from labeled data according to
+// `opts`.
+func UsingEntitiesWithOptions(data []EntityContext, opts TrainingOptions) DataSource {
 	return func(model *Model) {
-		corpus := makeCorpus(data, model.tagger, tokenizer)
-		model.extracter = extracterFromData(corpus)
+		var base *entityExtracter
+		if opts.WarmStart {
+			base = model.extracter
+			if base == nil {
+				var err error
+				if base, err = newEntityExtracter(); err != nil {
+					model.err = fmt.Errorf("unable to load default NER: %w", err)
+					return
+				}
+			}
+		}
+		if err := checkScheme(data, opts.Scheme); err != nil {
+			model.err = err
+			return
+		}
+		tokenizer := opts.Tokenizer
+		if tokenizer == nil {
+			tokenizer = NewIterTokenizer()
+		}
+		corpus := makeCorpus(data, model.tagger, tokenizer, opts)
+		extracter, err := extracterFromData(corpus, opts, base)
+		if err != nil {
+			model.err = err
+			return
+		}
+		model.extracter = extracter
+		model.extracter.tokenizer = tokenizerFingerprint(tokenizer)
+		model.extracter.scheme = opts.Scheme
+		model.extracter.manifest = newManifest(data, opts)
 	}
 }
 
+// OntoNotesLabels are the 18 entity labels of the OntoNotes 5 scheme, used
+// by many public NER datasets and models.
+var OntoNotesLabels = []string{
+	"CARDINAL", "DATE", "EVENT", "FAC", "GPE", "LANGUAGE", "LAW", "LOC",
+	"MONEY", "NORP", "ORDINAL", "ORG", "PERCENT", "PERSON", "PRODUCT",
+	"QUANTITY", "TIME", "WORK_OF_ART",
+}
+
+// checkScheme determines if the entities of `data` only use the labels of
+// `scheme`, if any.
+func checkScheme(data []EntityContext, scheme []string) error {
+	if len(scheme) == 0 {
+		return nil
+	}
+	for i, example := range data {
+		for _, span := range example.Spans {
+			if !stringInSlice(span.Label, scheme) {
+				return fmt.Errorf("label %q of example %d isn't in the scheme", span.Label, i)
+			}
+		}
+	}
+	return nil
+}
+
+// Labels returns the entity labels the Model's NER assigns, sorted: the
+// declared scheme (see TrainingOptions.Scheme), if any, or those it was
+// trained with.
+func (m *Model) Labels() []string {
+	if m.extracter == nil {
+		return []string{}
+	} else if len(m.extracter.scheme) > 0 {
+		labels := append([]string{}, m.extracter.scheme...)
+		sort.Strings(labels)
+		return labels
+	}
+
+	labels := []string{}
+	for _, label := range m.extracter.model.labels {
+		parts := strings.SplitN(label, "-", 2)
+		if len(parts) == 2 && !stringInSlice(parts[1], labels) {
+			labels = append(labels, parts[1])
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
 // LabeledEntity represents an externally-labeled named-entity.
 type LabeledEntity struct {
 	Start int
@@ -52,39 +236,66 @@ type EntityContext struct {
 }
 
 // ModelFromData creates a new Model from user-provided training data.
+//
+// The default NER is only loaded if no DataSource provides one (or one
+// needs it, as with TrainingOptions.WarmStart).
 func ModelFromData(name string, sources ...DataSource) (*Model, error) {
-	model, err := defaultModel(true, true)
+	model, err := defaultModel(true, false)
 	if err != nil {
 		return nil, fmt.Errorf("unable to load default model: %w", err)
 	}
 	model.Name = name
 	for _, source := range sources {
 		source(model)
+		if model.err != nil {
+			return nil, fmt.Errorf("unable to train model: %w", model.err)
+		}
+	}
+	if model.extracter == nil {
+		model.extracter, err = newEntityExtracter()
+		if err != nil {
+			return nil, fmt.Errorf("unable to load default NER: %w", err)
+		}
 	}
 	return model, nil
 }
 
-// ModelFromDisk loads a Model from the user-provided location.
-func ModelFromDisk(path string) (*Model, error) {
-	filesys := os.DirFS(path)
-	tagger, err := NewPerceptronTagger()
-	if err != nil {
-		return nil, fmt.Errorf("unable to load POS tager from disk: %w", err)
-	}
-	classifier, err := loadClassifier(filesys)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load classifier from disk: %w", err)
+// LoadOpt is a setting that changes how a Model is loaded from disk.
+type LoadOpt func(opts *LoadOpts)
+
+// LoadOpts controls how a Model is loaded from disk.
+type LoadOpts struct {
+	// LabelMap renames the entity labels of the loaded NER (e.g., "PER" ->
+	// "PERSON"). Labels not in the map are kept as-is.
+	LabelMap map[string]string
+
+	// MinWeight drops NER features whose weights have an absolute value
+	// below it, as Model.Prune does, once the model is loaded.
+	MinWeight float64
+}
+
+// UsingLabelMap renames the entity labels of a loaded NER according to
+// `labels`, which maps a model's labels (e.g., "PER") to the ones downstream
+// code expects (e.g., "PERSON").
+func UsingLabelMap(labels map[string]string) LoadOpt {
+	return func(opts *LoadOpts) {
+		opts.LabelMap = labels
 	}
-	return &Model{
-		Name: filepath.Base(path),
+}
 
-		extracter: classifier,
-		tagger:    tagger,
-	}, nil
+// UsingMinWeight sparsifies a loaded NER by dropping the features whose
+// weights have an absolute value below `threshold` (see Model.Prune), which
+// reduces the memory held by models with very large feature spaces. The
+// model is decoded in full before it's pruned, so it doesn't reduce the
+// memory needed to load it.
+func UsingMinWeight(threshold float64) LoadOpt {
+	return func(opts *LoadOpts) {
+		opts.MinWeight = threshold
+	}
 }
 
 // ModelFromFS loads a model from the
-func ModelFromFS(name string, filesys fs.FS) (*Model, error) {
+func ModelFromFS(name string, filesys fs.FS, opts ...LoadOpt) (*Model, error) {
 	// Locate a folder matching name within filesys
 	var modelFS fs.FS
 	err := fs.WalkDir(filesys, ".", func(path string, d fs.DirEntry, err error) error {
@@ -103,56 +314,112 @@ func ModelFromFS(name string, filesys fs.FS) (*Model, error) {
 
 		return nil
 	})
-	if err != io.EOF {
+	if err == nil {
+		return nil, fmt.Errorf("%w: no directory named %s", ErrModelNotFound, name)
+	} else if err != io.EOF {
 		return nil, fmt.Errorf("expected EOF but got: %w", err)
 	}
-	tagger, err := NewPerceptronTagger()
+	return loadModel(name, modelFS, opts)
+}
+
+// ModelsFromFS loads every model found within `filesys`, such as an optional
+// pack of domain-specific models (prose doesn't ship any); a model is any
+// directory that contains a "Maxent" subdirectory, and is named after that
+// directory. Models must have distinct names.
+func ModelsFromFS(filesys fs.FS, opts ...LoadOpt) ([]*Model, error) {
+	dirs := map[string]string{}
+	names := []string{}
+	err := fs.WalkDir(filesys, ".", func(dir string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "Maxent" && dir != "Maxent" {
+			parent := path.Dir(dir)
+			name := path.Base(parent)
+			if other, found := dirs[name]; found {
+				return fmt.Errorf("duplicate model %s in %s and %s", name, other, parent)
+			}
+			dirs[name] = parent
+			names = append(names, name)
+			return fs.SkipDir
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to create POS tagger FS: %w", err)
+		return nil, fmt.Errorf("unable to search for models: %w", err)
 	}
-	classifier, err := loadClassifier(modelFS)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load classifier from FS: %w", err)
+
+	models := make([]*Model, 0, len(names))
+	for _, name := range names {
+		modelFS, err := fs.Sub(filesys, dirs[name])
+		if err != nil {
+			return nil, fmt.Errorf("unable to load model %s: %w", name, err)
+		}
+		model, err := loadModel(name, modelFS, opts)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load model %s: %w", name, err)
+		}
+		models = append(models, model)
 	}
-	return &Model{
-		Name: name,
+	return models, nil
+}
 
-		extracter: classifier,
-		tagger:    tagger,
-	}, nil
+// A PruneReport summarizes the effect of pruning a Model.
+type PruneReport struct {
+	Before int // The number of NER features before pruning.
+	After  int // The number of NER features after pruning.
+
+	// RemovedWeight is the total absolute weight of the removed features, a
+	// rough proxy for how much the model's predictions may change.
+	RemovedWeight float64
 }
 
-// Write saves a Model to the user-provided location.
-func (m *Model) Write(path string) error {
-	err := os.MkdirAll(path, os.ModePerm)
-	if err != nil {
-		return fmt.Errorf("unable to open directory: %w", err)
+// Prune removes NER features whose weights have an absolute value below
+// `minAbsWeight`, making the Model smaller and faster to evaluate.
+func (m *Model) Prune(minAbsWeight float64) (PruneReport, error) {
+	if m.extracter == nil {
+		return PruneReport{}, fmt.Errorf("unable to prune: model has no NER")
 	}
-	// m.Tagger.model.Marshal(path)
-	return m.extracter.model.marshal(path)
+	before, after, removed := m.extracter.model.prune(minAbsWeight)
+	m.extracter.digest = ""
+	return PruneReport{Before: before, After: after, RemovedWeight: removed}, nil
 }
 
-/* TODO: External taggers
-func loadTagger(path string) *perceptronTagger {
-	var wts map[string]map[string]float64
+// loadTagger loads the POS tagger in the "AveragedPerceptron" subdirectory
+// of `filesys`, or the embedded one if there isn't one.
+func loadTagger(filesys fs.FS) (*PerceptronTagger, error) {
+	var lwts map[string][]float64
 	var tags map[string]string
 	var classes []string
 
-	loc := filepath.Join(path, "AveragedPerceptron")
-	dec := getDiskAsset(filepath.Join(loc, "weights.gob"))
-	checkError(dec.Decode(&wts))
-
-	dec = getDiskAsset(filepath.Join(loc, "tags.gob"))
-	checkError(dec.Decode(&tags))
-
-	dec = getDiskAsset(filepath.Join(loc, "classes.gob"))
-	checkError(dec.Decode(&classes))
+	if _, err := fs.Stat(filesys, "AveragedPerceptron"); errors.Is(err, fs.ErrNotExist) {
+		return NewPerceptronTagger()
+	}
+	perceptron, err := fs.Sub(filesys, "AveragedPerceptron")
+	if err != nil {
+		return nil, fmt.Errorf("unable to open subdirectory AveragedPerceptron: %w", err)
+	}
 
-	model := newAveragedPerceptron(wts, tags, classes)
-	return newTrainedPerceptronTagger(model)
-}*/
+	err = decodeFS(perceptron, "classes.gob", &classes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode classes: %w", err)
+	}
+	err = decodeFS(perceptron, "tags.gob", &tags)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode tags: %w", err)
+	}
+	err = decodeFS(perceptron, "weights-linear.gob", &lwts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode linear weights: %w", err)
+	}
+	model := newAveragedPerceptron(tags, classes, lwts)
+	if err = model.check(); err != nil {
+		return nil, err
+	}
+	return &PerceptronTagger{model: model}, nil
+}
 
-func loadClassifier(filesys fs.FS) (*entityExtracter, error) {
+func loadClassifier(filesys fs.FS, opts LoadOpts) (*entityExtracter, error) {
 	var mapping map[string]int
 	var weights []float64
 	var labels []string
@@ -162,36 +429,103 @@ func loadClassifier(filesys fs.FS) (*entityExtracter, error) {
 		return nil, fmt.Errorf("unable to open subdirectory Maxent: %w", err)
 	}
 
-	file, err := maxent.Open("mapping.gob")
-	if err != nil {
-		return nil, fmt.Errorf("unable to open mapping.gob: %w", err)
-	}
-
-	err = getDiskAsset(file).Decode(&mapping)
+	err = decodeFS(maxent, "mapping.gob", &mapping)
 	if err != nil {
 		return nil, fmt.Errorf("unable to decode mapping: %w", err)
 	}
-
-	file, err = maxent.Open("weights.gob")
+	err = decodeFS(maxent, "weights.gob", &weights)
 	if err != nil {
-		return nil, fmt.Errorf("unable to open weights.gob: %w", err)
+		return nil, fmt.Errorf("unable to decode weights: %w", err)
 	}
-	err = getDiskAsset(file).Decode(&weights)
+	err = decodeFS(maxent, "labels.gob", &labels)
 	if err != nil {
-		return nil, fmt.Errorf("unable to decode weights: %w", err)
+		return nil, fmt.Errorf("unable to decode labels: %w", err)
 	}
 
-	file, err = maxent.Open("labels.gob")
-	if err != nil {
-		return nil, fmt.Errorf("unable to open labels.gob: %w", err)
+	// Models trained before fingerprints were introduced don't have one.
+	fingerprint, err := fs.ReadFile(maxent, "tokenizer.txt")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unable to read tokenizer.txt: %w", err)
 	}
-	err = getDiskAsset(file).Decode(&labels)
-	if err != nil {
-		return nil, fmt.Errorf("unable to decode labels: %w", err)
+	// Nor do models without a declared label scheme.
+	scheme, err := fs.ReadFile(maxent, "scheme.txt")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unable to read scheme.txt: %w", err)
+	}
+	// Nor do models trained without TrainingOptions.Snapshot.
+	var manifest *DataManifest
+	data, err := fs.ReadFile(maxent, "manifest.json")
+	if err == nil {
+		manifest = new(DataManifest)
+		if err = json.Unmarshal(data, manifest); err != nil {
+			return nil, withCode(ErrCorruptModel, fmt.Errorf("unable to decode manifest: %w", err))
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unable to read manifest.json: %w", err)
+	}
+
+	// Nor do models trained without TrainingOptions.LabelFeatures.
+	var labelFeatures map[string][]string
+	if _, err = fs.Stat(maxent, "templates.gob"); err == nil {
+		if err = decodeFS(maxent, "templates.gob", &labelFeatures); err != nil {
+			return nil, fmt.Errorf("unable to decode feature templates: %w", err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unable to read templates.gob: %w", err)
+	}
+
+	// Nor do models that haven't been distilled.
+	var lookup map[string]string
+	if _, err = fs.Stat(maxent, "lookup.gob"); err == nil {
+		if err = decodeFS(maxent, "lookup.gob", &lookup); err != nil {
+			return nil, fmt.Errorf("unable to decode lookup table: %w", err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unable to read lookup.gob: %w", err)
 	}
 
 	model := newMaxentClassifier(weights, mapping, labels)
-	return newTrainedEntityExtracter(model), nil
+	if labelFeatures != nil {
+		model.templates = map[string][]int{}
+		for label, names := range labelFeatures {
+			if model.templates[label], err = featureIndices(names); err != nil {
+				return nil, withCode(ErrCorruptModel, fmt.Errorf("invalid features for %s: %w", label, err))
+			}
+		}
+	}
+	if err = model.check(); err != nil {
+		return nil, err
+	}
+	if len(opts.LabelMap) > 0 {
+		err = model.relabel(opts.LabelMap)
+		if err != nil {
+			return nil, fmt.Errorf("unable to remap labels: %w", err)
+		}
+	}
+	if opts.MinWeight > 0 {
+		model.prune(opts.MinWeight)
+	}
+	extracter := newTrainedEntityExtracter(model)
+	extracter.tokenizer = string(fingerprint)
+	extracter.manifest = manifest
+	if lookup != nil {
+		extracter.lookup = make(map[string]string, len(lookup))
+		for key, label := range lookup {
+			if parts := strings.SplitN(label, "-", 2); len(parts) == 2 {
+				if name, found := opts.LabelMap[parts[1]]; found {
+					label = parts[0] + "-" + name
+				}
+			}
+			extracter.lookup[key] = label
+		}
+	}
+	for _, label := range strings.Fields(string(scheme)) {
+		if name, found := opts.LabelMap[label]; found {
+			label = name
+		}
+		extracter.scheme = append(extracter.scheme, label)
+	}
+	return extracter, nil
 }
 
 func defaultModel(tagging, classifying bool) (*Model, error) {
@@ -13,9 +13,13 @@ var assets embed.FS
 
 const datadir = "model"
 
+// readAsset reads the embedded file `name`; tests replace it to observe
+// which assets are used.
+var readAsset = assets.ReadFile
+
 // ReadBytes reads an embedded file into a byte slice.
 func ReadBytes(filename string) ([]byte, error) {
-	return assets.ReadFile(path.Join(datadir, filename))
+	return readAsset(path.Join(datadir, filename))
 }
 
 // ReadAndDecodeBytes reads an embedded file into a gob decoder
@@ -26,3 +30,15 @@ func ReadAndDecodeBytes(filename string) (*gob.Decoder, error) {
 	}
 	return gob.NewDecoder(bytes.NewReader(b)), nil
 }
+
+// decodeAsset decodes the gob stored in the embedded file `name` into `v`.
+func decodeAsset(name string, v interface{}) error {
+	dec, err := ReadAndDecodeBytes(name)
+	if err != nil {
+		return err
+	}
+	if err = dec.Decode(v); err != nil {
+		return withCode(ErrCorruptModel, fmt.Errorf("unable to decode %s: %w", name, err))
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package prose
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The functions in this file read and write models on disk; everything
+// else works with embedded or in-memory (fs.FS) models, so that inference
+// doesn't depend on a filesystem (e.g., in js/wasm builds).
+
+// ModelFromDisk loads a Model from the user-provided location.
+func ModelFromDisk(path string, opts ...LoadOpt) (*Model, error) {
+	return loadModel(filepath.Base(path), os.DirFS(path), opts)
+}
+
+// Write saves a Model to the user-provided location, replacing whatever is
+// there (see replaceDir), so that nothing of a model saved there before is
+// left to be loaded with it.
+func (m *Model) Write(path string) error {
+	return replaceDir(path, m.writeDir)
+}
+
+// writeDir saves a Model to the directory `path`, which is expected to be
+// new.
+func (m *Model) writeDir(path string) error {
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return err
+	}
+	return m.save(func(name string) (io.WriteCloser, error) {
+		file := filepath.Join(path, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			return nil, err
+		}
+		return os.Create(file)
+	})
+}
+
+// WriteArchive saves a Model to the single (zip) file `path`, in the format
+// written by WriteTo.
+func (m *Model) WriteArchive(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create archive: %w", err)
+	}
+	if _, err = m.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ModelFromArchive loads a Model from the file `path` written by
+// WriteArchive; it's named after the file, without its extension.
+//
+// Unlike ModelFromReader, the archive is read from disk as needed rather
+// than into memory.
+func ModelFromArchive(path string, opts ...LoadOpt) (*Model, error) {
+	archive, err := zip.OpenReader(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, path)
+	} else if err != nil {
+		return nil, withCode(ErrCorruptModel, fmt.Errorf("unable to open model archive: %w", err))
+	}
+	defer archive.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return loadModel(name, archive, opts)
+}
+
+// diskStore is a ModelStore of the models in a directory.
+type diskStore struct {
+	dir string
+}
+
+// NewDiskStore creates a ModelStore of the models in the directory `dir`,
+// each saved by Model.Write to a subdirectory named after it.
+func NewDiskStore(dir string) ModelStore {
+	return &diskStore{dir: dir}
+}
+
+func (s *diskStore) Save(m *Model) error {
+	path, err := s.path(m.Name)
+	if err != nil {
+		return err
+	}
+	if err = m.Write(path); err != nil {
+		return fmt.Errorf("unable to save model %s: %w", m.Name, err)
+	}
+	return nil
+}
+
+func (s *diskStore) Load(name string, opts ...LoadOpt) (*Model, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return loadModel(name, os.DirFS(path), opts)
+}
+
+// path returns the directory of the model `name`.
+func (s *diskStore) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid model name %q", name)
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// CheckpointToDisk returns a TrainingOptions.Checkpoint function that saves
+// each checkpoint to the directory `dir`, replacing the previous one only
+// once the new one is complete.
+func CheckpointToDisk(dir string) func(checkpoint *Model, iteration int) error {
+	return func(checkpoint *Model, iteration int) error {
+		return replaceDir(dir, func(temp string) error {
+			if err := checkpoint.writeDir(temp); err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(temp, "iteration.txt"), []byte(strconv.Itoa(iteration)), 0644)
+		})
+	}
+}
+
+// replaceDir replaces the directory `dir` with the one `write` creates,
+// which is written in full beside it first. The current copy is renamed
+// aside (to `dir`.old) before the new one is renamed into place, and only
+// removed after, so that a complete copy is always on disk.
+func replaceDir(dir string, write func(temp string) error) error {
+	temp, old := dir+".tmp", dir+".old"
+	if err := os.RemoveAll(temp); err != nil {
+		return err
+	}
+	if err := write(temp); err != nil {
+		os.RemoveAll(temp)
+		return err
+	}
+	if err := os.RemoveAll(old); err != nil {
+		return err
+	}
+	if err := os.Rename(dir, old); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(temp, dir); err != nil {
+		os.Rename(old, dir)
+		return err
+	}
+	// A leftover copy is harmless; the next save removes it.
+	os.RemoveAll(old)
+	return nil
+}
+
+// ResumeFromDisk sets `opts` to resume training from the checkpoint saved
+// by CheckpointToDisk in `dir`; if there isn't one, `opts` is unchanged and
+// training starts from scratch.
+func ResumeFromDisk(dir string, opts *TrainingOptions) error {
+	data, err := os.ReadFile(filepath.Join(dir, "iteration.txt"))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read checkpoint: %w", err)
+	}
+	iteration, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return withCode(ErrCorruptModel, fmt.Errorf("unable to parse checkpoint iteration: %w", err))
+	}
+	checkpoint, err := ModelFromDisk(dir)
+	if err != nil {
+		return fmt.Errorf("unable to load checkpoint: %w", err)
+	}
+	opts.Resume, opts.ResumeIteration = checkpoint, iteration
+	return nil
+}
@@ -0,0 +1,113 @@
+package prose
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// A ModelStore saves and loads Models by name, e.g., in a directory or a
+// cloud storage bucket.
+type ModelStore interface {
+	// Save stores `m` under m.Name, replacing any Model with that name.
+	Save(m *Model) error
+
+	// Load loads the Model named `name`; if there isn't one, the error
+	// matches ErrModelNotFound.
+	Load(name string, opts ...LoadOpt) (*Model, error)
+}
+
+// WriteToStore saves a Model to `store`, under its name.
+func (m *Model) WriteToStore(store ModelStore) error {
+	if m.Name == "" {
+		return errors.New("unable to store a model without a name")
+	}
+	return store.Save(m)
+}
+
+// ModelFromStore loads the Model named `name` from `store`.
+func ModelFromStore(store ModelStore, name string, opts ...LoadOpt) (*Model, error) {
+	return store.Load(name, opts...)
+}
+
+// fsStore is a read-only ModelStore of the models in an fs.FS.
+type fsStore struct {
+	filesys fs.FS
+}
+
+// NewFSStore creates a read-only ModelStore of the models in `filesys`
+// (e.g., an embed.FS), each a top-level directory named after its model.
+func NewFSStore(filesys fs.FS) ModelStore {
+	return &fsStore{filesys: filesys}
+}
+
+func (s *fsStore) Save(m *Model) error {
+	return fmt.Errorf("unable to save model %s: the store is read-only", m.Name)
+}
+
+func (s *fsStore) Load(name string, opts ...LoadOpt) (*Model, error) {
+	if !fs.ValidPath(name) || path.Dir(name) != "." {
+		return nil, fmt.Errorf("invalid model name %q", name)
+	}
+	if _, err := fs.Stat(s.filesys, name); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no directory named %s", ErrModelNotFound, name)
+	}
+	sub, err := fs.Sub(s.filesys, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open model %s: %w", name, err)
+	}
+	return loadModel(name, sub, opts)
+}
+
+// An ObjectClient reads and writes the objects of a bucket; it's usually a
+// thin wrapper around an S3 or GCS client, which also sets any timeouts.
+type ObjectClient interface {
+	// Get returns the content of the object `key`; if there isn't one, the
+	// error matches fs.ErrNotExist.
+	Get(key string) (io.ReadCloser, error)
+
+	// Put stores the content of `r` as the object `key`.
+	Put(key string, r io.Reader) error
+}
+
+// objectStore is a ModelStore that keeps each model as a single archive
+// (see Model.WriteTo) in a bucket.
+type objectStore struct {
+	client ObjectClient
+	prefix string
+}
+
+// NewObjectStore creates a ModelStore that keeps each model as the object
+// `prefix` + name + ".zip", in the format written by Model.WriteTo.
+func NewObjectStore(client ObjectClient, prefix string) ModelStore {
+	return &objectStore{client: client, prefix: prefix}
+}
+
+func (s *objectStore) key(name string) string {
+	return s.prefix + name + ".zip"
+}
+
+func (s *objectStore) Save(m *Model) error {
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		return err
+	}
+	if err := s.client.Put(s.key(m.Name), &buf); err != nil {
+		return fmt.Errorf("unable to upload model %s: %w", m.Name, err)
+	}
+	return nil
+}
+
+func (s *objectStore) Load(name string, opts ...LoadOpt) (*Model, error) {
+	obj, err := s.client.Get(s.key(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, s.key(name))
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to download model %s: %w", name, err)
+	}
+	defer obj.Close()
+	return ModelFromReader(name, obj, opts...)
+}
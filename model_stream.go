@@ -0,0 +1,226 @@
+package prose
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// A modelWriter creates the file `name` (a slash-separated path) of a
+// serialized Model.
+type modelWriter func(name string) (io.WriteCloser, error)
+
+// WriteTo writes `m` to `w` as a single stream (a zip archive of the files
+// saved by Write), which ModelFromReader loads.
+func (m *Model) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{w: w}
+	archive := zip.NewWriter(counter)
+	err := m.save(func(name string) (io.WriteCloser, error) {
+		file, err := archive.Create(name)
+		return nopCloser{file}, err
+	})
+	if err != nil {
+		return counter.n, err
+	}
+	if err = archive.Close(); err != nil {
+		return counter.n, fmt.Errorf("unable to finish archive: %w", err)
+	}
+	return counter.n, nil
+}
+
+// ModelFromReader loads a Model named `name` from a stream written by
+// Model.WriteTo.
+func ModelFromReader(name string, r io.Reader, opts ...LoadOpt) (*Model, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read model: %w", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, withCode(ErrCorruptModel, fmt.Errorf("unable to open model archive: %w", err))
+	}
+	return loadModel(name, archive, opts)
+}
+
+// loadModel loads the Model stored in `filesys`.
+func loadModel(name string, filesys fs.FS, opts []LoadOpt) (*Model, error) {
+	base := LoadOpts{}
+	for _, applyOpt := range opts {
+		applyOpt(&base)
+	}
+
+	if _, err := fs.Stat(filesys, "Maxent"); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s has no Maxent directory", ErrModelNotFound, name)
+	}
+	if name := missingComponent(filesys); name != "" {
+		return nil, withCode(ErrCorruptModel, fmt.Errorf("missing model component %s", name))
+	}
+	tagger, err := loadTagger(filesys)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load POS tagger: %w", err)
+	}
+	classifier, err := loadClassifier(filesys, base)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load classifier: %w", err)
+	}
+	return &Model{
+		Name: name,
+
+		extracter: classifier,
+		tagger:    tagger,
+	}, nil
+}
+
+// missingComponent returns the name of the first file that `filesys` should
+// have but doesn't, if any; the tagger is optional, but if it's present, it
+// must be complete.
+func missingComponent(filesys fs.FS) string {
+	required := []string{"Maxent/labels.gob", "Maxent/mapping.gob", "Maxent/weights.gob"}
+	if _, err := fs.Stat(filesys, "AveragedPerceptron"); err == nil {
+		required = append(required, "AveragedPerceptron/classes.gob",
+			"AveragedPerceptron/tags.gob", "AveragedPerceptron/weights-linear.gob")
+	}
+	for _, name := range required {
+		if _, err := fs.Stat(filesys, name); errors.Is(err, fs.ErrNotExist) {
+			return name
+		}
+	}
+	return ""
+}
+
+// save writes the files that make up `m` to `write`. The built-in tagger
+// isn't written, since loadTagger falls back to it.
+func (m *Model) save(write modelWriter) error {
+	if m.tagger != nil {
+		if !m.tagger.model.embedded {
+			if err := m.tagger.model.marshal(write); err != nil {
+				return err
+			}
+		}
+	}
+	if m.extracter == nil {
+		return nil
+	}
+	if err := m.extracter.model.marshal(write); err != nil {
+		return err
+	}
+	if m.extracter.lookup != nil {
+		if err := writeGob(write, "Maxent/lookup.gob", m.extracter.lookup); err != nil {
+			return fmt.Errorf("unable to marshal lookup table: %w", err)
+		}
+	}
+	if len(m.extracter.scheme) > 0 {
+		err := writeFile(write, "Maxent/scheme.txt", func(w io.Writer) error {
+			_, err := io.WriteString(w, strings.Join(m.extracter.scheme, "\n")+"\n")
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("unable to write label scheme: %w", err)
+		}
+	}
+	if m.extracter.tokenizer != "" {
+		err := writeFile(write, "Maxent/tokenizer.txt", func(w io.Writer) error {
+			_, err := io.WriteString(w, m.extracter.tokenizer)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("unable to write tokenizer fingerprint: %w", err)
+		}
+	}
+	if m.extracter.manifest != nil {
+		err := writeFile(write, "Maxent/manifest.json", func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(m.extracter.manifest)
+		})
+		if err != nil {
+			return fmt.Errorf("unable to write manifest: %w", err)
+		}
+	}
+	return nil
+}
+
+// marshal saves the model to `write`.
+func (m *binaryMaxentClassifier) marshal(write modelWriter) error {
+	if err := writeGob(write, "Maxent/labels.gob", m.labels); err != nil {
+		return fmt.Errorf("unable to marshal labels: %w", err)
+	}
+	if err := writeGob(write, "Maxent/mapping.gob", m.mapping); err != nil {
+		return fmt.Errorf("unable to marshal mapping: %w", err)
+	}
+	if err := writeGob(write, "Maxent/weights.gob", m.weights); err != nil {
+		return fmt.Errorf("unable to marshal weights: %w", err)
+	}
+	if len(m.templates) > 0 {
+		// By name, so that they survive changes to featureOrder.
+		labelFeatures := make(map[string][]string, len(m.templates))
+		for label, indices := range m.templates {
+			labelFeatures[label] = []string{}
+			for _, i := range indices {
+				labelFeatures[label] = append(labelFeatures[label], featureOrder[i])
+			}
+		}
+		if err := writeGob(write, "Maxent/templates.gob", labelFeatures); err != nil {
+			return fmt.Errorf("unable to marshal feature templates: %w", err)
+		}
+	}
+	return nil
+}
+
+// marshal saves the model to `write`.
+func (m *averagedPerceptron) marshal(write modelWriter) error {
+	if err := writeGob(write, "AveragedPerceptron/classes.gob", m.classes); err != nil {
+		return fmt.Errorf("unable to marshal classes: %w", err)
+	}
+	if err := writeGob(write, "AveragedPerceptron/tags.gob", m.tagMap); err != nil {
+		return fmt.Errorf("unable to marshal tags: %w", err)
+	}
+	if err := writeGob(write, "AveragedPerceptron/weights-linear.gob", m.linearWeights); err != nil {
+		return fmt.Errorf("unable to marshal linear weights: %w", err)
+	}
+	return nil
+}
+
+// writeGob encodes `v` into the file `name`.
+func writeGob(write modelWriter, name string, v interface{}) error {
+	return writeFile(write, name, func(w io.Writer) error {
+		return gob.NewEncoder(w).Encode(v)
+	})
+}
+
+// writeFile creates the file `name` and fills it with `fill`.
+func writeFile(write modelWriter, name string, fill func(w io.Writer) error) error {
+	file, err := write(name)
+	if err != nil {
+		return err
+	}
+	if err = fill(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// countingWriter counts the bytes written to `w`.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -1,12 +1,20 @@
 package prose
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
 	"embed"
+	"encoding/gob"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -34,6 +42,198 @@ func TestModelFromDisk(t *testing.T) {
 	}
 }
 
+func TestModelTaggerRoundTrip(t *testing.T) {
+	tagger, err := NewPerceptronTagger()
+	require.NoError(t, err)
+	require.NoError(t, tagger.Train(readWSJ(t), 10))
+	model, err := ModelFromData("wsj", UsingTagger(tagger))
+	require.NoError(t, err)
+
+	temp := filepath.Join(testdata, "temp")
+	_ = os.RemoveAll(temp)
+	require.NoError(t, model.Write(temp))
+	defer os.RemoveAll(temp)
+
+	loaded, err := ModelFromDisk(temp)
+	require.NoError(t, err)
+	assert.Equal(t, tagger.Classes(), loaded.tagger.Classes())
+
+	text := "Mr. Vinken is chairman of a British industrial group."
+	want, err := NewDocument(text, UsingModel(model), WithExtraction(false))
+	require.NoError(t, err)
+	got, err := NewDocument(text, UsingModel(loaded), WithExtraction(false))
+	require.NoError(t, err)
+	assert.Equal(t, want.Tokens(), got.Tokens())
+}
+
+func TestModelWriteTo(t *testing.T) {
+	model, err := ModelFromDisk(filepath.Join(testdata, "PRODUCT"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	n, err := model.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	// The built-in tagger isn't saved; it's loaded instead.
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	for _, file := range archive.File {
+		assert.False(t, strings.HasPrefix(file.Name, "AveragedPerceptron/"), file.Name)
+	}
+
+	loaded, err := ModelFromReader("PRODUCT", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "PRODUCT", loaded.Name)
+	assert.Equal(t, model.extracter.model.weights, loaded.extracter.model.weights)
+	assert.True(t, loaded.tagger.model.embedded)
+
+	doc, err := NewDocument("Windows 10 is an operating system", UsingModel(loaded))
+	require.NoError(t, err)
+	ents := doc.Entities()
+	require.Len(t, ents, 1)
+	assert.Equal(t, "Windows 10", ents[0].Text)
+
+	_, err = ModelFromReader("PRODUCT", bytes.NewReader([]byte("not a model")))
+	assert.Error(t, err)
+}
+
+func TestModelArchive(t *testing.T) {
+	model, err := ModelFromDisk(filepath.Join(testdata, "PRODUCT"))
+	require.NoError(t, err)
+
+	dir, err := os.MkdirTemp("", "prose")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "PRODUCT.zip")
+	require.NoError(t, model.WriteArchive(path))
+	loaded, err := ModelFromArchive(path)
+	require.NoError(t, err)
+	assert.Equal(t, "PRODUCT", loaded.Name)
+
+	doc, err := NewDocument("Windows 10 is an operating system", UsingModel(loaded))
+	require.NoError(t, err)
+	ents := doc.Entities()
+	require.Len(t, ents, 1)
+	assert.Equal(t, "Windows 10", ents[0].Text)
+
+	// An archive without the NER's weights is refused.
+	partial := filepath.Join(dir, "partial.zip")
+	file, err := os.Create(partial)
+	require.NoError(t, err)
+	archive := zip.NewWriter(file)
+	for _, name := range []string{"Maxent/labels.gob", "Maxent/mapping.gob"} {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		data, err := fs.ReadFile(embeddedModel, "testdata/PRODUCT/"+name)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	require.NoError(t, file.Close())
+
+	_, err = ModelFromArchive(partial)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Maxent/weights.gob")
+	assert.True(t, errors.Is(err, ErrCorruptModel))
+
+	_, err = ModelFromArchive(filepath.Join(dir, "missing.zip"))
+	assert.True(t, errors.Is(err, ErrModelNotFound))
+}
+
+func TestModelFromDataSkipsDefaultNER(t *testing.T) {
+	reads := map[string]int{}
+	defer func(read func(string) ([]byte, error)) { readAsset = read }(readAsset)
+	readAsset = func(name string) ([]byte, error) {
+		reads[name]++
+		return assets.ReadFile(name)
+	}
+
+	data := []EntityContext{{
+		Accept: true,
+		Text:   "Acme Corp hired Jane Doe.",
+		Spans:  []LabeledEntity{{Start: 0, End: 9, Label: "ORG"}}}}
+	model, err := ModelFromData("ORG", UsingEntities(data))
+	require.NoError(t, err)
+	assert.NotNil(t, model.extracter)
+	for name := range reads {
+		assert.NotContains(t, name, "Maxent")
+	}
+
+	// Without a NER from the data, the default one is loaded.
+	model, err = ModelFromData("default")
+	require.NoError(t, err)
+	assert.Contains(t, model.extracter.model.labels, "B-PERSON")
+	assert.Equal(t, 1, reads["model/Maxent/weights.gob"])
+}
+
+func TestCorruptEmbeddedModel(t *testing.T) {
+	defer func(read func(string) ([]byte, error)) { readAsset = read }(readAsset)
+	replace := func(name string, data []byte) {
+		readAsset = func(asset string) ([]byte, error) {
+			if asset == name {
+				return data, nil
+			}
+			return assets.ReadFile(asset)
+		}
+	}
+
+	replace("model/Maxent/weights.gob", []byte("not a gob"))
+	_, err := newEntityExtracter()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrCorruptModel))
+	assert.Contains(t, err.Error(), "Maxent/weights.gob")
+	_, err = ModelFromData("default")
+	assert.True(t, errors.Is(err, ErrCorruptModel))
+
+	// Weights that decode but don't match the features are rejected too.
+	var short bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&short).Encode([]float64{1, 2, 3}))
+	replace("model/Maxent/weights.gob", short.Bytes())
+	_, err = newEntityExtracter()
+	assert.True(t, errors.Is(err, ErrCorruptModel))
+
+	var weights bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&weights).Encode(map[string][]float64{"bias": {1}}))
+	replace("model/AveragedPerceptron/weights-linear.gob", weights.Bytes())
+	_, err = NewPerceptronTagger()
+	assert.True(t, errors.Is(err, ErrCorruptModel))
+}
+
+func TestModelLabels(t *testing.T) {
+	model, err := defaultModel(true, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FACILITY", "GPE", "GSP", "LOCATION", "ORGANIZATION", "PERSON"},
+		model.Labels())
+
+	data := []EntityContext{{
+		Accept: true,
+		Text:   "On May 5, Acme paid $ 40 million.",
+		Spans: []LabeledEntity{
+			{Start: 3, End: 8, Label: "DATE"},
+			{Start: 20, End: 32, Label: "MONEY"}}}}
+	model, err = ModelFromData("onto",
+		UsingEntitiesWithOptions(data, TrainingOptions{Scheme: OntoNotesLabels}))
+	require.NoError(t, err)
+	assert.Len(t, model.Labels(), 18)
+	assert.Contains(t, model.Labels(), "WORK_OF_ART")
+
+	temp := filepath.Join(testdata, "temp")
+	_ = os.RemoveAll(temp)
+	require.NoError(t, model.Write(temp))
+	defer os.RemoveAll(temp)
+	loaded, err := ModelFromDisk(temp)
+	require.NoError(t, err)
+	assert.Equal(t, model.Labels(), loaded.Labels())
+
+	data[0].Spans[0].Label = "HOLIDAY"
+	_, err = ModelFromData("onto",
+		UsingEntitiesWithOptions(data, TrainingOptions{Scheme: OntoNotesLabels}))
+	assert.Error(t, err)
+}
+
 //go:embed testdata/PRODUCT
 var embeddedModel embed.FS
 
@@ -71,3 +271,370 @@ func TestModelFromFS(t *testing.T) {
 		t.Errorf("Expected to tab entity with PRODUCT, got = %v", ents[0].Label)
 	}
 }
+
+func TestModelsFromFS(t *testing.T) {
+	models, err := ModelsFromFS(embeddedModel)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	assert.Equal(t, "PRODUCT", models[0].Name)
+
+	pack := fstest.MapFS{"docs/legal/README.md": &fstest.MapFile{Data: []byte("Not a model.")}}
+	for _, dir := range []string{"packs/legal", "packs/medical/v2/medical"} {
+		for _, name := range []string{"labels.gob", "mapping.gob", "weights.gob"} {
+			data, err := fs.ReadFile(embeddedModel, "testdata/PRODUCT/Maxent/"+name)
+			require.NoError(t, err)
+			pack[dir+"/Maxent/"+name] = &fstest.MapFile{Data: data}
+		}
+	}
+	models, err = ModelsFromFS(pack)
+	require.NoError(t, err)
+	names := []string{}
+	for _, model := range models {
+		names = append(names, model.Name)
+	}
+	assert.ElementsMatch(t, []string{"legal", "medical"}, names)
+
+	pack["other/legal/Maxent/weights.gob"] = pack["packs/legal/Maxent/weights.gob"]
+	_, err = ModelsFromFS(pack)
+	assert.Error(t, err)
+}
+
+func TestModelFromMemory(t *testing.T) {
+	memory := fstest.MapFS{}
+	for _, name := range []string{"labels.gob", "mapping.gob", "weights.gob"} {
+		data, err := fs.ReadFile(embeddedModel, "testdata/PRODUCT/Maxent/"+name)
+		require.NoError(t, err)
+		memory["PRODUCT/Maxent/"+name] = &fstest.MapFile{Data: data}
+	}
+
+	model, err := ModelFromFS("PRODUCT", memory)
+	require.NoError(t, err)
+	doc, err := NewDocument("Windows 10 is an operating system", UsingModel(model))
+	require.NoError(t, err)
+	ents := doc.Entities()
+	require.Len(t, ents, 1)
+	assert.Equal(t, "Windows 10", ents[0].Text)
+}
+
+func TestModelLabelMap(t *testing.T) {
+	model, err := ModelFromFS("PRODUCT", embeddedModel,
+		UsingLabelMap(map[string]string{"PRODUCT": "PROD"}))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"B-PROD", "I-PROD", "O"}, model.extracter.model.labels)
+
+	doc, err := NewDocument("Windows 10 is an operating system", UsingModel(model))
+	require.NoError(t, err)
+
+	ents := doc.Entities()
+	require.Len(t, ents, 1)
+	assert.Equal(t, "Windows 10", ents[0].Text)
+	assert.Equal(t, "PROD", ents[0].Label)
+}
+
+func TestModelMinWeight(t *testing.T) {
+	full, err := ModelFromFS("PRODUCT", embeddedModel)
+	require.NoError(t, err)
+	sparse, err := ModelFromFS("PRODUCT", embeddedModel, UsingMinWeight(0.01))
+	require.NoError(t, err)
+	assert.Less(t, len(sparse.extracter.model.mapping), len(full.extracter.model.mapping))
+	assert.Len(t, sparse.extracter.model.weights, len(sparse.extracter.model.mapping)+1)
+
+	doc, err := NewDocument("Windows 10 is an operating system", UsingModel(sparse))
+	require.NoError(t, err)
+	ents := doc.Entities()
+	require.Len(t, ents, 1)
+	assert.Equal(t, "Windows 10", ents[0].Text)
+}
+
+func TestModelPrune(t *testing.T) {
+	model, err := ModelFromDisk(filepath.Join(testdata, "PRODUCT"))
+	require.NoError(t, err)
+
+	report, err := model.Prune(0.01)
+	require.NoError(t, err)
+	assert.Less(t, report.After, report.Before)
+	assert.Len(t, model.extracter.model.mapping, report.After)
+	assert.Len(t, model.extracter.model.weights, report.After+1)
+
+	doc, err := NewDocument("Windows 10 is an operating system", UsingModel(model))
+	require.NoError(t, err)
+	ents := doc.Entities()
+	require.Len(t, ents, 1)
+	assert.Equal(t, "Windows 10", ents[0].Text)
+}
+
+func TestModelTokenizerFingerprint(t *testing.T) {
+	data := []EntityContext{{
+		Accept: true,
+		Text:   "Acme Corp hired Jane Doe.",
+		Spans:  []LabeledEntity{{Start: 0, End: 9, Label: "ORG"}}}}
+	custom := NewIterTokenizer(UsingDashPolicy(DashPunct))
+
+	model, err := ModelFromData("ORG", UsingEntitiesAndTokenizer(data, custom))
+	require.NoError(t, err)
+
+	temp := filepath.Join(testdata, "temp")
+	_ = os.RemoveAll(temp)
+	require.NoError(t, model.Write(temp))
+	defer os.RemoveAll(temp)
+
+	model, err = ModelFromDisk(temp)
+	require.NoError(t, err)
+
+	doc, err := NewDocument("Acme Corp hired John.", UsingModel(model),
+		UsingTokenizer(NewIterTokenizer(UsingDashPolicy(DashPunct))))
+	require.NoError(t, err)
+	assert.Empty(t, doc.Warnings())
+
+	doc, err = NewDocument("Acme Corp hired John.", UsingModel(model))
+	require.NoError(t, err)
+	assert.Len(t, doc.Warnings(), 1)
+
+	_, err = NewDocument("Acme Corp hired John.", UsingModel(model), WithTokenizerCheck(true))
+	assert.Error(t, err)
+
+	// Models without a fingerprint aren't checked.
+	doc, err = NewDocument("Acme Corp hired John.", UsingTokenizer(custom))
+	require.NoError(t, err)
+	assert.Empty(t, doc.Warnings())
+}
+
+func TestModelManifest(t *testing.T) {
+	data := []EntityContext{{
+		Accept: true,
+		Text:   "On May 5, Acme paid $ 40 million.",
+		Spans: []LabeledEntity{
+			{Start: 3, End: 8, Label: "DATE"},
+			{Start: 20, End: 32, Label: "MONEY"}}}}
+	test := []EntityContext{{Accept: true, Text: "Acme paid on June 1.",
+		Spans: []LabeledEntity{{Start: 13, End: 19, Label: "DATE"}}}}
+
+	model, err := ModelFromData("plain", UsingEntities(data))
+	require.NoError(t, err)
+	_, found := model.Manifest()
+	assert.False(t, found)
+
+	model, err = ModelFromData("audited", UsingEntitiesWithOptions(data, TrainingOptions{
+		Snapshot: true, TestData: test, Sources: []string{"deals-v2.jsonl"}}))
+	require.NoError(t, err)
+	manifest, found := model.Manifest()
+	require.True(t, found)
+	assert.Equal(t, SnapshotData(data), manifest.Training)
+	assert.Equal(t, 1, manifest.Training.Examples)
+	assert.Equal(t, map[string]int{"DATE": 1, "MONEY": 1}, manifest.Training.Labels)
+	require.NotNil(t, manifest.Test)
+	assert.Equal(t, map[string]int{"DATE": 1}, manifest.Test.Labels)
+	assert.Equal(t, []string{"deals-v2.jsonl"}, manifest.Sources)
+
+	temp := filepath.Join(testdata, "temp")
+	_ = os.RemoveAll(temp)
+	require.NoError(t, model.Write(temp))
+	defer os.RemoveAll(temp)
+	loaded, err := ModelFromDisk(temp)
+	require.NoError(t, err)
+	saved, found := loaded.Manifest()
+	require.True(t, found)
+	assert.Equal(t, manifest, saved)
+
+	require.NoError(t, os.WriteFile(filepath.Join(temp, "Maxent", "manifest.json"), []byte("{"), 0644))
+	_, err = ModelFromDisk(temp)
+	assert.True(t, errors.Is(err, ErrCorruptModel), err)
+
+	// Any change to the data changes its hash.
+	data[0].Spans[1].Label = "CARDINAL"
+	assert.NotEqual(t, manifest.Training.Hash, SnapshotData(data).Hash)
+}
+
+// memoryBucket is an ObjectClient that keeps objects in memory.
+type memoryBucket map[string][]byte
+
+func (b memoryBucket) Get(key string) (io.ReadCloser, error) {
+	data, found := b[key]
+	if !found {
+		return nil, fmt.Errorf("no object %s: %w", key, fs.ErrNotExist)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b memoryBucket) Put(key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	b[key] = data
+	return err
+}
+
+func TestModelStores(t *testing.T) {
+	data := []EntityContext{{
+		Accept: true,
+		Text:   "Acme Corp hired Jane Doe.",
+		Spans:  []LabeledEntity{{Start: 0, End: 9, Label: "ORG"}}}}
+	model, err := ModelFromData("ORG", UsingEntities(data))
+	require.NoError(t, err)
+
+	temp := filepath.Join(testdata, "temp")
+	_ = os.RemoveAll(temp)
+	defer os.RemoveAll(temp)
+	bucket := memoryBucket{}
+
+	for name, store := range map[string]ModelStore{
+		"disk":   NewDiskStore(temp),
+		"object": NewObjectStore(bucket, "models/"),
+	} {
+		_, err = ModelFromStore(store, "ORG")
+		assert.True(t, errors.Is(err, ErrModelNotFound), name)
+
+		require.NoError(t, model.WriteToStore(store), name)
+		// Saving again replaces the stored model.
+		require.NoError(t, model.WriteToStore(store), name)
+		if name == "disk" {
+			entries, err := os.ReadDir(temp)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, "ORG", entries[0].Name())
+		}
+		loaded, err := ModelFromStore(store, "ORG")
+		require.NoError(t, err, name)
+		assert.Equal(t, "ORG", loaded.Name, name)
+		assert.Equal(t, model.Labels(), loaded.Labels(), name)
+
+		assert.Error(t, (&Model{}).WriteToStore(store), name)
+	}
+	assert.Contains(t, bucket, "models/ORG.zip")
+	_, err = ModelFromDisk(filepath.Join(temp, "ORG"))
+	assert.NoError(t, err)
+
+	store := NewFSStore(os.DirFS(temp))
+	loaded, err := ModelFromStore(store, "ORG")
+	require.NoError(t, err)
+	assert.Equal(t, model.Labels(), loaded.Labels())
+	_, err = ModelFromStore(store, "PRODUCT")
+	assert.True(t, errors.Is(err, ErrModelNotFound))
+	_, err = ModelFromStore(store, "../ORG")
+	assert.Error(t, err)
+	assert.Error(t, model.WriteToStore(store))
+}
+
+func TestModelWriteReplaces(t *testing.T) {
+	data, err := ReadCoNLL2003(strings.NewReader(conll2003))
+	require.NoError(t, err)
+	features := []string{"bias", "word", "shape", "prevtag", "nextword"}
+	full, err := ModelFromData("model", UsingEntitiesWithOptions(data, TrainingOptions{
+		LabelFeatures: map[string][]string{"PER": features}}))
+	require.NoError(t, err)
+	_, err = full.Distill([]string{"Peter Blackburn visited New York."}, 1)
+	require.NoError(t, err)
+	require.NotEmpty(t, full.extracter.lookup)
+	plain, err := ModelFromData("model", UsingEntities(data))
+	require.NoError(t, err)
+
+	temp := filepath.Join(testdata, "temp")
+	_ = os.RemoveAll(temp)
+	defer os.RemoveAll(temp)
+	require.NoError(t, full.Write(temp))
+	require.FileExists(t, filepath.Join(temp, "Maxent", "lookup.gob"))
+	require.NoError(t, plain.Write(temp))
+
+	// Nothing of the first model is left behind.
+	for _, name := range []string{"lookup.gob", "templates.gob"} {
+		assert.NoFileExists(t, filepath.Join(temp, "Maxent", name))
+	}
+	assert.NoDirExists(t, temp+".tmp")
+	assert.NoDirExists(t, temp+".old")
+	loaded, err := ModelFromDisk(temp)
+	require.NoError(t, err)
+	assert.Nil(t, loaded.extracter.lookup)
+	assert.Nil(t, loaded.extracter.model.templates)
+	assert.Equal(t, plain.extracter.model.weights, loaded.extracter.model.weights)
+}
+
+func TestModelLabelFeatures(t *testing.T) {
+	data, err := ReadCoNLL2003(strings.NewReader(conll2003))
+	require.NoError(t, err)
+	full, err := ModelFromData("full", UsingEntities(data))
+	require.NoError(t, err)
+
+	features := []string{"bias", "word", "shape", "prevtag", "nextword"}
+	model, err := ModelFromData("restricted", UsingEntitiesWithOptions(data, TrainingOptions{
+		LabelFeatures: map[string][]string{"PER": features, "O": features}}))
+	require.NoError(t, err)
+	classifier := model.extracter.model
+	assert.Less(t, len(classifier.mapping), len(full.extracter.model.mapping))
+	for entry := range classifier.mapping {
+		if strings.HasSuffix(entry, "-B-PER") {
+			assert.Contains(t, features, strings.SplitN(entry, "-", 2)[0], entry)
+		}
+	}
+	assert.Len(t, classifier.features("I-PER"), len(features))
+	assert.Len(t, classifier.features("B-ORG"), numFeatures)
+
+	entities := func(m *Model) map[string]string {
+		doc, err := NewDocument("Peter Blackburn visited New York.", UsingModel(m))
+		require.NoError(t, err)
+		labels := map[string]string{}
+		for _, ent := range doc.Entities() {
+			labels[ent.Text] = ent.Label
+		}
+		return labels
+	}
+	assert.Equal(t, "PER", entities(model)["Peter Blackburn"])
+
+	// The templates are saved with the model.
+	var buf bytes.Buffer
+	_, err = model.WriteTo(&buf)
+	require.NoError(t, err)
+	loaded, err := ModelFromReader("restricted", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, classifier.templates, loaded.extracter.model.templates)
+	assert.Equal(t, entities(model), entities(loaded))
+
+	// Relabeling keeps each label's templates, even when labels are swapped.
+	model, err = ModelFromData("swapped", UsingEntitiesWithOptions(data, TrainingOptions{
+		LabelFeatures: map[string][]string{"PER": features, "LOC": {"bias", "word"}}}))
+	require.NoError(t, err)
+	buf.Reset()
+	_, err = model.WriteTo(&buf)
+	require.NoError(t, err)
+	swapped, err := ModelFromReader("swapped", &buf,
+		UsingLabelMap(map[string]string{"PER": "LOC", "LOC": "PER"}))
+	require.NoError(t, err)
+	templates := model.extracter.model.templates
+	assert.Equal(t, templates["B-PER"], swapped.extracter.model.templates["B-LOC"])
+	assert.Equal(t, templates["B-LOC"], swapped.extracter.model.templates["B-PER"])
+
+	_, err = ModelFromData("invalid", UsingEntitiesWithOptions(data, TrainingOptions{
+		LabelFeatures: map[string][]string{"PER": {"word", "colour"}}}))
+	assert.Error(t, err)
+}
+
+func TestTrainingProgress(t *testing.T) {
+	data, err := ReadCoNLL2003(strings.NewReader(conll2003))
+	require.NoError(t, err)
+
+	iterations, likelihoods := []int{}, []float64{}
+	opts := TrainingOptions{Iterations: 10, Progress: func(iteration int, logLikelihood float64) {
+		iterations = append(iterations, iteration)
+		likelihoods = append(likelihoods, logLikelihood)
+	}}
+	_, err = ModelFromData("progress", UsingEntitiesWithOptions(data, opts))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, iterations)
+	for i, ll := range likelihoods {
+		assert.True(t, ll <= 0, "log-likelihood %f", ll)
+		if i > 0 {
+			assert.True(t, ll >= likelihoods[i-1]-1e-9, "iteration %d: %f < %f", i, ll, likelihoods[i-1])
+		}
+	}
+	assert.True(t, likelihoods[9] > likelihoods[0])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	opts = TrainingOptions{Iterations: 10, Context: ctx, Progress: func(iteration int, _ float64) {
+		calls++
+		if iteration == 2 {
+			cancel()
+		}
+	}}
+	_, err = ModelFromData("canceled", UsingEntitiesWithOptions(data, opts))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,231 @@
+package prose
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// An OffsetMap records how byte offsets in a transformed text correspond to
+// byte offsets in the text it was derived from.
+type OffsetMap struct {
+	edits []offsetEdit
+	prev  *OffsetMap // The map for an earlier transformation, if any.
+}
+
+// offsetEdit records that text[start:end] replaced original[origStart:origEnd].
+type offsetEdit struct {
+	start, end         int
+	origStart, origEnd int
+}
+
+// Original maps a byte offset in the transformed text to the corresponding
+// byte offset in the original text.
+//
+// Offsets that fall inside a replaced sequence map to the end of the
+// original sequence.
+func (m *OffsetMap) Original(offset int) int {
+	if m == nil {
+		return offset
+	}
+	return m.prev.Original(m.original(offset))
+}
+
+func (m *OffsetMap) original(offset int) int {
+	i := sort.Search(len(m.edits), func(i int) bool {
+		return m.edits[i].start > offset
+	}) - 1
+	if i < 0 {
+		return offset
+	}
+	edit := m.edits[i]
+	if offset == edit.start {
+		return edit.origStart
+	} else if offset < edit.end {
+		return edit.origEnd
+	}
+	return edit.origEnd + offset - edit.end
+}
+
+// Transformed maps a byte offset in the original text to the corresponding
+// byte offset in the transformed text.
+func (m *OffsetMap) Transformed(offset int) int {
+	if m == nil {
+		return offset
+	}
+	return m.transformed(m.prev.Transformed(offset))
+}
+
+func (m *OffsetMap) transformed(offset int) int {
+	i := sort.Search(len(m.edits), func(i int) bool {
+		return m.edits[i].origStart > offset
+	}) - 1
+	if i < 0 {
+		return offset
+	}
+	edit := m.edits[i]
+	if offset == edit.origStart {
+		return edit.start
+	} else if offset < edit.origEnd {
+		return edit.end
+	}
+	return edit.end + offset - edit.origEnd
+}
+
+// chainOffsets combines the maps of two successive transformations into one.
+func chainOffsets(first, second *OffsetMap) *OffsetMap {
+	if first == nil {
+		return second
+	} else if second == nil {
+		return first
+	}
+	second.prev = first
+	return second
+}
+
+// offsetBuilder incrementally builds a transformed text and its OffsetMap.
+type offsetBuilder struct {
+	text  strings.Builder
+	edits []offsetEdit
+}
+
+// keep copies `s` from the original text unchanged.
+func (b *offsetBuilder) keep(s string) {
+	b.text.WriteString(s)
+}
+
+// replace writes `s` in place of `orig`, found at `at` in the original text.
+func (b *offsetBuilder) replace(s, orig string, at int) {
+	start := b.text.Len()
+	b.text.WriteString(s)
+	if s == orig {
+		return
+	}
+	b.edits = append(b.edits, offsetEdit{
+		start: start, end: b.text.Len(),
+		origStart: at, origEnd: at + len(orig)})
+}
+
+func (b *offsetBuilder) result() (string, *OffsetMap) {
+	return b.text.String(), &OffsetMap{edits: b.edits}
+}
+
+// NormalizeConfusables maps Unicode characters that are visually confusable
+// with ASCII ("homoglyphs") to their ASCII counterparts, returning the
+// normalized text and an OffsetMap back to `text`.
+//
+// Fullwidth forms are always mapped. Cyrillic and Greek look-alikes are only
+// mapped inside words that also contain Latin letters, so genuine Cyrillic
+// or Greek text is left untouched.
+func NormalizeConfusables(text string) (string, *OffsetMap) {
+	var b offsetBuilder
+
+	mixed := false
+	for i, r := range text {
+		if i == 0 || !isWordRune(prevRune(text, i)) {
+			mixed = isMixedScriptWord(text[i:])
+		}
+		size := utf8.RuneLen(r)
+		orig := text[i : i+size]
+		if r >= 0xFF01 && r <= 0xFF5E {
+			b.replace(string(r-0xFEE0), orig, i)
+		} else if r == 0x3000 {
+			b.replace(" ", orig, i)
+		} else if ascii, found := confusables[r]; found && mixed {
+			b.replace(string(ascii), orig, i)
+		} else {
+			b.keep(orig)
+		}
+	}
+
+	return b.result()
+}
+
+// A UnicodeForm identifies a Unicode normalization form.
+type UnicodeForm int
+
+const (
+	// UnicodeNone leaves text as-is (the default).
+	UnicodeNone UnicodeForm = iota
+	// UnicodeNFC composes letters and combining marks into precomposed
+	// characters: "e\u0301" -> "é".
+	UnicodeNFC
+	// UnicodeNFKC additionally maps compatibility characters, such as
+	// ligatures and fullwidth forms, to their plain equivalents: "ﬁ" -> "fi".
+	UnicodeNFKC
+)
+
+// NormalizeUnicode converts `text` to the given normalization form,
+// returning the normalized text and an OffsetMap back to `text`.
+//
+// Normalization follows the Unicode standard (see golang.org/x/text/unicode/norm),
+// one normalization segment (a starter and the marks combined with it) at a
+// time, so that each segment maps back to its original bytes.
+func NormalizeUnicode(text string, form UnicodeForm) (string, *OffsetMap) {
+	var b offsetBuilder
+	if form == UnicodeNone {
+		b.keep(text)
+		return b.result()
+	}
+
+	nf := norm.NFC
+	if form == UnicodeNFKC {
+		nf = norm.NFKC
+	}
+	var iter norm.Iter
+	iter.InitString(nf, text)
+	out, start := []byte{}, 0
+	for !iter.Done() {
+		// A long decomposition (e.g., of a ligature) can span several
+		// segments that don't advance through `text`.
+		out = append(out, iter.Next()...)
+		if end := iter.Pos(); end > start {
+			b.replace(string(out), text[start:end], start)
+			out, start = out[:0], end
+		}
+	}
+
+	return b.result()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func prevRune(text string, i int) rune {
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return r
+}
+
+// isMixedScriptWord determines if the word at the start of `text` mixes
+// Latin letters with Cyrillic or Greek look-alikes.
+func isMixedScriptWord(text string) bool {
+	latin, confusable := false, false
+	for _, r := range text {
+		if !isWordRune(r) {
+			break
+		}
+		if _, found := confusables[r]; found {
+			confusable = true
+		} else if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			latin = true
+		}
+	}
+	return latin && confusable
+}
+
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x',
+	'і': 'i', 'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'ӏ': 'l', 'һ': 'h', 'ԛ': 'q',
+	'ԝ': 'w', 'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H',
+	'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'Х': 'X', 'У': 'Y', 'І': 'I',
+	'Ј': 'J', 'Ѕ': 'S', 'Ԛ': 'Q', 'Ԝ': 'W',
+	// Greek
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K',
+	'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+	'ο': 'o', 'ν': 'v', 'ι': 'i',
+}
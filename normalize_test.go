@@ -0,0 +1,90 @@
+package prose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeConfusables(t *testing.T) {
+	// "Аpple" starts with a Cyrillic A; "Москва" is genuine Cyrillic.
+	text := "Аpple Inc. ｖｓ Москва"
+	normalized, offsets := NormalizeConfusables(text)
+	assert.Equal(t, "Apple Inc. vs Москва", normalized)
+
+	for _, word := range []string{"Apple", "Inc.", "vs", "Москва"} {
+		start := strings.Index(normalized, word)
+		end := start + len(word)
+		orig := text[offsets.Original(start):offsets.Original(end)]
+		assert.Equal(t, len([]rune(word)), len([]rune(orig)), word)
+		assert.Equal(t, start, offsets.Transformed(offsets.Original(start)))
+	}
+}
+
+func TestConfusableDocument(t *testing.T) {
+	doc, err := NewDocument("Wе mеt Bаrаck Obаmа in Pаris.",
+		WithConfusableNormalization(true))
+	require.NoError(t, err)
+	assert.Equal(t, "Wе mеt Bаrаck Obаmа in Pаris.", doc.Text)
+	assert.NotNil(t, doc.OffsetMap())
+
+	// Entities are recognized in the normalized text, but their Text is
+	// exactly as written; their tokens hold the normalized form.
+	ents, normalized := []string{}, []string{}
+	for i, ent := range doc.Entities() {
+		ents = append(ents, ent.Text)
+		normalized = append(normalized, JoinTokens(doc.entParts[i]))
+	}
+	assert.Equal(t, []string{"Bаrаck Obаmа", "Pаris"}, ents)
+	assert.Equal(t, []string{"Barack Obama", "Paris"}, normalized)
+}
+
+func TestNormalizeUnicode(t *testing.T) {
+	text := "Cafe\u0301 ﬁnance Ａ1 nai\u0308ve"
+
+	nfc, offsets := NormalizeUnicode(text, UnicodeNFC)
+	assert.Equal(t, "Caf\u00e9 ﬁnance Ａ1 na\u00efve", nfc)
+	start := strings.Index(nfc, "ﬁnance")
+	assert.Equal(t, strings.Index(text, "ﬁnance"), offsets.Original(start))
+
+	nfkc, offsets := NormalizeUnicode(text, UnicodeNFKC)
+	assert.Equal(t, "Caf\u00e9 finance A1 na\u00efve", nfkc)
+	for _, word := range []string{"Caf\u00e9", "finance", "A1", "na\u00efve"} {
+		start := strings.Index(nfkc, word)
+		assert.Equal(t, start, offsets.Transformed(offsets.Original(start)), word)
+	}
+	assert.Equal(t, len(text), offsets.Original(len(nfkc)))
+
+	// Marks are reordered canonically before composition, and scripts other
+	// than Latin (here, Hangul jamo) are composed too.
+	nfc, _ = NormalizeUnicode("a\u0323\u0302 a\u0302\u0323 \u1100\u1161", UnicodeNFC)
+	assert.Equal(t, "\u1ead \u1ead \uac00", nfc)
+	nfkc, offsets = NormalizeUnicode("\u2460 \uff76 x\u00b2", UnicodeNFKC)
+	assert.Equal(t, "1 \u30ab x2", nfkc)
+	assert.Equal(t, strings.Index("\u2460 \uff76 x\u00b2", "x"), offsets.Original(strings.Index(nfkc, "x")))
+
+	same, offsets := NormalizeUnicode(text, UnicodeNone)
+	assert.Equal(t, text, same)
+	assert.Equal(t, 5, offsets.Original(5))
+}
+
+func TestUnicodeDocument(t *testing.T) {
+	text := "Rene\u0301e Zellweger met ﬁve people in Ｐａｒｉｓ."
+	doc, err := NewDocument(text,
+		WithUnicodeNormalization(UnicodeNFKC),
+		WithConfusableNormalization(true))
+	require.NoError(t, err)
+	assert.Equal(t, text, doc.Text)
+
+	tokens := []string{}
+	for _, tok := range doc.Tokens() {
+		tokens = append(tokens, tok.Text)
+	}
+	assert.Equal(t, []string{
+		"Ren\u00e9e", "Zellweger", "met", "five", "people", "in", "Paris", "."}, tokens)
+
+	end := strings.Index(text, ".")
+	assert.Equal(t, end, doc.OffsetMap().Original(strings.Index("Ren\u00e9e Zellweger met five people in Paris.", ".")))
+}
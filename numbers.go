@@ -0,0 +1,126 @@
+package prose
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Number represents the structure of a numeric expression such as "1,200",
+// "3rd", "1 1/2", "$5", or "10km".
+type Number struct {
+	Text      string  // The expression's actual content.
+	Value     float64 // The expression's numeric value.
+	Unit      string  // A currency symbol or unit (e.g., "$", "%", "km"), if any.
+	Ordinal   bool    // If true, the number is an ordinal (e.g., "3rd").
+	Fraction  bool    // If true, the number contains a fraction (e.g., "1/2").
+	Thousands bool    // If true, the number uses thousands separators.
+}
+
+var reNumber = regexp.MustCompile(
+	`^([+-]?)(\d{1,3}(?:,\d{3})+|\d+)?(\.\d+)?(?:/(\d+))?(st|nd|rd|th)?(%|[A-Za-z]{1,3})?$`)
+
+// ParseNumber parses a single numeric token (e.g., "1,200.50", "2nd", "3/4",
+// or "10km"), reporting false if `text` isn't numeric.
+func ParseNumber(text string) (Number, bool) {
+	m := reNumber.FindStringSubmatch(text)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return Number{}, false
+	}
+	sign, whole, decimal, denom, ordinal, unit := m[1], m[2], m[3], m[4], m[5], m[6]
+
+	num := Number{Text: text, Unit: unit}
+	num.Thousands = strings.Contains(whole, ",")
+
+	value, err := strconv.ParseFloat(strings.Replace(whole, ",", "", -1)+decimal, 64)
+	if err != nil {
+		return Number{}, false
+	}
+	if denom != "" {
+		d, err := strconv.ParseFloat(denom, 64)
+		if err != nil || d == 0 || decimal != "" || num.Thousands {
+			return Number{}, false
+		}
+		value /= d
+		num.Fraction = true
+	}
+	if ordinal != "" {
+		if decimal != "" || denom != "" || !validOrdinal(whole, ordinal) {
+			return Number{}, false
+		}
+		num.Ordinal = true
+	}
+	if sign == "-" {
+		value = -value
+	}
+
+	num.Value = value
+	return num, true
+}
+
+// validOrdinal determines if `suffix` is the correct ordinal suffix for the
+// integer `whole` (e.g., 1st, 12th, 22nd).
+func validOrdinal(whole, suffix string) bool {
+	n := len(whole)
+	if n == 0 {
+		return false
+	}
+	last := whole[n-1]
+	teen := n > 1 && whole[n-2] == '1'
+	switch {
+	case teen:
+		return suffix == "th"
+	case last == '1':
+		return suffix == "st"
+	case last == '2':
+		return suffix == "nd"
+	case last == '3':
+		return suffix == "rd"
+	}
+	return suffix == "th"
+}
+
+// Number parses the token as a numeric expression (see ParseNumber).
+func (t *Token) Number() (Number, bool) {
+	return ParseNumber(t.Text)
+}
+
+// Numbers returns the numeric expressions in `doc`, combining tokens that
+// belong together: a currency symbol and its amount ("$" "5"), or a whole
+// number and a fraction ("1" "1/2").
+func (doc *Document) Numbers() []Number {
+	numbers := []Number{}
+	for i := 0; i < len(doc.tokens); i++ {
+		num, ok := doc.tokens[i].Number()
+		if !ok {
+			continue
+		}
+
+		if i > 0 && num.Unit == "" && isCurrency(doc.tokens[i-1].Text) {
+			num.Unit = doc.tokens[i-1].Text
+			num.Text = num.Unit + num.Text
+		}
+
+		if i+1 < len(doc.tokens) && !num.Fraction && !num.Ordinal && num.Unit == "" {
+			next, ok := doc.tokens[i+1].Number()
+			if ok && next.Fraction && next.Value > 0 && next.Value < 1 {
+				if num.Value < 0 {
+					num.Value -= next.Value
+				} else {
+					num.Value += next.Value
+				}
+				num.Text = num.Text + " " + next.Text
+				num.Fraction = true
+				num.Unit = next.Unit
+				i++
+			}
+		}
+
+		numbers = append(numbers, num)
+	}
+	return numbers
+}
+
+func isCurrency(s string) bool {
+	return stringInSlice(s, []string{"$", "€", "£", "¥"})
+}
@@ -0,0 +1,48 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumber(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected Number
+	}{
+		{"42", Number{Value: 42}},
+		{"-3.5", Number{Value: -3.5}},
+		{"1,200,000", Number{Value: 1200000, Thousands: true}},
+		{"3/4", Number{Value: 0.75, Fraction: true}},
+		{"22nd", Number{Value: 22, Ordinal: true}},
+		{"11th", Number{Value: 11, Ordinal: true}},
+		{"10km", Number{Value: 10, Unit: "km"}},
+		{"15%", Number{Value: 15, Unit: "%"}},
+	}
+	for _, c := range cases {
+		num, ok := ParseNumber(c.text)
+		require.True(t, ok, c.text)
+		c.expected.Text = c.text
+		assert.Equal(t, c.expected, num)
+	}
+
+	for _, text := range []string{"", "abc", "1,20", "2st", "1/0", "km"} {
+		_, ok := ParseNumber(text)
+		assert.False(t, ok, text)
+	}
+}
+
+func TestDocumentNumbers(t *testing.T) {
+	doc, err := NewDocument("Add 1 1/2 cups, pay $5 and finish 3rd.")
+	require.NoError(t, err)
+
+	texts, values := []string{}, []float64{}
+	for _, num := range doc.Numbers() {
+		texts = append(texts, num.Text)
+		values = append(values, num.Value)
+	}
+	assert.Equal(t, []string{"1 1/2", "$5", "3rd"}, texts)
+	assert.Equal(t, []float64{1.5, 5, 3}, values)
+}
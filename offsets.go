@@ -0,0 +1,75 @@
+package prose
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A StreamMapper maps between character (rune) offsets in raw text and
+// positions in the text's whitespace-free character stream, which is how
+// token sequences relate to the text they came from. (An OffsetMap, by
+// contrast, maps byte offsets in normalized text back to the original.)
+//
+// For example, in "a  bc", the raw offset 3 ("b") is stream position 1.
+type StreamMapper struct {
+	before []int // before[i] is the number of non-space runes before rune i.
+	raw    []int // raw[j] is the rune offset of the j-th non-space rune.
+}
+
+// NewStreamMapper creates a StreamMapper for `text`.
+func NewStreamMapper(text string) *StreamMapper {
+	runes := []rune(text)
+	m := &StreamMapper{before: make([]int, len(runes)+1)}
+	for i, r := range runes {
+		m.before[i+1] = m.before[i]
+		if !unicode.IsSpace(r) {
+			m.before[i+1]++
+			m.raw = append(m.raw, i)
+		}
+	}
+	return m
+}
+
+// ToStream maps the raw offset `raw` to a stream position; offsets beyond
+// the end of the text are clamped to it.
+func (m *StreamMapper) ToStream(raw int) int {
+	if raw < 0 {
+		return 0
+	} else if raw >= len(m.before) {
+		return m.before[len(m.before)-1]
+	}
+	return m.before[raw]
+}
+
+// ToRaw maps the stream position `stream` to the raw offset of the
+// character found there; positions beyond the end of the stream map to the
+// end of the text.
+func (m *StreamMapper) ToRaw(stream int) int {
+	if stream < 0 {
+		return 0
+	} else if stream >= len(m.raw) {
+		return len(m.before) - 1
+	}
+	return m.raw[stream]
+}
+
+// JoinTokens merges `tokens` back into a span of text, separating adjacent
+// tokens with a space only if they were separated in the input (according
+// to their offsets). Tokens without offsets are separated by a space.
+func JoinTokens(tokens []*Token) string {
+	var b strings.Builder
+	for i, tok := range tokens {
+		if i > 0 {
+			prev := tokens[i-1]
+			if !hasOffsets(prev) || !hasOffsets(tok) || tok.Start > prev.End {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(tok.Text)
+	}
+	return b.String()
+}
+
+func hasOffsets(tok *Token) bool {
+	return tok.End > tok.Start
+}
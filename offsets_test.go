@@ -0,0 +1,38 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamMapper(t *testing.T) {
+	m := NewStreamMapper("a  bç\td")
+
+	assert.Equal(t, 0, m.ToStream(0))
+	assert.Equal(t, 1, m.ToStream(2))
+	assert.Equal(t, 1, m.ToStream(3))
+	assert.Equal(t, 3, m.ToStream(6))
+	assert.Equal(t, 4, m.ToStream(7))
+	assert.Equal(t, 4, m.ToStream(100))
+
+	assert.Equal(t, 0, m.ToRaw(0))
+	assert.Equal(t, 3, m.ToRaw(1))
+	assert.Equal(t, 4, m.ToRaw(2))
+	assert.Equal(t, 6, m.ToRaw(3))
+	assert.Equal(t, 7, m.ToRaw(4))
+
+	for raw := 0; raw < 7; raw++ {
+		assert.LessOrEqual(t, raw, m.ToRaw(m.ToStream(raw)))
+	}
+}
+
+func TestJoinTokens(t *testing.T) {
+	tokens := NewIterTokenizer().Tokenize("Mr. O'Brien- (the U.S. envoy) said")
+	assert.Equal(t, "Mr. O'Brien- (the U.S. envoy) said", JoinTokens(tokens))
+	assert.Equal(t, "(the", JoinTokens(tokens[2:4]))
+
+	// Without offsets, tokens are separated by spaces.
+	assert.Equal(t, "a b", JoinTokens([]*Token{{Text: "a"}, {Text: "b"}}))
+	assert.Equal(t, "", JoinTokens(nil))
+}
@@ -0,0 +1,227 @@
+package prose
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// A Pipeline creates Documents with a fixed model and configuration.
+type Pipeline struct {
+	Model  *Model // The model to use; the default model if nil.
+	Config Config
+}
+
+// NewDocument creates a Document using `p`'s model and configuration;
+// `opts` adjust the configuration further.
+func (p *Pipeline) NewDocument(text string, opts ...DocOpt) (*Document, error) {
+	return NewDocument(text, append([]DocOpt{UsingConfig(p.Config), UsingModel(p.Model)}, opts...)...)
+}
+
+// A PipelineSpec describes a Pipeline in a configuration file; unset fields
+// keep their defaults.
+type PipelineSpec struct {
+	Preset    string            `json:"preset"`    // See Preset.
+	Model     string            `json:"model"`     // A model directory, as for ModelFromDisk.
+	LabelMap  map[string]string `json:"labelMap"`  // See UsingLabelMap.
+	MinWeight float64           `json:"minWeight"` // See UsingMinWeight.
+
+	Segment   *bool `json:"segment"`
+	Tag       *bool `json:"tag"`
+	Extract   *bool `json:"extract"`
+	Sentiment *bool `json:"sentiment"`
+	ListItems *bool `json:"listItems"`
+
+	Tokenizer struct {
+		Cache     string `json:"cache"` // "per-call", "off", or "shared".
+		CacheSize int    `json:"cacheSize"`
+		Dashes    string `json:"dashes"` // "joiner", "separator", or "punct".
+	} `json:"tokenizer"`
+	Segmenter string `json:"segmenter"` // "punkt" or "rule".
+
+	Guard           string   `json:"guard"`   // "ignore", "replace", "skip", or "error".
+	Unicode         string   `json:"unicode"` // "none", "nfc", or "nfkc".
+	Confusables     *bool    `json:"confusables"`
+	Tables          string   `json:"tables"` // "ignore", "detect", "skip", or "rows".
+	Caps            string   `json:"caps"`   // "ignore" or "truecase".
+	Trim            []string `json:"trim"`   // Any of "punctuation" and "determiners".
+	Boundaries      string   `json:"boundaries"`
+	MinConfidence   float64  `json:"minConfidence"` // See WithMinConfidence.
+	Consistency     string   `json:"consistency"`   // "off", "majority", or "first".
+	BlockContext    *bool    `json:"blockContext"`
+	StrictTokenizer *bool    `json:"strictTokenizer"`
+}
+
+// PipelineFromConfig builds a Pipeline from a JSON PipelineSpec read from
+// `r`. For example,
+//
+//	{
+//	  "preset": "fast",
+//	  "model": "/models/contracts",
+//	  "sentiment": true,
+//	  "tokenizer": {"cache": "shared"},
+//	  "trim": ["punctuation", "determiners"]
+//	}
+//
+// Unknown fields and values are errors.
+func PipelineFromConfig(r io.Reader) (*Pipeline, error) {
+	var spec PipelineSpec
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unable to read config: %w", err)
+	}
+	return spec.Pipeline()
+}
+
+// Pipeline builds the Pipeline described by `spec`.
+func (spec PipelineSpec) Pipeline() (*Pipeline, error) {
+	p := &Pipeline{Config: DefaultConfig()}
+	if spec.Preset != "" {
+		Preset(spec.Preset)(nil, &p.Config)
+	}
+
+	setBool(&p.Config.Segment, spec.Segment)
+	setBool(&p.Config.Tag, spec.Tag)
+	setBool(&p.Config.Extract, spec.Extract)
+	setBool(&p.Config.Sentiment, spec.Sentiment)
+	setBool(&p.Config.ListItems, spec.ListItems)
+	setBool(&p.Config.Confusables, spec.Confusables)
+	setBool(&p.Config.BlockContext, spec.BlockContext)
+	setBool(&p.Config.StrictTokenizer, spec.StrictTokenizer)
+
+	var err error
+	var value int
+
+	tokOpts := []TokenizerOptFunc{}
+	if value, err = lookupName("cache", spec.Tokenizer.Cache, cacheNames); err != nil {
+		return nil, err
+	} else if value >= 0 {
+		tokOpts = append(tokOpts, UsingCacheStrategy(CacheStrategy(value)))
+	}
+	if spec.Tokenizer.CacheSize > 0 {
+		tokOpts = append(tokOpts, UsingCacheSize(spec.Tokenizer.CacheSize))
+	}
+	if value, err = lookupName("dashes", spec.Tokenizer.Dashes, dashNames); err != nil {
+		return nil, err
+	} else if value >= 0 {
+		tokOpts = append(tokOpts, UsingDashPolicy(DashPolicy(value)))
+	}
+	if len(tokOpts) > 0 {
+		p.Config.Tokenizer = NewIterTokenizer(tokOpts...)
+	}
+
+	switch spec.Segmenter {
+	case "":
+	case "punkt":
+		p.Config.SentenceTokenizer = nil
+	case "rule":
+		p.Config.SentenceTokenizer = NewRuleSentenceTokenizer()
+	default:
+		return nil, fmt.Errorf("unknown segmenter %q", spec.Segmenter)
+	}
+
+	if value, err = lookupName("guard", spec.Guard, guardNames); err != nil {
+		return nil, err
+	} else if value >= 0 {
+		p.Config.Guard = InputPolicy(value)
+	}
+	if value, err = lookupName("unicode", spec.Unicode, unicodeNames); err != nil {
+		return nil, err
+	} else if value >= 0 {
+		p.Config.Unicode = UnicodeForm(value)
+	}
+	if value, err = lookupName("tables", spec.Tables, tableNames); err != nil {
+		return nil, err
+	} else if value >= 0 {
+		p.Config.Tables = TablePolicy(value)
+	}
+	if value, err = lookupName("caps", spec.Caps, capsNames); err != nil {
+		return nil, err
+	} else if value >= 0 {
+		p.Config.Caps = CapsPolicy(value)
+	}
+	if value, err = lookupName("consistency", spec.Consistency, consistencyNames); err != nil {
+		return nil, err
+	} else if value >= 0 {
+		p.Config.Consistency = ConsistencyPolicy(value)
+	}
+	for _, name := range spec.Trim {
+		if value, err = lookupName("trim", name, trimNames); err != nil {
+			return nil, err
+		}
+		p.Config.Trim |= EntityTrim(value)
+	}
+
+	if spec.MinConfidence != 0 {
+		p.Config.MinConfidence = spec.MinConfidence
+	}
+	if spec.Boundaries != "" {
+		p.Config.Boundaries, err = regexp.Compile(spec.Boundaries)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile boundaries: %w", err)
+		}
+	}
+
+	if err = p.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if spec.Model != "" {
+		p.Model, err = ModelFromDisk(spec.Model,
+			UsingLabelMap(spec.LabelMap), UsingMinWeight(spec.MinWeight))
+		if err != nil {
+			return nil, fmt.Errorf("unable to load model: %w", err)
+		}
+	} else if len(spec.LabelMap) > 0 || spec.MinWeight != 0 {
+		return nil, errors.New("invalid config: labelMap and minWeight require a model")
+	}
+
+	return p, nil
+}
+
+func setBool(dst *bool, src *bool) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// lookupName returns the value of `name` among the choices for `field`, or
+// -1 if `name` is empty.
+func lookupName(field, name string, choices map[string]int) (int, error) {
+	if name == "" {
+		return -1, nil
+	} else if value, found := choices[name]; found {
+		return value, nil
+	}
+	return -1, fmt.Errorf("unknown %s %q", field, name)
+}
+
+var cacheNames = map[string]int{
+	"per-call": int(CachePerCall), "off": int(CacheOff), "shared": int(CacheShared)}
+
+var dashNames = map[string]int{
+	"joiner": int(DashJoiner), "separator": int(DashSeparator), "punct": int(DashPunct)}
+
+var guardNames = map[string]int{
+	"ignore": int(PolicyIgnore), "replace": int(PolicyReplace),
+	"skip": int(PolicySkip), "error": int(PolicyError)}
+
+var unicodeNames = map[string]int{
+	"none": int(UnicodeNone), "nfc": int(UnicodeNFC), "nfkc": int(UnicodeNFKC)}
+
+var tableNames = map[string]int{
+	"ignore": int(TablesIgnore), "detect": int(TablesDetect),
+	"skip": int(TablesSkip), "rows": int(TablesRows)}
+
+var capsNames = map[string]int{
+	"ignore": int(CapsIgnore), "truecase": int(CapsTruecase)}
+
+var consistencyNames = map[string]int{
+	"off": int(ConsistencyOff), "majority": int(ConsistencyMajority), "first": int(ConsistencyFirst)}
+
+var trimNames = map[string]int{
+	"punctuation": int(TrimPunctuation), "determiners": int(TrimDeterminers)}
@@ -0,0 +1,55 @@
+package prose
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineFromConfig(t *testing.T) {
+	p, err := PipelineFromConfig(strings.NewReader(`{
+  "preset": "fast",
+  "extract": false,
+  "sentiment": true,
+  "tokenizer": {"dashes": "punct"},
+  "trim": ["punctuation", "determiners"],
+  "boundaries": "-{3,}",
+  "minConfidence": 0.6
+}`))
+	require.NoError(t, err)
+	assert.False(t, p.Config.Extract)
+	assert.True(t, p.Config.Sentiment)
+	assert.Equal(t, TrimPunctuation|TrimDeterminers, p.Config.Trim)
+	assert.NotNil(t, p.Config.SentenceTokenizer)
+	assert.Equal(t, 0.6, p.Config.MinConfidence)
+
+	doc, err := p.NewDocument("It was great—really. --- Next record.")
+	require.NoError(t, err)
+	assert.Len(t, doc.Sentences(), 2)
+	assert.Empty(t, doc.Entities())
+	assert.Greater(t, doc.Sentiment().Polarity, 0.0)
+	assert.Equal(t, "—", doc.Tokens()[3].Text)
+
+	p, err = PipelineFromConfig(strings.NewReader(
+		`{"model": "` + filepath.Join(testdata, "PRODUCT") + `", "tag": true}`))
+	require.NoError(t, err)
+	assert.Equal(t, "PRODUCT", p.Model.Name)
+
+	for _, config := range []string{
+		`{"preset": "slow"}`,
+		`{"tables": "sometimes"}`,
+		`{"trim": ["commas"]}`,
+		`{"segmenter": "magic"}`,
+		`{"extrct": false}`,
+		`{"boundaries": "("}`,
+		`{"labelMap": {"PRODUCT": "ITEM"}}`,
+		`{"minConfidence": 2}`,
+		"preset: fast",
+	} {
+		_, err = PipelineFromConfig(strings.NewReader(config))
+		assert.Error(t, err, config)
+	}
+}
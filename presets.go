@@ -0,0 +1,49 @@
+package prose
+
+import "fmt"
+
+// The names of the pipeline presets available via Preset.
+const (
+	// PresetMinimal only tokenizes, so no model is loaded.
+	PresetMinimal = "minimal"
+	// PresetFast runs the full pipeline with a rule-based segmenter and a
+	// shared tokenizer cache, trading some accuracy for speed.
+	PresetFast = "fast"
+	// PresetAccurate runs the full pipeline with the punkt segmenter and
+	// normalizes the input (NFKC and homoglyphs) first.
+	PresetAccurate = "accurate"
+)
+
+// fastTokenizer is shared by all documents using PresetFast, so its cache
+// persists across them.
+var fastTokenizer = NewIterTokenizer(UsingCacheStrategy(CacheShared))
+
+// Preset applies a named bundle of options ("minimal", "fast", or
+// "accurate") with sensible trade-offs; options given after it override its
+// choices. NewDocument fails if `name` isn't a known preset.
+//
+// The presets only choose the pipeline's stages, segmenter, tokenizer cache,
+// and input normalization. They leave WithMinConfidence at its default (all
+// entities are kept), and they don't change how models are loaded: as
+// always, the default model is only loaded for the stages that need it.
+func Preset(name string) DocOpt {
+	return func(doc *Document, opts *Config) {
+		switch name {
+		case PresetMinimal:
+			opts.Segment = false
+			opts.Tag = false
+			opts.Extract = false
+		case PresetFast:
+			opts.Tokenizer = fastTokenizer
+			opts.SentenceTokenizer = NewRuleSentenceTokenizer()
+			opts.Segment = true
+		case PresetAccurate:
+			opts.SentenceTokenizer = nil
+			opts.Segment = true
+			opts.Unicode = UnicodeNFKC
+			opts.Confusables = true
+		default:
+			opts.err = fmt.Errorf("unknown preset %q", name)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package prose
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NewDocumentFromTokens creates a Document from text that has already been
+// segmented into `sentences` and tokenized. The tokens are tagged and
+// classified as-is (see UsingTokens for how their Tag and Label fields are
+// treated), so options that segment or tokenize text don't apply; the
+// others, such as UsingModel or WithTagging, do.
+//
+// Either every token has offsets into `text` (End > Start), from which the
+// sentences and entities take their text, or none does. The input isn't
+// modified.
+func NewDocumentFromTokens(text string, sentences [][]*Token, opts ...DocOpt) (*Document, error) {
+	tokens, sents, err := flattenSentences(text, sentences)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tokens: %w", err)
+	}
+	opts = append(opts[:len(opts):len(opts)], UsingTokens(tokens), usingSentences(sents))
+	return NewDocument(text, opts...)
+}
+
+// usingSentences makes `sents` the Document's sentences instead of
+// segmenting its text.
+func usingSentences(sents []Sentence) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.sentences = sents
+	}
+}
+
+// flattenSentences validates the tokenized `sentences` of `text`, returning
+// a copy of their tokens and the Sentences they make up.
+func flattenSentences(text string, sentences [][]*Token) ([]Token, []Sentence, error) {
+	tokens := []Token{}
+	sents := make([]Sentence, 0, len(sentences))
+	withOffsets := 0
+	for i, sentence := range sentences {
+		if len(sentence) == 0 {
+			return nil, nil, fmt.Errorf("sentence %d is empty", i)
+		}
+		for j, tok := range sentence {
+			if tok == nil {
+				return nil, nil, fmt.Errorf("token %d of sentence %d is nil", j, i)
+			} else if tok.Text == "" {
+				return nil, nil, fmt.Errorf("token %d of sentence %d has no text", j, i)
+			} else if !hasOffsets(tok) {
+				continue
+			} else if tok.Start < 0 || tok.End > len(text) {
+				return nil, nil, withCode(ErrInvalidSpan, fmt.Errorf(
+					"token %d of sentence %d spans [%d, %d), outside of the text",
+					j, i, tok.Start, tok.End))
+			}
+			withOffsets++
+		}
+
+		first, last := sentence[0], sentence[len(sentence)-1]
+		if hasOffsets(first) && hasOffsets(last) && first.Start <= last.End {
+			sents = append(sents, Sentence{
+				Text: text[first.Start:last.End], Start: first.Start, End: last.End})
+		} else {
+			sents = append(sents, Sentence{Text: JoinTokens(sentence), Start: -1, End: -1})
+		}
+		for _, tok := range sentence {
+			tokens = append(tokens, *tok)
+		}
+	}
+	if withOffsets > 0 && withOffsets < len(tokens) {
+		return nil, nil, errors.New("some tokens have offsets and others don't")
+	}
+	return tokens, sents, nil
+}
+
+// validateTokens reports the first token of pre-tokenized input that can't
+// be tagged or classified, if any.
+func validateTokens(tokens []Token) error {
+	for i, tok := range tokens {
+		if tok.Text == "" {
+			return fmt.Errorf("token %d has no text", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package prose
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A QualityIssue is a property of a Document's text that makes its tags and
+// entities less reliable.
+type QualityIssue int
+
+const (
+	// IssueAllCaps means that most words are written in all capitals, which
+	// the models (trained on mixed-case text) handle poorly; see
+	// WithCapsPolicy.
+	IssueAllCaps QualityIssue = iota + 1
+	// IssueLongSentences means that sentences are unusually long on
+	// average, which usually means that segmentation failed (e.g., on text
+	// without punctuation).
+	IssueLongSentences
+	// IssueNonLatin means that most of the text's letters are written in a
+	// script other than Latin, so it can't be English, which is all the
+	// bundled models support. Like the input guard (see WithInputGuard),
+	// this is a script check: it doesn't flag other Latin-script languages.
+	IssueNonLatin
+)
+
+// A QualityWarning describes a QualityIssue found in a Document.
+type QualityWarning struct {
+	Issue   QualityIssue
+	Value   float64 // The measurement that raised the issue (see checkQuality)
+	Message string  // A description of the issue, as reported by Warnings
+}
+
+// Thresholds for the quality checks; texts with fewer than minQualityWords
+// words are too short for the share of all-caps words or of Latin letters
+// to be meaningful.
+const (
+	minQualityWords   = 10
+	maxCapsShare      = 0.5
+	maxSentenceTokens = 100
+)
+
+// WithQualityChecks can enable or disable (the default) checking the text
+// for QualityIssues; those found are reported by Document.Quality and, as
+// messages, by Document.Warnings.
+func WithQualityChecks(include bool) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Quality = include
+	}
+}
+
+// Quality returns the QualityIssues found in `doc`'s text, if it was checked
+// for them (see WithQualityChecks).
+func (doc *Document) Quality() []QualityWarning {
+	return doc.quality
+}
+
+// checkQuality returns the QualityIssues found in `doc`, which has been
+// segmented and tokenized. Their values are the share of words in all
+// capitals, the average number of tokens per sentence, and the share of
+// letters written in the Latin script (see latinShare), respectively.
+func checkQuality(doc *Document) []QualityWarning {
+	warnings := []QualityWarning{}
+
+	words := []string{}
+	for _, tok := range doc.tokens {
+		if len(tok.Text) > 1 && strings.IndexFunc(tok.Text, isNotLetter) < 0 {
+			words = append(words, tok.Text)
+		}
+	}
+
+	if len(words) >= minQualityWords {
+		caps := 0
+		for _, word := range words {
+			if !hasLower(word) {
+				caps++
+			}
+		}
+		if share := float64(caps) / float64(len(words)); share > maxCapsShare {
+			warnings = append(warnings, QualityWarning{
+				Issue: IssueAllCaps, Value: share,
+				Message: fmt.Sprintf("%.0f%% of words are ALL-CAPS", 100*share)})
+		}
+	}
+
+	if len(doc.sentences) > 0 && len(doc.tokens) > 0 {
+		average := float64(len(doc.tokens)) / float64(len(doc.sentences))
+		if average > maxSentenceTokens {
+			warnings = append(warnings, QualityWarning{
+				Issue: IssueLongSentences, Value: average,
+				Message: fmt.Sprintf("average sentence length %.0f tokens — possible segmentation failure", average)})
+		}
+	}
+
+	if len(words) >= minQualityWords {
+		if share := latinShare(strings.Join(words, " ")); share < minLatinShare {
+			warnings = append(warnings, QualityWarning{
+				Issue: IssueNonLatin, Value: share,
+				Message: fmt.Sprintf("non-Latin script (%.0f%% of letters are Latin)", 100*share)})
+		}
+	}
+
+	return warnings
+}
+
+func isNotLetter(r rune) bool {
+	return !unicode.IsLetter(r)
+}
@@ -0,0 +1,58 @@
+package prose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// qualityIssues returns the issues of the Document for `text`.
+func qualityIssues(t *testing.T, text string) []QualityIssue {
+	doc, err := NewDocument(text, WithTagging(false), WithExtraction(false),
+		WithQualityChecks(true))
+	require.NoError(t, err)
+	issues := []QualityIssue{}
+	for _, warning := range doc.Quality() {
+		issues = append(issues, warning.Issue)
+	}
+	require.Len(t, doc.Warnings(), len(issues))
+	return issues
+}
+
+func TestQualityChecks(t *testing.T) {
+	assert.Empty(t, qualityIssues(t,
+		"The quick brown fox jumps over the lazy dog. The farmer watches from his porch."))
+
+	assert.Equal(t, []QualityIssue{IssueAllCaps}, qualityIssues(t,
+		"THE LICENSEE SHALL INDEMNIFY THE LICENSOR AGAINST ALL CLAIMS ARISING FROM THE USE OF THE SOFTWARE."))
+
+	run := strings.Repeat("the farmer watches the fox and the dog from his porch ", 12)
+	assert.Equal(t, []QualityIssue{IssueLongSentences}, qualityIssues(t, run))
+
+	assert.Equal(t, []QualityIssue{IssueNonLatin}, qualityIssues(t,
+		"Быстрая коричневая лиса прыгает через ленивую собаку, пока фермер смотрит с крыльца."))
+	assert.Equal(t, []QualityIssue{IssueNonLatin}, qualityIssues(t,
+		"Η γρήγορη καφέ αλεπού πηδά πάνω από τον τεμπέλη σκύλο ενώ ο αγρότης κοιτάζει."))
+	// Other Latin-script languages aren't flagged.
+	assert.Empty(t, qualityIssues(t,
+		"Der schnelle braune Fuchs springt über den faulen Hund, während der Bauer zusieht."))
+
+	// Short texts are only checked for long sentences.
+	assert.Empty(t, qualityIssues(t, "ACME CORP. ANNUAL REPORT."))
+
+	doc, err := NewDocument("THE LICENSEE SHALL INDEMNIFY THE LICENSOR AGAINST ALL CLAIMS ARISING FROM THE USE OF THE SOFTWARE.",
+		WithTagging(false), WithExtraction(false), WithQualityChecks(true))
+	require.NoError(t, err)
+	require.Len(t, doc.Quality(), 1)
+	assert.Equal(t, 1.0, doc.Quality()[0].Value)
+	assert.Equal(t, []string{"100% of words are ALL-CAPS"}, doc.Warnings())
+
+	// The checks are off by default.
+	doc, err = NewDocument("THE LICENSEE SHALL INDEMNIFY THE LICENSOR AGAINST ALL CLAIMS.",
+		WithTagging(false), WithExtraction(false))
+	require.NoError(t, err)
+	assert.Empty(t, doc.Quality())
+	assert.Empty(t, doc.Warnings())
+}
@@ -0,0 +1,129 @@
+package prose
+
+import "strings"
+
+// A MatchMode determines when a predicted entity matches a gold one.
+type MatchMode int
+
+const (
+	// MatchExact requires the same text and label.
+	MatchExact MatchMode = iota
+	// MatchOverlap requires the same label and at least one shared word.
+	MatchOverlap
+	// MatchTypeRelaxed requires the same text, but ignores labels.
+	MatchTypeRelaxed
+)
+
+// MatchByOffset, combined with another mode (e.g., MatchExact|MatchByOffset),
+// compares entities that both have offsets by offset rather than by text, so
+// that repeated mentions are told apart. It only makes sense for entities of
+// the same text, such as those of one Document.
+const MatchByOffset MatchMode = 1 << 8
+
+// A Score holds the counts behind precision, recall, and F1.
+type Score struct {
+	TruePositives  int // Predictions that match a gold entity.
+	FalsePositives int // Predictions that match no gold entity.
+	FalseNegatives int // Gold entities matched by no prediction.
+}
+
+// Precision is the fraction of predictions that are correct.
+func (s Score) Precision() float64 {
+	return ratio(s.TruePositives, s.TruePositives+s.FalsePositives)
+}
+
+// Recall is the fraction of gold entities that were predicted.
+func (s Score) Recall() float64 {
+	return ratio(s.TruePositives, s.TruePositives+s.FalseNegatives)
+}
+
+// F1 is the harmonic mean of Precision and Recall.
+func (s Score) F1() float64 {
+	p, r := s.Precision(), s.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// A SpanReport summarizes how well predicted entities match gold ones,
+// overall and by label.
+type SpanReport struct {
+	Score
+	Labels map[string]Score
+}
+
+// ScoreSpans compares the predicted entities `pred` with the gold entities
+// `gold` according to `mode`.
+//
+// Each gold entity matches at most one prediction. In MatchTypeRelaxed
+// mode, matches are attributed to the gold label. Entities are compared by
+// their text, unless `mode` includes MatchByOffset and both have offsets; in
+// MatchOverlap mode, that means a shared word or overlapping offsets.
+func ScoreSpans(gold, pred []Entity, mode MatchMode) SpanReport {
+	report := SpanReport{Labels: map[string]Score{}}
+	count := func(label string, update func(*Score)) {
+		update(&report.Score)
+		score := report.Labels[label]
+		update(&score)
+		report.Labels[label] = score
+	}
+
+	matched := make([]bool, len(gold))
+	for _, p := range pred {
+		found := false
+		for i, g := range gold {
+			if !matched[i] && spansMatch(g, p, mode) {
+				matched[i], found = true, true
+				count(g.Label, func(s *Score) { s.TruePositives++ })
+				break
+			}
+		}
+		if !found {
+			count(p.Label, func(s *Score) { s.FalsePositives++ })
+		}
+	}
+	for i, g := range gold {
+		if !matched[i] {
+			count(g.Label, func(s *Score) { s.FalseNegatives++ })
+		}
+	}
+
+	return report
+}
+
+func spansMatch(gold, pred Entity, mode MatchMode) bool {
+	located := mode&MatchByOffset != 0 && gold.End > gold.Start && pred.End > pred.Start
+	switch mode &^ MatchByOffset {
+	case MatchOverlap:
+		if gold.Label != pred.Label {
+			return false
+		} else if located {
+			return gold.Start < pred.End && pred.Start < gold.End
+		}
+		words := strings.Fields(gold.Text)
+		for _, word := range strings.Fields(pred.Text) {
+			if stringInSlice(word, words) {
+				return true
+			}
+		}
+		return false
+	case MatchTypeRelaxed:
+		return sameSpan(gold, pred, located)
+	}
+	return sameSpan(gold, pred, located) && gold.Label == pred.Label
+}
+
+func sameSpan(gold, pred Entity, located bool) bool {
+	if located {
+		return gold.Start == pred.Start && gold.End == pred.End
+	}
+	return gold.Text == pred.Text
+}
@@ -0,0 +1,50 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreSpans(t *testing.T) {
+	gold := []Entity{
+		{Text: "Acme Corp", Label: "ORG"},
+		{Text: "Jane Doe", Label: "PERSON"},
+		{Text: "Ontario", Label: "GPE"},
+	}
+	pred := []Entity{
+		{Text: "Acme Corp", Label: "ORG"},
+		{Text: "Jane", Label: "PERSON"},
+		{Text: "Ontario", Label: "PERSON"},
+	}
+
+	exact := ScoreSpans(gold, pred, MatchExact)
+	assert.Equal(t, Score{TruePositives: 1, FalsePositives: 2, FalseNegatives: 2}, exact.Score)
+	assert.InDelta(t, 1.0/3.0, exact.Precision(), 1e-9)
+	assert.InDelta(t, 1.0/3.0, exact.F1(), 1e-9)
+	assert.Equal(t, Score{FalsePositives: 2, FalseNegatives: 1}, exact.Labels["PERSON"])
+	assert.Equal(t, Score{FalseNegatives: 1}, exact.Labels["GPE"])
+
+	overlap := ScoreSpans(gold, pred, MatchOverlap)
+	assert.Equal(t, Score{TruePositives: 2, FalsePositives: 1, FalseNegatives: 1}, overlap.Score)
+	assert.Equal(t, Score{TruePositives: 1, FalsePositives: 1}, overlap.Labels["PERSON"])
+
+	relaxed := ScoreSpans(gold, pred, MatchTypeRelaxed)
+	assert.Equal(t, Score{TruePositives: 2, FalsePositives: 1, FalseNegatives: 1}, relaxed.Score)
+	assert.Equal(t, Score{TruePositives: 1}, relaxed.Labels["GPE"])
+
+	// Entities with offsets are compared by offset only with MatchByOffset.
+	first := []Entity{{Text: "Acme", Label: "ORG", Start: 0, End: 4}}
+	second := []Entity{{Text: "Acme", Label: "ORG", Start: 10, End: 14}}
+	located := ScoreSpans(first, second, MatchExact)
+	assert.Equal(t, Score{TruePositives: 1}, located.Score)
+	located = ScoreSpans(first, second, MatchExact|MatchByOffset)
+	assert.Equal(t, Score{FalsePositives: 1, FalseNegatives: 1}, located.Score)
+	located = ScoreSpans(
+		[]Entity{{Text: "Acme Corp", Label: "ORG", Start: 0, End: 9}},
+		[]Entity{{Text: "Corp", Label: "ORG", Start: 5, End: 9}}, MatchOverlap|MatchByOffset)
+	assert.Equal(t, Score{TruePositives: 1}, located.Score)
+
+	empty := ScoreSpans(nil, nil, MatchExact)
+	assert.Equal(t, 0.0, empty.F1())
+}
@@ -0,0 +1,92 @@
+package prose
+
+import (
+	"sort"
+	"strings"
+)
+
+// A SentenceMatch is a sentence found by Document.FindSentences.
+type SentenceMatch struct {
+	Sentence Sentence
+	Index    int // The index of the sentence in Document.Sentences.
+	Distance int // The number of token edits needed to match the query.
+}
+
+// FindSentences returns the sentences of `doc` that contain an approximate
+// match of `query`: one within `fuzziness` token insertions, deletions, or
+// substitutions. Matches are ordered by distance, then by position.
+//
+// Tokens are compared case-insensitively, and a run of underscores ("___")
+// in `query` matches one or more tokens of any kind, so that
+//
+//	doc.FindSentences("governed by the laws of the State of ___", 2)
+//
+// finds the variants of a governing law clause.
+func (doc *Document) FindSentences(query string, fuzziness int) []SentenceMatch {
+	tokenizer := doc.config.Tokenizer
+	if tokenizer == nil {
+		tokenizer = NewIterTokenizer()
+	}
+	pattern := searchTerms(tokenizer.Tokenize(query))
+
+	matches := []SentenceMatch{}
+	if len(pattern) == 0 {
+		return matches
+	}
+	for i, sent := range doc.sentences {
+		terms := searchTerms(tokenizer.Tokenize(sent.Text))
+		if d := matchDistance(pattern, terms); d <= fuzziness {
+			matches = append(matches, SentenceMatch{Sentence: sent, Index: i, Distance: d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Distance < matches[j].Distance
+	})
+	return matches
+}
+
+func searchTerms(tokens []*Token) []string {
+	terms := make([]string, len(tokens))
+	for i, tok := range tokens {
+		terms[i] = strings.ToLower(tok.Text)
+	}
+	return terms
+}
+
+func isWildcard(term string) bool {
+	return len(term) >= 2 && strings.Trim(term, "_") == ""
+}
+
+// matchDistance returns the smallest token-level edit distance between
+// `pattern` and any span of `terms`.
+func matchDistance(pattern, terms []string) int {
+	// prev[j] is the distance between the first i-1 pattern terms and a span
+	// of `terms` ending at j; a match may start anywhere, so prev[0:] starts
+	// at zero.
+	prev := make([]int, len(terms)+1)
+	curr := make([]int, len(terms)+1)
+	for i := 1; i <= len(pattern); i++ {
+		term := pattern[i-1]
+		curr[0] = i
+		for j := 1; j <= len(terms); j++ {
+			if isWildcard(term) {
+				// Consume this token and, possibly, more after it.
+				curr[j] = min(prev[j-1], curr[j-1])
+				continue
+			}
+			cost := 1
+			if term == terms[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j-1]+cost, min(prev[j]+1, curr[j-1]+1))
+		}
+		prev, curr = curr, prev
+	}
+
+	best := prev[0]
+	for _, d := range prev[1:] {
+		best = min(best, d)
+	}
+	return best
+}
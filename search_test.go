@@ -0,0 +1,29 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindSentences(t *testing.T) {
+	doc, err := NewDocument(
+		"This Agreement is governed by the laws of the State of New York. "+
+			"Payment is due in thirty days. "+
+			"This agreement shall be governed by the law of the State of Delaware. "+
+			"The laws of Ontario apply.",
+		WithExtraction(false))
+	require.NoError(t, err)
+
+	matches := doc.FindSentences("governed by the laws of the State of ___", 2)
+	require.Len(t, matches, 2)
+	assert.Equal(t, 0, matches[0].Index)
+	assert.Equal(t, 0, matches[0].Distance)
+	assert.Equal(t, 2, matches[1].Index)
+	assert.Equal(t, 1, matches[1].Distance)
+
+	assert.Len(t, doc.FindSentences("governed by the laws of the State of ___", 0), 1)
+	assert.Len(t, doc.FindSentences("PAYMENT IS DUE", 0), 1)
+	assert.Empty(t, doc.FindSentences("", 3))
+}
@@ -0,0 +1,89 @@
+package prose
+
+import "regexp"
+
+// WithBoundaries treats matches of `delimiter` (e.g., form feeds, "-----"
+// separators, or markers such as "<doc>") as hard boundaries between
+// sections of the text: no sentence or entity crosses one. The delimiters
+// themselves are excluded from sentences and tokens.
+//
+// This is useful when several records are concatenated into one string.
+func WithBoundaries(delimiter *regexp.Regexp) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Boundaries = delimiter
+	}
+}
+
+// findDelimiters returns the [start, end) byte offsets of the matches of
+// `delimiter` in `text`.
+func findDelimiters(text string, delimiter *regexp.Regexp) [][2]int {
+	spans := [][2]int{}
+	if delimiter == nil {
+		return spans
+	}
+	for _, loc := range delimiter.FindAllStringIndex(text, -1) {
+		if loc[0] < loc[1] {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+	return spans
+}
+
+// sectionsBetween returns the regions of `text` between `delimiters`.
+func sectionsBetween(text string, delimiters [][2]int) [][2]int {
+	sections := [][2]int{}
+	cursor := 0
+	for _, d := range delimiters {
+		sections = append(sections, [2]int{cursor, d[0]})
+		cursor = d[1]
+	}
+	return append(sections, [2]int{cursor, len(text)})
+}
+
+// cutSection splits `section` at the offsets `cuts` (in order) that fall
+// within it.
+func cutSection(section [2]int, cuts []int) [][2]int {
+	spans := [][2]int{}
+	cursor := section[0]
+	for _, cut := range cuts {
+		if cut > cursor && cut < section[1] {
+			spans = append(spans, [2]int{cursor, cut})
+			cursor = cut
+		}
+	}
+	return append(spans, [2]int{cursor, section[1]})
+}
+
+// blankSpans replaces the (non-newline) contents of `spans` with spaces,
+// preserving all offsets into `text`.
+func blankSpans(text string, spans [][2]int) string {
+	if len(spans) == 0 {
+		return text
+	}
+	b := []byte(text)
+	for _, span := range spans {
+		for i := span[0]; i < span[1]; i++ {
+			if b[i] != '\n' {
+				b[i] = ' '
+			}
+		}
+	}
+	return string(b)
+}
+
+// chunkSections groups the tokens of each section's entities, ensuring that
+// none crosses from one section into the next.
+func chunkSections(extracter *entityExtracter, sections [][]*Token) [][]*Token {
+	if len(sections) == 1 {
+		return extracter.chunkTokens(sections[0])
+	}
+	tokens := []*Token{}
+	for i, section := range sections {
+		if i > 0 {
+			// An "O" token always ends the current entity.
+			tokens = append(tokens, &Token{Label: "O"})
+		}
+		tokens = append(tokens, section...)
+	}
+	return extracter.chunkTokens(tokens)
+}
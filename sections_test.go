@@ -0,0 +1,33 @@
+package prose
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundaries(t *testing.T) {
+	text := "The memo was approved by Jack\n-----\nSmith signed it in Seattle on Monday.\fWe met Barack Obama"
+	delimiter := regexp.MustCompile(`\n-----\n|\f`)
+
+	doc, err := NewDocument(text, WithBoundaries(delimiter))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"The memo was approved by Jack",
+		"Smith signed it in Seattle on Monday.",
+		"We met Barack Obama"}, sentenceTexts(doc))
+	for _, tok := range doc.Tokens() {
+		assert.NotContains(t, tok.Text, "-----")
+	}
+	for _, ent := range doc.Entities() {
+		assert.False(t, strings.Contains(ent.Text, "Jack") && strings.Contains(ent.Text, "Smith"))
+	}
+
+	doc, err = NewDocument(text)
+	require.NoError(t, err)
+	assert.Len(t, doc.Sentences(), 2)
+}
@@ -21,40 +21,321 @@
 package prose
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"gopkg.in/neurosnap/sentences.v1"
 	"gopkg.in/neurosnap/sentences.v1/data"
 )
 
+// A SentenceTokenizer splits text into sentences.
+//
+// The built-in SentenceTokenizers set the offsets of the sentences they
+// return, and NewDocument sets those of custom ones.
+type SentenceTokenizer interface {
+	Segment(string) []Sentence
+}
+
+// SegmenterOptFunc configures the built-in SentenceTokenizers.
+type SegmenterOptFunc func(*segmenterOpts)
+
+type segmenterOpts struct {
+	add    []string
+	remove []string
+	tagger *PerceptronTagger
+}
+
+// Add the provided abbreviations (e.g., "v." or "Stat."), which don't end
+// sentences, to the defaults.
+func AddingAbbreviations(x []string) SegmenterOptFunc {
+	return func(opts *segmenterOpts) {
+		opts.add = append(opts.add, x...)
+	}
+}
+
+// Remove the provided abbreviations from the defaults, so that they can end
+// sentences.
+func RemovingAbbreviations(x []string) SegmenterOptFunc {
+	return func(opts *segmenterOpts) {
+		opts.remove = append(opts.remove, x...)
+	}
+}
+
+// abbreviationType normalizes `abbr` as the segmenters store it: lowercase,
+// without a final period.
+func abbreviationType(abbr string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(abbr), "."))
+}
+
+// sentenceSegmenter is the SentenceTokenizer that NewPunktSentenceTokenizer
+// and NewRuleSentenceTokenizer return, and that NewDocument wraps custom
+// SentenceTokenizers in: it locates the sentences of its tokenizer in the
+// text and, optionally, decides whether abbreviations end them.
+type sentenceSegmenter struct {
+	tokenizer SentenceTokenizer
+
+	// tagger, if set, decides whether abbreviations end sentences (see
+	// UsingAbbreviationTagger); abbreviations are those it applies to.
+	tagger        *PerceptronTagger
+	abbreviations map[string]bool
+}
+
+func newSegmenter(tokenizer SentenceTokenizer, opts segmenterOpts) *sentenceSegmenter {
+	s := &sentenceSegmenter{
+		tokenizer:     tokenizer,
+		tagger:        opts.tagger,
+		abbreviations: make(map[string]bool, len(ruleAbbreviations)+len(opts.add))}
+	for _, abbr := range ruleAbbreviations {
+		s.abbreviations[abbr] = true
+	}
+	for _, abbr := range opts.add {
+		s.abbreviations[abbreviationType(abbr)] = true
+	}
+	for _, abbr := range opts.remove {
+		delete(s.abbreviations, abbreviationType(abbr))
+	}
+	return s
+}
+
+// Segment splits `text` into sentences. Text without terminal punctuation is
+// a single sentence, and blank text has none.
+//
+// Each sentence's offsets locate it in `text`, so that
+// text[sent.Start:sent.End] == sent.Text; the surrounding whitespace isn't
+// part of a sentence. A custom SentenceTokenizer may change the whitespace
+// within sentences (the text then follows `text`), but sentences it changes
+// otherwise have the offsets -1.
+func (s *sentenceSegmenter) Segment(text string) []Sentence {
+	if strings.TrimSpace(text) == "" {
+		return []Sentence{}
+	}
+	sents := locateSentences(text, s.tokenizer.Segment(text))
+	if s.tagger != nil {
+		sents, _ = s.disambiguate(text, sents)
+	}
+	return sents
+}
+
+// locateSentences sets the offsets of `sents`, which were segmented in order
+// from `text`.
+func locateSentences(text string, sents []Sentence) []Sentence {
+	cursor := 0
+	for i := range sents {
+		start, end := alignSentence(text, cursor, sents[i].Text)
+		if start < 0 {
+			sents[i].Start, sents[i].End = -1, -1
+			continue
+		}
+		sents[i] = Sentence{Text: text[start:end], Start: start, End: end}
+		cursor = end
+	}
+	return sents
+}
+
+// alignSentence returns the offsets of the first occurrence of `sent` in
+// `text` at or after `from`, allowing for differences in whitespace, or -1
+// if there isn't one.
+func alignSentence(text string, from int, sent string) (int, int) {
+	if idx := strings.Index(text[from:], sent); idx >= 0 && sent != "" {
+		return from + idx, from + idx + len(sent)
+	}
+
+	words := strings.Fields(sent)
+	if len(words) == 0 {
+		return -1, -1
+	}
+	for from < len(text) {
+		idx := strings.Index(text[from:], words[0])
+		if idx < 0 {
+			break
+		}
+		start := from + idx
+		end := start + len(words[0])
+		for _, word := range words[1:] {
+			end = len(text) - len(strings.TrimLeftFunc(text[end:], unicode.IsSpace))
+			if !strings.HasPrefix(text[end:], word) {
+				end = -1
+				break
+			}
+			end += len(word)
+		}
+		if end >= 0 {
+			return start, end
+		}
+		from = start + 1
+	}
+	return -1, -1
+}
+
+// segmentBetween segments `text` so that no sentence crosses one of the
+// byte offsets in `breaks`.
+func (s *sentenceSegmenter) segmentBetween(text string, breaks []int) []Sentence {
+	if len(breaks) == 0 {
+		return s.Segment(text)
+	}
+	sort.Ints(breaks)
+
+	sents := []Sentence{}
+	cursor := 0
+	for _, b := range append(breaks, len(text)) {
+		if b <= cursor {
+			continue
+		}
+		for _, sent := range s.Segment(text[cursor:b]) {
+			if sent.Start >= 0 {
+				sent.Start, sent.End = sent.Start+cursor, sent.End+cursor
+			}
+			sents = append(sents, sent)
+		}
+		cursor = b
+	}
+	return sents
+}
+
+// documentSegmenter returns the segmenter NewDocument uses for `config`;
+// `tagger`, if set, is the model's (see WithAbbreviationTagging).
+func documentSegmenter(config Config, tagger *PerceptronTagger) (*sentenceSegmenter, error) {
+	if !config.AbbreviationTagging {
+		tagger = nil
+	} else if tagger == nil {
+		var err error
+		if tagger, err = NewPerceptronTagger(); err != nil {
+			return nil, fmt.Errorf("unable to load default POS tagger: %w", err)
+		}
+	}
+
+	switch t := config.SentenceTokenizer.(type) {
+	case nil:
+		segmenter, err := NewPunktSentenceTokenizer(UsingAbbreviationTagger(tagger))
+		if err != nil {
+			return nil, fmt.Errorf("unable to create punkt segmenter: %w", err)
+		}
+		return segmenter, nil
+	case *sentenceSegmenter:
+		if tagger == nil || t.tagger != nil {
+			return t, nil
+		}
+		tagged := *t
+		tagged.tagger = tagger
+		return &tagged, nil
+	default:
+		return newSegmenter(t, segmenterOpts{tagger: tagger}), nil
+	}
+}
+
 // punktSentenceTokenizer is an extension of the Go implementation of the Punkt
 // sentence tokenizer (https://github.com/neurosnap/sentences), with a few
 // minor improvements (see https://github.com/neurosnap/sentences/pull/18).
 type punktSentenceTokenizer struct {
 	tokenizer *sentences.DefaultSentenceTokenizer
+	opts      segmenterOpts
 }
 
-// newPunktSentenceTokenizer creates a new PunktSentenceTokenizer and loads
-// its English model.
-func newPunktSentenceTokenizer() (*punktSentenceTokenizer, error) {
-	var pt punktSentenceTokenizer
-	var err error
-	pt.tokenizer, err = newSentenceTokenizer(nil)
-
-	return &pt, err
+// NewPunktSentenceTokenizer creates a new punkt-based SentenceTokenizer and
+// loads its English model.
+func NewPunktSentenceTokenizer(opts ...SegmenterOptFunc) (*sentenceSegmenter, error) {
+	pt := &punktSentenceTokenizer{}
+	for _, applyOpt := range opts {
+		applyOpt(&pt.opts)
+	}
+	tokenizer, err := newSentenceTokenizer(nil, pt.opts)
+	if err != nil {
+		return nil, err
+	}
+	pt.tokenizer = tokenizer
+	return newSegmenter(pt, pt.opts), nil
 }
 
-// segment splits text into sentences.
-func (p punktSentenceTokenizer) segment(text string) []Sentence {
+// Segment splits text into sentences.
+func (p punktSentenceTokenizer) Segment(text string) []Sentence {
 	tokens := p.tokenizer.Tokenize(text)
-	sents := make([]Sentence, len(tokens))
+	sents := make([]Sentence, 0, len(tokens))
 	for i := range tokens {
-		sents[i] = Sentence{Text: strings.TrimSpace(tokens[i].Text)}
+		// Trailing whitespace is returned as a sentence of its own.
+		if sent := strings.TrimSpace(tokens[i].Text); sent != "" {
+			sents = append(sents, Sentence{Text: sent})
+		}
 	}
 	return sents
 }
 
+// ruleSentenceTokenizer splits sentences with a small set of rules: a
+// sentence ends at terminal punctuation (optionally followed by closing quotes
+// or brackets) that is followed by whitespace and a capitalized word, unless
+// the punctuation belongs to a known abbreviation or an initial.
+type ruleSentenceTokenizer struct {
+	abbreviations map[string]struct{}
+}
+
+// NewRuleSentenceTokenizer creates a new rule-based SentenceTokenizer.
+func NewRuleSentenceTokenizer(opts ...SegmenterOptFunc) *sentenceSegmenter {
+	var options segmenterOpts
+	for _, applyOpt := range opts {
+		applyOpt(&options)
+	}
+	abbrevs := make(map[string]struct{}, len(ruleAbbreviations)+len(options.add))
+	for _, abbr := range ruleAbbreviations {
+		abbrevs[abbr] = struct{}{}
+	}
+	for _, abbr := range options.add {
+		abbrevs[abbreviationType(abbr)] = struct{}{}
+	}
+	for _, abbr := range options.remove {
+		delete(abbrevs, abbreviationType(abbr))
+	}
+	return newSegmenter(&ruleSentenceTokenizer{abbreviations: abbrevs}, options)
+}
+
+var ruleAbbreviations = []string{
+	"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st", "vs", "etc", "inc",
+	"ltd", "co", "corp", "jan", "feb", "mar", "apr", "jun", "jul", "aug",
+	"sep", "sept", "oct", "nov", "dec", "no", "gov", "sgt", "mt", "e.g",
+	"i.e"}
+
+// Segment splits text into sentences.
+func (r *ruleSentenceTokenizer) Segment(text string) []Sentence {
+	sents := []Sentence{}
+	start := 0
+	for _, loc := range reSentenceEnd.FindAllStringSubmatchIndex(text, -1) {
+		end := loc[3]
+		if end <= start || r.isAbbreviation(text[start:end]) {
+			continue
+		}
+		if sent := strings.TrimSpace(text[start:end]); sent != "" {
+			sents = append(sents, Sentence{Text: sent})
+		}
+		start = end
+	}
+	if sent := strings.TrimSpace(text[start:]); sent != "" {
+		sents = append(sents, Sentence{Text: sent})
+	}
+	return sents
+}
+
+// isAbbreviation determines if the last word of `text` is an abbreviation or
+// an initial (and so shouldn't end a sentence).
+func (r *ruleSentenceTokenizer) isAbbreviation(text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+	word := strings.TrimLeft(fields[len(fields)-1], `"'([`)
+	if !strings.HasSuffix(word, ".") {
+		return false
+	}
+	word = strings.ToLower(strings.TrimSuffix(word, "."))
+	if _, found := r.abbreviations[word]; found {
+		return true
+	}
+	return utf8.RuneCountInString(word) == 1
+}
+
+var reSentenceEnd = regexp.MustCompile(`([^.?!\s]*[.?!]+['"’”)\]]*)\s+['"‘“(\[]?[\p{Lu}\d]`)
+
 type wordTokenizer struct {
 	sentences.DefaultWordTokenizer
 }
@@ -64,7 +345,7 @@ var reLooksLikeEllipsis = regexp.MustCompile(`(?:\.\s?){2,}\.`)
 var reEntities = regexp.MustCompile(`Yahoo!`)
 
 // English customized sentence tokenizer.
-func newSentenceTokenizer(s *sentences.Storage) (*sentences.DefaultSentenceTokenizer, error) {
+func newSentenceTokenizer(s *sentences.Storage, opts segmenterOpts) (*sentences.DefaultSentenceTokenizer, error) {
 	training := s
 
 	if training == nil {
@@ -84,6 +365,12 @@ func newSentenceTokenizer(s *sentences.Storage) (*sentences.DefaultSentenceToken
 	for _, abbr := range abbrevs {
 		training.AbbrevTypes.Add(abbr)
 	}
+	for _, abbr := range opts.add {
+		training.AbbrevTypes.Add(abbreviationType(abbr))
+	}
+	for _, abbr := range opts.remove {
+		training.AbbrevTypes.Remove(abbreviationType(abbr))
+	}
 
 	lang := sentences.NewPunctStrings()
 	word := newWordTokenizer(lang)
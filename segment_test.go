@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -63,7 +64,7 @@ func TestEnglishSmartQuotes(t *testing.T) {
 
 	for index, sent := range actual {
 		if sent.Text != expected[index] {
-			t.Fatalf("Actual: %s\nExpected: %s", sent, expected[index])
+			t.Fatalf("Actual: %s\nExpected: %s", sent.Text, expected[index])
 		}
 	}
 }
@@ -84,7 +85,7 @@ func TestEnglishCustomAbbrev(t *testing.T) {
 
 	for index, sent := range actual {
 		if sent.Text != expected[index] {
-			t.Fatalf("Actual: %s\nExpected: %s", sent, expected[index])
+			t.Fatalf("Actual: %s\nExpected: %s", sent.Text, expected[index])
 		}
 	}
 
@@ -103,7 +104,7 @@ func TestEnglishCustomAbbrev(t *testing.T) {
 
 	for index, sent := range actual {
 		if sent.Text != expected[index] {
-			t.Fatalf("Actual: %s\nExpected: %s", sent, expected[index])
+			t.Fatalf("Actual: %s\nExpected: %s", sent.Text, expected[index])
 		}
 	}
 }
@@ -125,7 +126,7 @@ func TestEnglishSupervisedAbbrev(t *testing.T) {
 
 	for index, sent := range actual {
 		if sent.Text != expected[index] {
-			t.Fatalf("Actual: %s\nExpected: %s", sent, expected[index])
+			t.Fatalf("Actual: %s\nExpected: %s", sent.Text, expected[index])
 		}
 	}
 }
@@ -146,7 +147,7 @@ func TestEnglishSemicolon(t *testing.T) {
 
 	for index, sent := range actual {
 		if sent.Text != expected[index] {
-			t.Fatalf("Actual: %s\nExpected: %s", sent, expected[index])
+			t.Fatalf("Actual: %s\nExpected: %s", sent.Text, expected[index])
 		}
 	}
 }
@@ -166,7 +167,7 @@ func compareSentences(t *testing.T, actualText string, expected []string, test s
 	for index, sent := range actual {
 		if sent.Text != expected[index] {
 			t.Log(test)
-			t.Errorf("Actual: [%s] Expected: [%s]\n", sent, expected[index])
+			t.Errorf("Actual: [%s] Expected: [%s]\n", sent.Text, expected[index])
 			t.Log("===")
 			return false
 		}
@@ -504,3 +505,259 @@ func TestGoldenRules(t *testing.T) {
 	}
 	compareSentences(t, actualText, expected, test)*/
 }
+
+func TestRuleSentenceTokenizer(t *testing.T) {
+	text := `Mr. Smith went to Washington. He said "Hello there!" Then J. R. Jones left... Was it 5 p.m.? Yes.`
+	doc, err := NewDocument(text,
+		UsingSentenceTokenizer(NewRuleSentenceTokenizer()),
+		WithTagging(false),
+		WithExtraction(false))
+	require.NoError(t, err)
+
+	sents := []string{}
+	for _, sent := range doc.Sentences() {
+		sents = append(sents, sent.Text)
+	}
+	require.Equal(t, []string{
+		"Mr. Smith went to Washington.",
+		`He said "Hello there!"`,
+		"Then J. R. Jones left...",
+		"Was it 5 p.m.?",
+		"Yes.",
+	}, sents)
+}
+
+func TestSentenceTokenizerParity(t *testing.T) {
+	punkt, err := NewPunktSentenceTokenizer()
+	require.NoError(t, err)
+	rules := NewRuleSentenceTokenizer()
+
+	for _, segmenter := range []SentenceTokenizer{punkt, rules} {
+		require.Len(t, segmenter.Segment("One. Two."), 2)
+	}
+
+	text := "The first sentence. The second one!"
+	require.Equal(t, punkt.Segment(text), rules.Segment(text))
+}
+
+func TestSegmenterAbbreviations(t *testing.T) {
+	texts := func(sents []Sentence) []string {
+		out := []string{}
+		for _, sent := range sents {
+			out = append(out, sent.Text)
+		}
+		return out
+	}
+	legal := []string{"v.", "Stat.", "Ann.", "U.S.C."}
+
+	segmenter, err := NewPunktSentenceTokenizer(AddingAbbreviations([]string{"v."}))
+	require.NoError(t, err)
+	doc, err := NewDocument("Smith v. Jones was decided.",
+		UsingSentenceTokenizer(segmenter), WithTagging(false), WithExtraction(false))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Smith v. Jones was decided."}, texts(doc.Sentences()))
+
+	text := "See 42 U.S.C. Stat. Ann. No. 5 applies. Acme Corp. sued."
+	expected := []string{"See 42 U.S.C. Stat. Ann. No. 5 applies.", "Acme Corp. sued."}
+	segmenter, err = NewPunktSentenceTokenizer(AddingAbbreviations(legal))
+	require.NoError(t, err)
+	for _, tokenizer := range []SentenceTokenizer{segmenter, NewRuleSentenceTokenizer(AddingAbbreviations(legal))} {
+		doc, err = NewDocument(text, UsingSentenceTokenizer(tokenizer),
+			WithTagging(false), WithExtraction(false))
+		require.NoError(t, err)
+		require.Equal(t, expected, texts(doc.Sentences()))
+	}
+
+	// Defaults can be removed.
+	text = "He lives on Main St. Smith lives nearby."
+	punkt, err := NewPunktSentenceTokenizer()
+	require.NoError(t, err)
+	require.Len(t, punkt.Segment(text), 1)
+	punkt, err = NewPunktSentenceTokenizer(RemovingAbbreviations([]string{"St."}))
+	require.NoError(t, err)
+	require.Len(t, punkt.Segment(text), 2)
+	require.Len(t, NewRuleSentenceTokenizer().Segment(text), 1)
+	require.Len(t, NewRuleSentenceTokenizer(RemovingAbbreviations([]string{"st"})).Segment(text), 2)
+
+	// Extra abbreviations don't hurt the golden rules.
+	tests := []goldenRule{}
+	require.NoError(t, json.Unmarshal(readDataFile(filepath.Join(testdata, "golden_rules_en.json"), t), &tests))
+	custom, err := NewPunktSentenceTokenizer(AddingAbbreviations(legal))
+	require.NoError(t, err)
+	punkt, err = NewPunktSentenceTokenizer()
+	require.NoError(t, err)
+	for _, test := range tests {
+		require.Equal(t, punkt.Segment(test.Input), custom.Segment(test.Input), test.Name)
+	}
+}
+
+func TestSegmenter(t *testing.T) {
+	segmenter, err := NewPunktSentenceTokenizer()
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name  string
+		Input string
+		Want  []string
+	}{
+		{"quoted speech", `"I'm leaving," she said. "Don't wait up." He nodded.`,
+			[]string{`"I'm leaving," she said.`, `"Don't wait up."`, "He nodded."}},
+		{"quoted question", `He paused. "Are you sure?" she asked. He was.`,
+			[]string{"He paused.", `"Are you sure?" she asked.`, "He was."}},
+		{"parentheses spanning a period", "He left (at 5 p.m. on Friday) and never came back. We waited.",
+			[]string{"He left (at 5 p.m. on Friday) and never came back.", "We waited."}},
+		{"parenthesized sentence", "The results were clear. (Nobody disputed them.) We moved on.",
+			[]string{"The results were clear.", "(Nobody disputed them.)", "We moved on."}},
+		{"no terminal punctuation", "no punctuation at all here",
+			[]string{"no punctuation at all here"}},
+		{"blank", " \n\t", []string{}},
+	}
+	for _, test := range tests {
+		got := []string{}
+		for _, sent := range segmenter.Segment(test.Input) {
+			got = append(got, sent.Text)
+		}
+		require.Equal(t, test.Want, got, test.Name)
+
+		// Documents segment text the same way.
+		doc, err := NewDocument(test.Input, WithTagging(false), WithExtraction(false))
+		require.NoError(t, err)
+		require.Equal(t, segmenter.Segment(test.Input), doc.Sentences(), test.Name)
+	}
+
+	custom, err := NewPunktSentenceTokenizer(AddingAbbreviations([]string{"Fig."}))
+	require.NoError(t, err)
+	require.Len(t, segmenter.Segment("See Fig. 2 below. It helps."), 3)
+	require.Len(t, custom.Segment("See Fig. 2 below. It helps."), 2)
+
+	rules := NewRuleSentenceTokenizer()
+	require.Len(t, rules.Segment("One. Two."), 2)
+	require.Empty(t, rules.Segment(" \n\t"))
+}
+
+// spacedTokenizer is a SentenceTokenizer that collapses the whitespace
+// within each line, which it treats as a sentence.
+type spacedTokenizer struct{}
+
+func (spacedTokenizer) Segment(text string) []Sentence {
+	sents := []Sentence{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			sents = append(sents, Sentence{Text: line})
+		}
+	}
+	return sents
+}
+
+func TestSentenceOffsets(t *testing.T) {
+	punkt, err := NewPunktSentenceTokenizer()
+	require.NoError(t, err)
+	rules := NewRuleSentenceTokenizer()
+
+	tests := []struct {
+		Name  string
+		Input string
+		Want  []string
+	}{
+		{"repeated", "I agree. You agree. I agree. You agree.",
+			[]string{"I agree.", "You agree.", "I agree.", "You agree."}},
+		{"surrounding whitespace", "  \tThe first one.   The second one.\n\n ",
+			[]string{"The first one.", "The second one."}},
+		{"Windows line endings", "The first line.\r\nThe second line.\r\n",
+			[]string{"The first line.", "The second line."}},
+	}
+	for _, test := range tests {
+		for _, segmenter := range []SentenceTokenizer{punkt, rules} {
+			got := []string{}
+			for _, sent := range segmenter.Segment(test.Input) {
+				require.Equal(t, sent.Text, test.Input[sent.Start:sent.End], test.Name)
+				got = append(got, sent.Text)
+			}
+			require.Equal(t, test.Want, got, test.Name)
+		}
+
+		doc, err := NewDocument(test.Input, WithTagging(false), WithExtraction(false))
+		require.NoError(t, err)
+		for _, sent := range doc.Sentences() {
+			require.Equal(t, sent.Text, doc.Text[sent.Start:sent.End], test.Name)
+		}
+	}
+
+	sents := punkt.Segment("I agree. You agree. I agree. You agree.")
+	require.Equal(t, []int{0, 9, 20, 29}, []int{sents[0].Start, sents[1].Start, sents[2].Start, sents[3].Start})
+
+	// Sentences whose whitespace was changed take the text they came from.
+	text := "Two   spaces\there.\nAnd  again."
+	doc, err := NewDocument(text, UsingSentenceTokenizer(spacedTokenizer{}),
+		WithTagging(false), WithExtraction(false))
+	require.NoError(t, err)
+	sents = doc.Sentences()
+	require.Equal(t, []Sentence{
+		{Text: "Two   spaces\there.", Start: 0, End: 18},
+		{Text: "And  again.", Start: 19, End: 30}}, sents)
+
+	// Offsets (and text) refer to the text before normalization.
+	doc, err = NewDocument("Ｆｕｌｌ ｗｉｄｔｈ. Then ASCII.", WithTagging(false),
+		WithExtraction(false), WithUnicodeNormalization(UnicodeNFKC))
+	require.NoError(t, err)
+	sents = doc.Sentences()
+	require.Len(t, sents, 2)
+	for _, sent := range sents {
+		require.Equal(t, doc.Text[sent.Start:sent.End], sent.Text)
+	}
+	require.Equal(t, "Ｆｕｌｌ ｗｉｄｔｈ.", sents[0].Text)
+	require.Equal(t, "Then ASCII.", sents[1].Text)
+}
+
+func TestAbbreviationTagger(t *testing.T) {
+	tagger, err := NewPerceptronTagger()
+	require.NoError(t, err)
+	punkt, err := NewPunktSentenceTokenizer(UsingAbbreviationTagger(tagger))
+	require.NoError(t, err)
+	rules := NewRuleSentenceTokenizer(UsingAbbreviationTagger(tagger))
+
+	tests := map[string][]string{
+		"He works at Acme Inc. He is the CEO.":  {"He works at Acme Inc.", "He is the CEO."},
+		"Acme Inc. Chairman John Doe resigned.": {"Acme Inc. Chairman John Doe resigned."},
+		"It was 5 p.m. Then we left.":           {"It was 5 p.m.", "Then we left."},
+		"The U.S. Army is here.":                {"The U.S. Army is here."},
+		"I met Dr. Smith at Acme Corp. The meeting went well.": {
+			"I met Dr. Smith at Acme Corp.", "The meeting went well."},
+		"John F. Kennedy spoke.": {"John F. Kennedy spoke."},
+	}
+	for text, want := range tests {
+		for _, segmenter := range []SentenceTokenizer{punkt, rules} {
+			got := []string{}
+			for _, sent := range segmenter.Segment(text) {
+				require.Equal(t, sent.Text, text[sent.Start:sent.End], text)
+				got = append(got, sent.Text)
+			}
+			require.Equal(t, want, got, text)
+		}
+	}
+
+	// Without the tagger, punkt splits after "Inc." here.
+	plain, err := NewPunktSentenceTokenizer()
+	require.NoError(t, err)
+	require.Len(t, plain.Segment("Acme Inc. Chairman John Doe resigned."), 2)
+	require.Empty(t, plain.Boundaries("Acme Inc. Chairman John Doe resigned."))
+
+	require.Equal(t, []BoundaryDecision{{
+		Offset: 9, Abbreviation: "Inc.", Next: "Chairman", Tag: "NNP",
+		Split: false, Changed: true, Reason: "the next word continues a sentence"}},
+		punkt.Boundaries("Acme Inc. Chairman John Doe resigned."))
+	decisions := rules.Boundaries("I met Dr. Smith at Acme Corp. The meeting went well.")
+	require.Len(t, decisions, 2)
+	require.Equal(t, "titles precede names", decisions[0].Reason)
+	require.True(t, decisions[1].Split)
+	require.True(t, decisions[1].Changed)
+
+	doc, err := NewDocument("Acme Inc. Chairman John Doe resigned. He was 64.",
+		WithAbbreviationTagging(true), WithExtraction(false))
+	require.NoError(t, err)
+	require.Len(t, doc.Sentences(), 2)
+	doc, err = NewDocument("It was 5 p.m. Then we left.", UsingSentenceTokenizer(NewRuleSentenceTokenizer()),
+		WithAbbreviationTagging(true), WithTagging(false), WithExtraction(false))
+	require.NoError(t, err)
+	require.Len(t, doc.Sentences(), 2)
+}
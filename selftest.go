@@ -0,0 +1,58 @@
+package prose
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selfTestText is the canonical input of SelfTest, and the rest are the
+// outputs expected of the embedded models.
+const selfTestText = "Barack Obama visited Paris on Monday. He met the president of France."
+
+var (
+	selfTestSentences = 2
+	selfTestTokens    = []string{
+		"Barack/NNP", "Obama/NNP", "visited/VBD", "Paris/NNP", "on/IN",
+		"Monday/NNP", "./.", "He/PRP", "met/VBD", "the/DT", "president/NN",
+		"of/IN", "France/NNP", "./."}
+	selfTestEntities = []string{"Barack Obama/PERSON", "Paris/GPE", "France/GPE"}
+)
+
+// SelfTest loads the embedded models and verifies that they segment, tag,
+// and extract the entities of a short canonical text as expected, e.g., for
+// a service's health check at startup. An error that matches
+// ErrCorruptModel means that the models loaded but produced other results,
+// which points to a corrupted build.
+func SelfTest() error {
+	model, err := defaultModel(true, true)
+	if err != nil {
+		return fmt.Errorf("self-test failed: %w", err)
+	}
+	doc, err := NewDocument(selfTestText, UsingModel(model))
+	if err != nil {
+		return fmt.Errorf("self-test failed: %w", err)
+	}
+
+	if n := len(doc.Sentences()); n != selfTestSentences {
+		return selfTestFailure("sentences", fmt.Sprint(selfTestSentences), fmt.Sprint(n))
+	}
+	tokens := []string{}
+	for _, tok := range doc.Tokens() {
+		tokens = append(tokens, tok.Text+"/"+tok.Tag)
+	}
+	if strings.Join(tokens, " ") != strings.Join(selfTestTokens, " ") {
+		return selfTestFailure("tokens", strings.Join(selfTestTokens, " "), strings.Join(tokens, " "))
+	}
+	entities := []string{}
+	for _, ent := range doc.Entities() {
+		entities = append(entities, ent.Text+"/"+ent.Label)
+	}
+	if strings.Join(entities, ", ") != strings.Join(selfTestEntities, ", ") {
+		return selfTestFailure("entities", strings.Join(selfTestEntities, ", "), strings.Join(entities, ", "))
+	}
+	return nil
+}
+
+func selfTestFailure(what, expected, actual string) error {
+	return withCode(ErrCorruptModel, fmt.Errorf("self-test failed: expected %s %q, got %q", what, expected, actual))
+}
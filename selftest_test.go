@@ -0,0 +1,16 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	require.NoError(t, SelfTest())
+
+	err := selfTestFailure("entities", "Paris/GPE", "Paris/PERSON")
+	assert.ErrorIs(t, err, ErrCorruptModel)
+	assert.Contains(t, err.Error(), `expected entities "Paris/GPE", got "Paris/PERSON"`)
+}
@@ -0,0 +1,133 @@
+package prose
+
+import "strings"
+
+// A Sentiment scores the opinion expressed by a span of text.
+type Sentiment struct {
+	Polarity     float64 // From -1 (negative) to 1 (positive).
+	Subjectivity float64 // From 0 (objective) to 1 (subjective).
+}
+
+// WithSentiment can enable or disable (the default) sentiment scoring of the
+// Document and its sentences.
+func WithSentiment(include bool) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Sentiment = include
+	}
+}
+
+// Sentiment returns the sentiment of `doc` as a whole; it's the zero value
+// unless requested with WithSentiment.
+func (doc *Document) Sentiment() Sentiment {
+	return doc.sentiment
+}
+
+// Sentiments returns the sentiment of each of `doc`'s sentences; it's nil
+// unless requested with WithSentiment.
+func (doc *Document) Sentiments() []Sentiment {
+	return doc.sentiments
+}
+
+// ScoreSentiment scores `tokens` with a lexicon of opinion words.
+//
+// Negations ("not", "never", "n't", ...) flip the polarity of the opinion
+// words that follow them within a short window, and intensifiers ("very",
+// "extremely", ...) strengthen the next opinion word.
+func ScoreSentiment(tokens []*Token) Sentiment {
+	polarity, subjectivity := 0.0, 0.0
+	opinions := 0
+
+	negated, boost := 0, 1.0
+	for _, tok := range tokens {
+		word := strings.ToLower(tok.Text)
+		if stringInSlice(word, negations) {
+			negated = negationWindow
+			continue
+		} else if factor, found := intensifiers[word]; found {
+			boost = factor
+			continue
+		}
+
+		if entry, found := sentimentLexicon[word]; found {
+			p := entry[0] * boost
+			if negated > 0 {
+				// Negation weakens as well as flips: "not bad" isn't "good".
+				p *= -0.5
+			}
+			polarity += p
+			subjectivity += entry[1]
+			opinions++
+			boost = 1.0
+		}
+		if isPunctToken(word) {
+			negated = 0
+		} else if negated > 0 {
+			negated--
+		}
+	}
+
+	if opinions == 0 {
+		return Sentiment{}
+	}
+	return Sentiment{
+		Polarity:     clamp(polarity/float64(opinions), -1, 1),
+		Subjectivity: clamp(subjectivity/float64(opinions), 0, 1)}
+}
+
+func clamp(x, lo, hi float64) float64 {
+	if x < lo {
+		return lo
+	} else if x > hi {
+		return hi
+	}
+	return x
+}
+
+// negationWindow is the number of tokens a negation applies to.
+const negationWindow = 3
+
+var negations = []string{
+	"not", "n't", "no", "never", "neither", "nor", "without", "hardly", "nothing"}
+
+var intensifiers = map[string]float64{
+	"very": 1.3, "really": 1.3, "extremely": 1.5, "highly": 1.3, "so": 1.2,
+	"quite": 1.1, "too": 1.2, "incredibly": 1.5, "totally": 1.3,
+	"slightly": 0.6, "somewhat": 0.7, "barely": 0.5,
+}
+
+// sentimentLexicon maps opinion words to their polarity and subjectivity.
+var sentimentLexicon = map[string][2]float64{
+	// Positive
+	"good": {0.7, 0.6}, "great": {0.8, 0.75}, "excellent": {1.0, 1.0},
+	"amazing": {0.6, 0.9}, "awesome": {1.0, 1.0}, "wonderful": {1.0, 1.0},
+	"fantastic": {0.4, 0.9}, "best": {1.0, 0.3}, "better": {0.5, 0.5},
+	"nice": {0.6, 1.0}, "happy": {0.8, 1.0}, "glad": {0.5, 1.0},
+	"love": {0.5, 0.6}, "loved": {0.7, 0.8}, "like": {0.2, 0.3},
+	"enjoy": {0.4, 0.5}, "enjoyed": {0.5, 0.6}, "beautiful": {0.85, 1.0},
+	"perfect": {1.0, 1.0}, "pleasant": {0.7, 0.8}, "impressive": {1.0, 1.0},
+	"favorable": {0.6, 0.7}, "helpful": {0.5, 0.6}, "reliable": {0.5, 0.5},
+	"recommend": {0.4, 0.5}, "satisfied": {0.5, 0.7}, "success": {0.3, 0.3},
+	"successful": {0.75, 0.95}, "strong": {0.4, 0.7}, "positive": {0.2, 0.5},
+	"fair": {0.4, 0.6}, "easy": {0.4, 0.8}, "fast": {0.2, 0.6},
+	"useful": {0.3, 0.0}, "valuable": {0.5, 0.6}, "remarkable": {0.75, 0.75},
+	"brilliant": {0.9, 1.0}, "superb": {1.0, 1.0}, "delightful": {1.0, 1.0},
+	"outstanding": {0.5, 0.5}, "thrilled": {0.8, 0.9}, "pleased": {0.5, 1.0},
+	"fine": {0.4, 0.5}, "benefit": {0.3, 0.4}, "improved": {0.4, 0.5},
+	// Negative
+	"bad": {-0.7, 0.67}, "worse": {-0.4, 0.6}, "worst": {-1.0, 1.0},
+	"terrible": {-1.0, 1.0}, "awful": {-1.0, 1.0}, "horrible": {-1.0, 1.0},
+	"poor": {-0.4, 0.6}, "sad": {-0.5, 1.0}, "angry": {-0.5, 1.0},
+	"hate": {-0.8, 0.9}, "hated": {-0.9, 0.9}, "dislike": {-0.5, 0.6},
+	"disappointed": {-0.75, 0.75}, "disappointing": {-0.6, 0.7},
+	"ugly": {-0.7, 1.0}, "wrong": {-0.5, 0.9}, "broken": {-0.4, 0.4},
+	"useless": {-0.5, 0.2}, "annoying": {-0.8, 0.9}, "boring": {-1.0, 1.0},
+	"slow": {-0.3, 0.4}, "difficult": {-0.5, 1.0}, "hard": {-0.3, 0.5},
+	"expensive": {-0.5, 0.7}, "failure": {-0.3, 0.3}, "failed": {-0.5, 0.3},
+	"fail": {-0.5, 0.3}, "problem": {-0.3, 0.3}, "unfair": {-0.6, 0.8},
+	"negative": {-0.3, 0.4}, "weak": {-0.4, 0.6}, "unhappy": {-0.6, 0.9},
+	"unreliable": {-0.5, 0.6}, "dangerous": {-0.6, 0.9}, "mediocre": {-0.5, 0.8},
+	"painful": {-0.7, 0.9}, "pathetic": {-1.0, 1.0}, "disaster": {-0.8, 0.8},
+	"unacceptable": {-0.8, 0.8}, "inferior": {-0.6, 0.7}, "lousy": {-0.8, 0.9},
+	"breach": {-0.4, 0.3}, "defective": {-0.6, 0.5}, "delay": {-0.3, 0.3},
+	"delayed": {-0.3, 0.3}, "loss": {-0.4, 0.3}, "damage": {-0.5, 0.4},
+}
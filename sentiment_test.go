@@ -0,0 +1,38 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreSentiment(t *testing.T) {
+	tokenizer := NewIterTokenizer()
+	score := func(text string) Sentiment {
+		return ScoreSentiment(tokenizer.Tokenize(text))
+	}
+
+	assert.Greater(t, score("The service was good.").Polarity, 0.0)
+	assert.Less(t, score("The service wasn't good.").Polarity, 0.0)
+	assert.Greater(t, score("The service was not bad.").Polarity, 0.0)
+	assert.Greater(t, score("It was very good.").Polarity, score("It was good.").Polarity)
+	assert.Equal(t, Sentiment{}, score("The meeting is on Tuesday."))
+	assert.Greater(t, score("What a terrible, boring film.").Subjectivity, 0.5)
+}
+
+func TestDocumentSentiment(t *testing.T) {
+	doc, err := NewDocument("The food was excellent. The service was terrible.",
+		WithSentiment(true))
+	require.NoError(t, err)
+
+	sents := doc.Sentiments()
+	require.Len(t, sents, 2)
+	assert.Greater(t, sents[0].Polarity, 0.0)
+	assert.Less(t, sents[1].Polarity, 0.0)
+	assert.Greater(t, doc.Sentiment().Subjectivity, 0.0)
+
+	doc, err = NewDocument("The food was excellent.")
+	require.NoError(t, err)
+	assert.Nil(t, doc.Sentiments())
+}
@@ -0,0 +1,60 @@
+package sequence
+
+import "strings"
+
+// A Span is a labeled range of tokens, [Start, End).
+type Span struct {
+	Start int
+	End   int
+	Label string
+}
+
+// EncodeBIO returns the BIO labels ("B-<label>", "I-<label>", and "O") of a
+// sequence of `n` tokens containing `spans`.
+func EncodeBIO(n int, spans []Span) []string {
+	labels := make([]string, n)
+	for i := range labels {
+		labels[i] = "O"
+	}
+	for _, span := range spans {
+		for i := span.Start; i < span.End && i < n; i++ {
+			if i == span.Start {
+				labels[i] = "B-" + span.Label
+			} else {
+				labels[i] = "I-" + span.Label
+			}
+		}
+	}
+	return labels
+}
+
+// DecodeBIO returns the spans described by BIO `labels`.
+//
+// An "I-" label that doesn't continue a span of the same type starts a new
+// one.
+func DecodeBIO(labels []string) []Span {
+	spans := []Span{}
+	open := -1
+	for i, label := range labels {
+		prefix, name := splitBIO(label)
+		if open >= 0 && (prefix != "I" || name != spans[open].Label) {
+			spans[open].End = i
+			open = -1
+		}
+		if prefix == "B" || (prefix == "I" && open < 0) {
+			spans = append(spans, Span{Start: i, Label: name})
+			open = len(spans) - 1
+		}
+	}
+	if open >= 0 {
+		spans[open].End = len(labels)
+	}
+	return spans
+}
+
+func splitBIO(label string) (string, string) {
+	if strings.HasPrefix(label, "B-") || strings.HasPrefix(label, "I-") {
+		return label[:1], label[2:]
+	}
+	return "O", ""
+}
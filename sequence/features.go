@@ -0,0 +1,68 @@
+package sequence
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WordFeatures is a FeatureFunc based on the token, its neighbors, its shape
+// and affixes, and the previous two labels.
+//
+// It's a reasonable starting point for most tasks; custom FeatureFuncs can
+// extend it.
+func WordFeatures(tokens []string, i int, history []string) []string {
+	word := tokens[i]
+	lower := strings.ToLower(word)
+	return []string{
+		"bias",
+		"word=" + lower,
+		"shape=" + shape(word),
+		"prefix=" + affix(lower, 3, false),
+		"suffix=" + affix(lower, 3, true),
+		"prev=" + at(tokens, i-1),
+		"next=" + at(tokens, i+1),
+		"prevlabel=" + at(history, i-1),
+		"prevlabels=" + at(history, i-2) + "+" + at(history, i-1),
+	}
+}
+
+func at(items []string, i int) string {
+	if i < 0 {
+		return "<start>"
+	} else if i >= len(items) {
+		return "<end>"
+	}
+	return strings.ToLower(items[i])
+}
+
+func affix(word string, n int, suffix bool) string {
+	runes := []rune(word)
+	if len(runes) <= n {
+		return word
+	} else if suffix {
+		return string(runes[len(runes)-n:])
+	}
+	return string(runes[:n])
+}
+
+// shape maps `word` to a coarse pattern such as "Xx" or "d".
+func shape(word string) string {
+	var b strings.Builder
+	last := rune(0)
+	for _, r := range word {
+		c := 'p'
+		switch {
+		case unicode.IsUpper(r):
+			c = 'X'
+		case unicode.IsLower(r):
+			c = 'x'
+		case unicode.IsDigit(r):
+			c = 'd'
+		}
+		if c != last {
+			b.WriteRune(c)
+			last = c
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,308 @@
+// Package sequence provides a general-purpose sequence labeler: a maximum
+// entropy classifier, trained with Generalized Iterative Scaling (GIS), that
+// labels each token of a sequence given its features and the labels already
+// assigned to the tokens before it.
+//
+// It's the same approach prose uses for named-entity recognition, but with
+// user-defined features and label sets, so it can be used for other span
+// tasks (e.g., clause types, section headers, or PII categories).
+package sequence
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/zuvaai/prose/v3/internal/gis"
+)
+
+// A FeatureFunc returns the (binary) features of the token at position `i`
+// of `tokens`, given the labels assigned to the tokens before it.
+type FeatureFunc func(tokens []string, i int, history []string) []string
+
+// An Example is a labeled sequence used for training.
+type Example struct {
+	Tokens []string
+	Labels []string
+}
+
+// TrainingOptions controls the training of a Labeler.
+type TrainingOptions struct {
+	// Iterations is the number of GIS iterations (default: 100).
+	Iterations int
+}
+
+// A Labeler assigns a label to each token of a sequence.
+type Labeler struct {
+	features FeatureFunc
+	labels   []string
+	mapping  map[string]map[string]int // feature -> label -> weight index
+	weights  []float64
+	// correction is the weight index of each label's GIS correction feature.
+	correction map[string]int
+	// cardinality is the (constant) total feature count used by GIS.
+	cardinality int
+}
+
+// Train returns a Labeler trained on `data` using `features`.
+func Train(data []Example, features FeatureFunc, opts TrainingOptions) (*Labeler, error) {
+	if features == nil {
+		return nil, errors.New("unable to train: no feature function")
+	} else if len(data) == 0 {
+		return nil, errors.New("unable to train: no examples")
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = 100
+	}
+
+	corpus := []instance{}
+	for i, ex := range data {
+		if len(ex.Tokens) != len(ex.Labels) {
+			return nil, fmt.Errorf(
+				"unable to train: example %d has %d tokens but %d labels",
+				i, len(ex.Tokens), len(ex.Labels))
+		}
+		for j := range ex.Tokens {
+			corpus = append(corpus, instance{
+				label:    ex.Labels[j],
+				features: dedupe(features(ex.Tokens, j, ex.Labels[:j]))})
+		}
+	}
+
+	l := encode(corpus, features)
+	err := gis.Train(&trainingSet{l: l, instances: corpus}, l.weights, gis.Options{
+		Cardinality: l.cardinality,
+		Iterations:  opts.Iterations,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// labelerData is the serialized form of a Labeler; its feature function
+// can't be serialized, so it's supplied to ReadLabeler.
+type labelerData struct {
+	Labels      []string
+	Mapping     map[string]map[string]int
+	Weights     []float64
+	Correction  map[string]int
+	Cardinality int
+}
+
+// WriteTo writes `l`, except for its feature function, to `w` as
+// ReadLabeler reads it.
+func (l *Labeler) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{w: w}
+	err := gob.NewEncoder(counter).Encode(labelerData{
+		Labels:      l.labels,
+		Mapping:     l.mapping,
+		Weights:     l.weights,
+		Correction:  l.correction,
+		Cardinality: l.cardinality,
+	})
+	if err != nil {
+		return counter.n, fmt.Errorf("unable to write labeler: %w", err)
+	}
+	return counter.n, nil
+}
+
+// ReadLabeler reads a Labeler written by WriteTo. `features` must be the
+// feature function it was trained with.
+func ReadLabeler(r io.Reader, features FeatureFunc) (*Labeler, error) {
+	if features == nil {
+		return nil, errors.New("unable to read labeler: no feature function")
+	}
+	var data labelerData
+	if err := gob.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("unable to read labeler: %w", err)
+	}
+
+	l := &Labeler{
+		features:    features,
+		labels:      data.Labels,
+		mapping:     data.Mapping,
+		weights:     data.Weights,
+		correction:  data.Correction,
+		cardinality: data.Cardinality,
+	}
+	if err := l.check(); err != nil {
+		return nil, fmt.Errorf("unable to read labeler: %w", err)
+	}
+	return l, nil
+}
+
+// check determines if the weight indices of `l` are consistent with its
+// weights, so that a corrupt labeler is rejected when it's read rather than
+// when it's used.
+func (l *Labeler) check() error {
+	if len(l.labels) == 0 {
+		return errors.New("no labels")
+	}
+	for _, labels := range l.mapping {
+		for _, idx := range labels {
+			if idx < 0 || idx >= len(l.weights) {
+				return fmt.Errorf("weight index %d out of range", idx)
+			}
+		}
+	}
+	for _, label := range l.labels {
+		idx, found := l.correction[label]
+		if !found || idx < 0 || idx >= len(l.weights) {
+			return fmt.Errorf("no correction feature for label %q", label)
+		}
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Labels returns the set of labels known to `l`, sorted.
+func (l *Labeler) Labels() []string {
+	return append([]string{}, l.labels...)
+}
+
+// Label returns the most likely label of each of `tokens`, assigned
+// greedily from left to right.
+func (l *Labeler) Label(tokens []string) []string {
+	history := make([]string, 0, len(tokens))
+	for i := range tokens {
+		features := dedupe(l.features(tokens, i, history))
+		best, bestScore := "", math.Inf(-1)
+		for _, label := range l.labels {
+			if score := l.score(features, label); score > bestScore {
+				best, bestScore = label, score
+			}
+		}
+		history = append(history, best)
+	}
+	return history
+}
+
+// Probabilities returns the probability of each label for the token at
+// position `i` of `tokens`, given the labels of the tokens before it.
+func (l *Labeler) Probabilities(tokens []string, i int, history []string) map[string]float64 {
+	return l.probabilities(dedupe(l.features(tokens, i, history)))
+}
+
+type instance struct {
+	label    string
+	features []string
+}
+
+// encode builds the (feature, label) index of `corpus`: only pairs that
+// occur in the training data get a weight.
+func encode(corpus []instance, features FeatureFunc) *Labeler {
+	l := &Labeler{
+		features:   features,
+		mapping:    map[string]map[string]int{},
+		correction: map[string]int{},
+	}
+
+	seen := map[string]bool{}
+	size := 0
+	for _, in := range corpus {
+		if !seen[in.label] {
+			seen[in.label] = true
+			l.labels = append(l.labels, in.label)
+		}
+		for _, f := range in.features {
+			if l.mapping[f] == nil {
+				l.mapping[f] = map[string]int{}
+			}
+			if _, found := l.mapping[f][in.label]; !found {
+				l.mapping[f][in.label] = size
+				size++
+			}
+		}
+		if len(in.features) > l.cardinality {
+			l.cardinality = len(in.features)
+		}
+	}
+	sort.Strings(l.labels)
+
+	// The correction feature brings every instance's feature count up to
+	// the same total, as GIS requires.
+	l.cardinality++
+	for _, label := range l.labels {
+		l.correction[label] = size
+		size++
+	}
+	l.weights = make([]float64, size)
+
+	return l
+}
+
+// active returns the joint-features of `features` for `label`.
+func (l *Labeler) active(features []string, label string) []gis.Feature {
+	active := make([]gis.Feature, 0, len(features)+1)
+	for _, f := range features {
+		if idx, found := l.mapping[f][label]; found {
+			active = append(active, gis.Feature{Index: idx, Value: 1})
+		}
+	}
+	return append(active, gis.Feature{
+		Index: l.correction[label],
+		Value: float64(l.cardinality - len(features))})
+}
+
+func (l *Labeler) score(features []string, label string) float64 {
+	return gis.Score(l.weights, l.active(features, label))
+}
+
+func (l *Labeler) probabilities(features []string) map[string]float64 {
+	scores := make([]float64, len(l.labels))
+	for i, label := range l.labels {
+		scores[i] = l.score(features, label)
+	}
+	gis.Normalize(scores)
+
+	probs := make(map[string]float64, len(l.labels))
+	for i, label := range l.labels {
+		probs[label] = math.Exp2(scores[i])
+	}
+	return probs
+}
+
+// trainingSet adapts a training corpus, encoded by a Labeler, to the GIS
+// trainer.
+type trainingSet struct {
+	l         *Labeler
+	instances []instance
+}
+
+func (t *trainingSet) Len() int    { return len(t.instances) }
+func (t *trainingSet) Labels() int { return len(t.l.labels) }
+
+func (t *trainingSet) Label(i int) int {
+	return sort.SearchStrings(t.l.labels, t.instances[i].label)
+}
+
+func (t *trainingSet) Encode(i, j int) []gis.Feature {
+	return t.l.active(t.instances[i].features, t.l.labels[j])
+}
+
+// dedupe removes duplicate features, since each is binary.
+func dedupe(features []string) []string {
+	seen := make(map[string]bool, len(features))
+	unique := features[:0:0]
+	for _, f := range features {
+		if !seen[f] {
+			seen[f] = true
+			unique = append(unique, f)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,78 @@
+package sequence
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBIO(t *testing.T) {
+	spans := []Span{{Start: 0, End: 2, Label: "NAME"}, {Start: 3, End: 4, Label: "EMAIL"}}
+	labels := EncodeBIO(5, spans)
+	assert.Equal(t, []string{"B-NAME", "I-NAME", "O", "B-EMAIL", "O"}, labels)
+	assert.Equal(t, spans, DecodeBIO(labels))
+
+	// A dangling "I-" starts a new span; a label change ends one.
+	assert.Equal(t,
+		[]Span{{Start: 1, End: 2, Label: "A"}, {Start: 2, End: 3, Label: "B"}},
+		DecodeBIO([]string{"O", "I-A", "I-B"}))
+}
+
+func TestLabeler(t *testing.T) {
+	data := []Example{}
+	for _, line := range []string{
+		"Contact Jane Smith at jane@example.com today",
+		"Please email bob@example.org or call Bob Jones",
+		"Mary Brown wrote to mary@example.net",
+		"Send it to John Green please",
+	} {
+		tokens := strings.Fields(line)
+		labels := make([]string, len(tokens))
+		for i, tok := range tokens {
+			switch {
+			case strings.Contains(tok, "@"):
+				labels[i] = "B-EMAIL"
+			case tok[0] >= 'A' && tok[0] <= 'Z' && i > 0 && labels[i-1] == "B-NAME":
+				labels[i] = "I-NAME"
+			case tok[0] >= 'A' && tok[0] <= 'Z' && (i > 0 || tok == "Mary"):
+				labels[i] = "B-NAME"
+			default:
+				labels[i] = "O"
+			}
+		}
+		data = append(data, Example{Tokens: tokens, Labels: labels})
+	}
+
+	labeler, err := Train(data, WordFeatures, TrainingOptions{Iterations: 50})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"B-EMAIL", "B-NAME", "I-NAME", "O"}, labeler.Labels())
+
+	for _, ex := range data {
+		assert.Equal(t, ex.Labels, labeler.Label(ex.Tokens))
+	}
+
+	tokens := strings.Fields("Call Ann Lee at ann@example.com")
+	probs := labeler.Probabilities(tokens, 4, []string{"O", "B-NAME", "I-NAME", "O"})
+	assert.InDelta(t, 1.0, probs["B-EMAIL"]+probs["B-NAME"]+probs["I-NAME"]+probs["O"], 1e-9)
+
+	_, err = Train([]Example{{Tokens: []string{"a"}}}, WordFeatures, TrainingOptions{})
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	n, err := labeler.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	loaded, err := ReadLabeler(bytes.NewReader(buf.Bytes()), WordFeatures)
+	require.NoError(t, err)
+	assert.Equal(t, labeler.Labels(), loaded.Labels())
+	assert.Equal(t, labeler.Label(tokens), loaded.Label(tokens))
+
+	_, err = ReadLabeler(bytes.NewReader(buf.Bytes()), nil)
+	assert.Error(t, err)
+	_, err = ReadLabeler(strings.NewReader("not a labeler"), WordFeatures)
+	assert.Error(t, err)
+}
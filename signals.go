@@ -0,0 +1,62 @@
+package prose
+
+import "math"
+
+// Signals summarizes a Document's entities for routing and triage.
+type Signals struct {
+	// EntityDensity is the number of entities per token.
+	EntityDensity float64
+
+	// Novelty is the fraction of entities whose text isn't among the known
+	// entities (see UsingKnownEntities); without any, every entity is novel.
+	Novelty float64
+
+	// Labels maps entity labels to their frequency.
+	Labels map[string]int
+
+	// LabelSkew measures how unevenly entities are spread over their labels,
+	// from 0 (evenly) to 1 (all the same label).
+	LabelSkew float64
+}
+
+// UsingKnownEntities provides the entity texts that Document.Signals
+// considers known, rather than novel.
+func UsingKnownEntities(known map[string]bool) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.KnownEntities = known
+	}
+}
+
+// Signals computes `doc`'s Signals.
+func (doc *Document) Signals() Signals {
+	signals := Signals{Labels: map[string]int{}}
+	if len(doc.entities) == 0 {
+		return signals
+	}
+
+	novel := 0
+	for _, ent := range doc.entities {
+		signals.Labels[ent.Label]++
+		if !doc.config.KnownEntities[ent.Text] {
+			novel++
+		}
+	}
+	total := float64(len(doc.entities))
+	signals.Novelty = float64(novel) / total
+	if len(doc.tokens) > 0 {
+		signals.EntityDensity = total / float64(len(doc.tokens))
+	}
+
+	// LabelSkew is one minus the labels' normalized entropy.
+	signals.LabelSkew = 1
+	if len(signals.Labels) > 1 {
+		entropy := 0.0
+		for _, n := range signals.Labels {
+			p := float64(n) / total
+			entropy -= p * math.Log2(p)
+		}
+		signals.LabelSkew = 1 - entropy/math.Log2(float64(len(signals.Labels)))
+	}
+
+	return signals
+}
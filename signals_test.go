@@ -0,0 +1,28 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignals(t *testing.T) {
+	doc := &Document{
+		tokens: make([]*Token, 10),
+		entities: []Entity{
+			{Text: "Acme", Label: "ORG"}, {Text: "Globex", Label: "ORG"},
+			{Text: "Jane", Label: "PERSON"}, {Text: "Bob", Label: "PERSON"}},
+	}
+	UsingKnownEntities(map[string]bool{"Acme": true})(doc, &doc.config)
+
+	signals := doc.Signals()
+	assert.InDelta(t, 0.4, signals.EntityDensity, 1e-9)
+	assert.InDelta(t, 0.75, signals.Novelty, 1e-9)
+	assert.Equal(t, map[string]int{"ORG": 2, "PERSON": 2}, signals.Labels)
+	assert.InDelta(t, 0.0, signals.LabelSkew, 1e-9)
+
+	doc.entities = doc.entities[:2]
+	assert.InDelta(t, 1.0, doc.Signals().LabelSkew, 1e-9)
+
+	assert.Equal(t, Signals{Labels: map[string]int{}}, (&Document{}).Signals())
+}
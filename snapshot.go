@@ -0,0 +1,86 @@
+package prose
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// A DataSnapshot summarizes a set of labeled data, so that a model can be
+// traced to the data it was trained or tested with.
+type DataSnapshot struct {
+	// Hash is the hex-encoded SHA-256 of the examples, in order.
+	Hash string `json:"hash"`
+
+	// Examples is the number of examples.
+	Examples int `json:"examples"`
+
+	// Labels is the number of entities with each label.
+	Labels map[string]int `json:"labels"`
+}
+
+// A DataManifest describes the data a Model's NER was trained with; see
+// TrainingOptions.Snapshot.
+type DataManifest struct {
+	Training DataSnapshot  `json:"training"`
+	Test     *DataSnapshot `json:"test,omitempty"`
+
+	// Sources names where the data came from, as given by
+	// TrainingOptions.Sources.
+	Sources []string `json:"sources,omitempty"`
+}
+
+// SnapshotData summarizes `data`. Equal data always have the same Hash,
+// regardless of how it was loaded.
+func SnapshotData(data []EntityContext) DataSnapshot {
+	h := sha256.New()
+	labels := make(map[string]int)
+	for _, example := range data {
+		// Each field is length-prefixed, so that no two different sets of
+		// examples hash the same input.
+		fmt.Fprintf(h, "%t %d %d:%s", example.Accept, len(example.Spans),
+			len(example.Text), example.Text)
+		for _, span := range example.Spans {
+			fmt.Fprintf(h, " %d %d %d:%s", span.Start, span.End, len(span.Label), span.Label)
+			labels[span.Label]++
+		}
+		h.Write([]byte{'\n'})
+	}
+	return DataSnapshot{
+		Hash:     hex.EncodeToString(h.Sum(nil)),
+		Examples: len(data),
+		Labels:   labels,
+	}
+}
+
+// String returns a one-line summary of `s`.
+func (s DataSnapshot) String() string {
+	return strconv.Itoa(s.Examples) + " examples, sha256:" + s.Hash
+}
+
+// Manifest returns the description of the data the Model's NER was trained
+// with, if it was saved (see TrainingOptions.Snapshot).
+func (m *Model) Manifest() (DataManifest, bool) {
+	if m.extracter == nil || m.extracter.manifest == nil {
+		return DataManifest{}, false
+	}
+	return *m.extracter.manifest, true
+}
+
+// newManifest creates the DataManifest for training with `data` according to
+// `opts`, if it asks for one.
+func newManifest(data []EntityContext, opts TrainingOptions) *DataManifest {
+	if !opts.Snapshot {
+		return nil
+	}
+	manifest := &DataManifest{
+		Training: SnapshotData(data),
+		Sources:  opts.Sources,
+	}
+	if opts.TestData != nil {
+		test := SnapshotData(opts.TestData)
+		manifest.Test = &test
+	}
+	return manifest
+}
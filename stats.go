@@ -0,0 +1,216 @@
+package prose
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+// CorpusStats accumulates summary statistics over a stream of Documents
+// without holding on to them.
+//
+// By default, the vocabulary is counted exactly; for very large corpora,
+// UsingSketch trades exactness for a fixed memory footprint.
+type CorpusStats struct {
+	Documents int            // The number of Documents added.
+	Sentences int            // The number of sentences seen.
+	Tokens    int            // The number of tokens seen.
+	Entities  int            // The number of entities seen.
+	Tags      map[string]int // Maps POS tags to their frequency.
+	Labels    map[string]int // Maps entity labels to their frequency.
+
+	lower  bool
+	vocab  map[string]int
+	sketch *countMinSketch
+}
+
+// StatsOptFunc configures a CorpusStats accumulator.
+type StatsOptFunc func(*CorpusStats)
+
+// UsingSketch counts the vocabulary with a count-min sketch of the given
+// dimensions instead of an exact map.
+//
+// Counts are then approximate (they may over-count, but never under-count)
+// and the distinct vocabulary is no longer available.
+func UsingSketch(width, depth int) StatsOptFunc {
+	return func(stats *CorpusStats) {
+		stats.sketch = newCountMinSketch(width, depth)
+		stats.vocab = nil
+	}
+}
+
+// UsingLowercase folds the vocabulary to lower case before counting.
+func UsingLowercase() StatsOptFunc {
+	return func(stats *CorpusStats) {
+		stats.lower = true
+	}
+}
+
+// NewCorpusStats creates an empty CorpusStats accumulator.
+func NewCorpusStats(opts ...StatsOptFunc) *CorpusStats {
+	stats := &CorpusStats{
+		Tags:   make(map[string]int),
+		Labels: make(map[string]int),
+		vocab:  make(map[string]int),
+	}
+	for _, applyOpt := range opts {
+		applyOpt(stats)
+	}
+	return stats
+}
+
+// Add accumulates the statistics of `doc`.
+func (s *CorpusStats) Add(doc *Document) {
+	s.Documents++
+	s.Sentences += len(doc.sentences)
+	s.Tokens += len(doc.tokens)
+	s.Entities += len(doc.entities)
+	for _, tok := range doc.tokens {
+		if tok.Tag != "" {
+			s.Tags[tok.Tag]++
+		}
+		word := tok.Text
+		if s.lower {
+			word = strings.ToLower(word)
+		}
+		if s.sketch != nil {
+			s.sketch.add(word)
+		} else {
+			s.vocab[word]++
+		}
+	}
+	for _, ent := range doc.entities {
+		s.Labels[ent.Label]++
+	}
+}
+
+// Count returns the number of times `word` has been seen.
+func (s *CorpusStats) Count(word string) int {
+	if s.lower {
+		word = strings.ToLower(word)
+	}
+	if s.sketch != nil {
+		return s.sketch.count(word)
+	}
+	return s.vocab[word]
+}
+
+// Vocabulary returns the distinct words seen and their counts, or nil if the
+// vocabulary is being counted with a sketch.
+func (s *CorpusStats) Vocabulary() map[string]int {
+	return s.vocab
+}
+
+// An EntityCount records how often an entity occurs across a collection of
+// Documents.
+type EntityCount struct {
+	Text      string // The entity's text.
+	Label     string // The entity's label; empty unless counted by label.
+	Documents int    // The number of Documents that contain the entity.
+	Count     int    // The total number of occurrences of the entity.
+}
+
+// TopEntities returns the `n` entities that occur in the most Documents
+// (ties are broken by total occurrences, then text); n <= 0 returns all of
+// them.
+//
+// If `byLabel` is true, entities with the same text but different labels
+// are counted separately.
+func TopEntities(docs []*Document, n int, byLabel bool) []EntityCount {
+	type entityKey struct{ text, label string }
+
+	counts := map[entityKey]*EntityCount{}
+	for _, doc := range docs {
+		seen := map[entityKey]bool{}
+		for _, ent := range doc.Entities() {
+			key := entityKey{text: ent.Text}
+			if byLabel {
+				key.label = ent.Label
+			}
+			count, found := counts[key]
+			if !found {
+				count = &EntityCount{Text: key.text, Label: key.label}
+				counts[key] = count
+			}
+			count.Count++
+			if !seen[key] {
+				seen[key] = true
+				count.Documents++
+			}
+		}
+	}
+
+	ranked := make([]EntityCount, 0, len(counts))
+	for _, count := range counts {
+		ranked = append(ranked, *count)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Documents != b.Documents {
+			return a.Documents > b.Documents
+		} else if a.Count != b.Count {
+			return a.Count > b.Count
+		} else if a.Text != b.Text {
+			return a.Text < b.Text
+		}
+		return a.Label < b.Label
+	})
+
+	if n > 0 && n < len(ranked) {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// countMinSketch is a probabilistic frequency table using `depth` rows of
+// `width` counters each.
+type countMinSketch struct {
+	width  int
+	counts [][]int
+}
+
+func newCountMinSketch(width, depth int) *countMinSketch {
+	if width < 1 {
+		width = 1
+	}
+	if depth < 1 {
+		depth = 1
+	}
+	counts := make([][]int, depth)
+	for i := range counts {
+		counts[i] = make([]int, width)
+	}
+	return &countMinSketch{width: width, counts: counts}
+}
+
+func (c *countMinSketch) add(key string) {
+	h1, h2 := sketchHashes(key)
+	for i, row := range c.counts {
+		row[c.column(h1, h2, i)]++
+	}
+}
+
+func (c *countMinSketch) count(key string) int {
+	h1, h2 := sketchHashes(key)
+	least := -1
+	for i, row := range c.counts {
+		n := row[c.column(h1, h2, i)]
+		if least < 0 || n < least {
+			least = n
+		}
+	}
+	return least
+}
+
+// column derives the i-th row's hash from two base hashes (Kirsch-Mitzenmacher).
+func (c *countMinSketch) column(h1, h2 uint32, i int) int {
+	return int((h1 + uint32(i)*h2) % uint32(c.width))
+}
+
+func sketchHashes(key string) (uint32, uint32) {
+	a := fnv.New32a()
+	_, _ = a.Write([]byte(key))
+	b := fnv.New32()
+	_, _ = b.Write([]byte(key))
+	return a.Sum32(), b.Sum32() | 1
+}
@@ -0,0 +1,57 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorpusStats(t *testing.T) {
+	exact := NewCorpusStats(UsingLowercase())
+	sketch := NewCorpusStats(UsingLowercase(), UsingSketch(1024, 4))
+	for _, text := range []string{
+		"The dog barked. The cat ran.",
+		"the end",
+	} {
+		doc, err := NewDocument(text)
+		require.NoError(t, err)
+		exact.Add(doc)
+		sketch.Add(doc)
+	}
+
+	assert.Equal(t, 2, exact.Documents)
+	assert.Equal(t, 3, exact.Sentences)
+	assert.Equal(t, 10, exact.Tokens)
+	assert.Equal(t, 3, exact.Tags["DT"])
+	assert.Equal(t, 3, exact.Count("The"))
+	assert.Equal(t, 0, exact.Count("horse"))
+	assert.Len(t, exact.Vocabulary(), 7)
+
+	assert.Nil(t, sketch.Vocabulary())
+	for word, n := range exact.Vocabulary() {
+		assert.GreaterOrEqual(t, sketch.Count(word), n)
+	}
+}
+
+func TestTopEntities(t *testing.T) {
+	docs := []*Document{
+		{entities: []Entity{
+			{Text: "Acme", Label: "ORG"}, {Text: "Acme", Label: "ORG"},
+			{Text: "Paris", Label: "GPE"}}},
+		{entities: []Entity{{Text: "Paris", Label: "PERSON"}, {Text: "Bob", Label: "PERSON"}}},
+		{entities: []Entity{{Text: "Paris", Label: "GPE"}}},
+	}
+
+	assert.Equal(t, []EntityCount{
+		{Text: "Paris", Documents: 3, Count: 3},
+		{Text: "Acme", Documents: 1, Count: 2},
+	}, TopEntities(docs, 2, false))
+
+	assert.Equal(t, []EntityCount{
+		{Text: "Paris", Label: "GPE", Documents: 2, Count: 2},
+		{Text: "Acme", Label: "ORG", Documents: 1, Count: 2},
+		{Text: "Bob", Label: "PERSON", Documents: 1, Count: 1},
+		{Text: "Paris", Label: "PERSON", Documents: 1, Count: 1},
+	}, TopEntities(docs, 0, true))
+}
@@ -0,0 +1,77 @@
+package prose
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// A DocumentResult is the outcome of processing one input of
+// Model.ExtractStream.
+type DocumentResult struct {
+	Index    int       // The input's position in the stream.
+	Document *Document // The processed input; nil if Err is set.
+	Err      error     // The error encountered while processing the input.
+}
+
+// ExtractStream creates a Document, according to `opts`, for each text
+// received from `texts` using one worker per CPU.
+//
+// Results are sent as soon as they're ready, so they may arrive out of order
+// (see DocumentResult.Index). Workers block until their results are
+// received, so a slow consumer throttles the stream. The returned channel is
+// closed once `texts` is closed and drained, or once `ctx` is done.
+func (m *Model) ExtractStream(ctx context.Context, texts <-chan string, opts ...DocOpt) <-chan DocumentResult {
+	type job struct {
+		index int
+		text  string
+	}
+
+	jobs := make(chan job)
+	results := make(chan DocumentResult)
+
+	go func() {
+		defer close(jobs)
+		index := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case text, ok := <-texts:
+				if !ok {
+					return
+				}
+				select {
+				case jobs <- job{index: index, text: text}:
+					index++
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := append(opts[:len(opts):len(opts)], UsingModel(m))
+			for j := range jobs {
+				doc, err := NewDocument(j.text, worker...)
+				select {
+				case results <- DocumentResult{Index: j.index, Document: doc, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
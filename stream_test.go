@@ -0,0 +1,59 @@
+package prose
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractStream(t *testing.T) {
+	model, err := defaultModel(true, true)
+	require.NoError(t, err)
+
+	inputs := []string{}
+	for i := 0; i < 20; i++ {
+		inputs = append(inputs, fmt.Sprintf(
+			"Jack Smith moved to Seattle in %d to work for Microsoft.", 1990+i))
+	}
+
+	texts := make(chan string)
+	go func() {
+		for _, text := range inputs {
+			texts <- text
+		}
+		close(texts)
+	}()
+
+	seen := map[int]bool{}
+	for result := range model.ExtractStream(context.Background(), texts) {
+		require.NoError(t, result.Err)
+		assert.False(t, seen[result.Index])
+		seen[result.Index] = true
+
+		expected, err := NewDocument(inputs[result.Index], UsingModel(model))
+		require.NoError(t, err)
+		assert.Equal(t, inputs[result.Index], result.Document.Text)
+		assert.Equal(t, expected.Entities(), result.Document.Entities())
+		assert.Equal(t, model, result.Document.Model)
+	}
+	assert.Len(t, seen, len(inputs))
+}
+
+func TestExtractStreamCancel(t *testing.T) {
+	model, err := defaultModel(true, true)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	texts := make(chan string)
+	results := model.ExtractStream(ctx, texts, WithExtraction(false))
+
+	texts <- "The first input."
+	<-results
+	cancel()
+
+	for range results {
+	}
+}
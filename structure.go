@@ -0,0 +1,199 @@
+package prose
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// A BlockKind identifies the layout role of a Block of text.
+type BlockKind int
+
+const (
+	// BlockParagraph is running text.
+	BlockParagraph BlockKind = iota
+	// BlockHeading is a short, title-like line.
+	BlockHeading
+	// BlockListItem is a bulleted or enumerated item.
+	BlockListItem
+	// BlockTable is a run of lines with aligned, delimited columns.
+	BlockTable
+)
+
+// String returns the name of the BlockKind.
+func (k BlockKind) String() string {
+	switch k {
+	case BlockHeading:
+		return "heading"
+	case BlockListItem:
+		return "list"
+	case BlockTable:
+		return "table"
+	}
+	return "paragraph"
+}
+
+// A Block represents a structural region of a document, such as a heading or
+// a list item.
+type Block struct {
+	Kind  BlockKind // The block's layout role.
+	Start int       // The byte offset of the block's start.
+	End   int       // The byte offset of the block's end.
+	Text  string    // The block's text.
+}
+
+var reBullet = regexp.MustCompile(
+	`^\s*((?:[-*•·▪◦‣–]|\(?(?:\d{1,3}|[a-zA-Z]|[ivxlcIVXLC]{1,5})[.)]))\s+\S`)
+var reColumnGap = regexp.MustCompile(`\t+|\s{2,}|\s*\|\s*`)
+
+// DetectBlocks splits `text` into lines and classifies each non-blank line as
+// a heading, list item, table row, or paragraph text.
+//
+// Consecutive paragraph lines are merged into a single Block, as are
+// consecutive table rows and indented lines continuing a list item.
+func DetectBlocks(text string) []Block {
+	type line struct {
+		start, end int
+		kind       BlockKind
+		indented   bool
+		continues  bool // the line continues the previous list item
+	}
+
+	lines := []line{}
+	offset := 0
+	for _, raw := range strings.SplitAfter(text, "\n") {
+		start := offset
+		offset += len(raw)
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			lines = append(lines, line{start: start, end: start, kind: -1})
+			continue
+		}
+		lead := len(raw) - len(strings.TrimLeftFunc(raw, unicode.IsSpace))
+		start += lead
+		lines = append(lines, line{
+			start:    start,
+			end:      start + len(trimmed),
+			kind:     lineKind(trimmed),
+			indented: lead > 0})
+	}
+
+	// An indented line directly below a list item continues it.
+	for i := 1; i < len(lines); i++ {
+		if lines[i].kind == BlockParagraph && lines[i].indented && lines[i-1].kind == BlockListItem {
+			lines[i].kind = BlockListItem
+			lines[i].continues = true
+		}
+	}
+
+	// A lone "table row" is just a line with some extra spacing.
+	for i, l := range lines {
+		if l.kind != BlockTable {
+			continue
+		}
+		prev := i > 0 && lines[i-1].kind == BlockTable
+		next := i < len(lines)-1 && lines[i+1].kind == BlockTable
+		if !prev && !next {
+			lines[i].kind = BlockParagraph
+		}
+	}
+
+	blocks := []Block{}
+	for i, l := range lines {
+		if l.kind < 0 {
+			continue
+		}
+		merge := l.continues || l.kind == BlockParagraph || l.kind == BlockTable
+		if n := len(blocks); merge && i > 0 && n > 0 && lines[i-1].kind == l.kind {
+			blocks[n-1].End = l.end
+			blocks[n-1].Text = text[blocks[n-1].Start:l.end]
+			continue
+		}
+		blocks = append(blocks, Block{
+			Kind: l.kind, Start: l.start, End: l.end, Text: text[l.start:l.end]})
+	}
+
+	return blocks
+}
+
+// lineKind classifies a single, trimmed line of text.
+func lineKind(line string) BlockKind {
+	if reBullet.MatchString(line) {
+		return BlockListItem
+	} else if len(reColumnGap.FindAllStringIndex(line, -1)) >= 2 {
+		return BlockTable
+	} else if isHeading(line) {
+		return BlockHeading
+	}
+	return BlockParagraph
+}
+
+// isHeading determines if `line` looks like a title: short, without
+// sentence-final punctuation, and either upper- or title-cased.
+func isHeading(line string) bool {
+	words := strings.Fields(line)
+	if len(words) > 10 || strings.ContainsAny(line[len(line)-1:], ".,;?!") {
+		return false
+	}
+	upper, title := true, true
+	for _, word := range words {
+		first := []rune(word)[0]
+		if !unicode.IsLetter(first) {
+			continue
+		}
+		if strings.ToUpper(word) != word {
+			upper = false
+		}
+		if !unicode.IsUpper(first) && !stringInSlice(word, headingStopWords) {
+			title = false
+		}
+	}
+	return upper || title
+}
+
+var headingStopWords = []string{
+	"a", "an", "and", "as", "at", "by", "for", "in", "of", "on", "or", "the",
+	"to", "with"}
+
+// blockContext returns, for each of `tokens`, the kind of the non-paragraph
+// block containing it in `text` (or "" for running text).
+func blockContext(text string, tokens []*Token) []string {
+	context := make([]string, len(tokens))
+	blocks := DetectBlocks(text)
+
+	// Tokens don't know their position in `text`, so we align them with the
+	// blocks by counting non-space characters.
+	bounds := make([]int, len(blocks))
+	starts := make([]int, len(blocks))
+	seen := 0
+	cursor := 0
+	for i, block := range blocks {
+		seen += countNonSpace(text[cursor:block.Start])
+		starts[i] = seen
+		seen += countNonSpace(block.Text)
+		bounds[i] = seen
+		cursor = block.End
+	}
+
+	b, seen := 0, 0
+	for i, tok := range tokens {
+		for b < len(blocks) && seen >= bounds[b] {
+			b++
+		}
+		if b < len(blocks) && seen >= starts[b] && blocks[b].Kind != BlockParagraph {
+			context[i] = blocks[b].Kind.String()
+		}
+		seen += countNonSpace(tok.Text)
+	}
+	return context
+}
+
+func countNonSpace(s string) int {
+	n := 0
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectBlocks(t *testing.T) {
+	text := "MASTER SERVICES AGREEMENT\n\n" +
+		"This Agreement is made between Acme Corp and Globex.\n" +
+		"It continues here.\n\n" +
+		"1. Definitions apply.\n" +
+		"(b) Another clause.\n" +
+		"- A bullet\n\n" +
+		"Name    Role     Since\n" +
+		"Alice   CEO      2001\n"
+
+	kinds, texts := []BlockKind{}, []string{}
+	for _, block := range DetectBlocks(text) {
+		kinds = append(kinds, block.Kind)
+		texts = append(texts, block.Text)
+		assert.Equal(t, block.Text, text[block.Start:block.End])
+	}
+	assert.Equal(t, []BlockKind{
+		BlockHeading, BlockParagraph, BlockListItem, BlockListItem,
+		BlockListItem, BlockTable}, kinds)
+	assert.Equal(t,
+		"This Agreement is made between Acme Corp and Globex.\nIt continues here.",
+		texts[1])
+}
+
+func TestBlockContext(t *testing.T) {
+	text := "Governing Law\n\nThis is governed by Delaware law.\n- Acme Corp"
+	tokens := NewIterTokenizer().Tokenize(text)
+	context := blockContext(text, tokens)
+	require.Len(t, context, len(tokens))
+	assert.Equal(t, []string{
+		"heading", "heading", "", "", "", "", "", "", "", "list", "list", "list"},
+		context)
+}
+
+func TestNERBlockContext(t *testing.T) {
+	data := []EntityContext{}
+	for _, name := range []string{"Acme", "Globex", "Initech", "Umbrella"} {
+		text := "PARTIES\n\n- " + name + " Corporation\n\nThe parties agree."
+		data = append(data, EntityContext{
+			Accept: true,
+			Text:   text,
+			Spans:  []LabeledEntity{{Start: 11, End: 11 + len(name), Label: "PARTY"}}})
+	}
+
+	model, err := ModelFromData("PARTY",
+		UsingEntitiesWithOptions(data, TrainingOptions{BlockContext: true}))
+	require.NoError(t, err)
+	_, found := model.extracter.model.mapping["block-list-B-PARTY"]
+	assert.True(t, found)
+
+	doc, err := NewDocument("PARTIES\n\n- Hooli Corporation\n\nThe parties agree.",
+		UsingModel(model), WithBlockContext(true))
+	require.NoError(t, err)
+	require.Len(t, doc.Entities(), 1)
+	assert.Equal(t, "PARTY", doc.Entities()[0].Label)
+}
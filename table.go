@@ -0,0 +1,61 @@
+package prose
+
+// A TokenTable is a column-oriented view of the tokens in one or more
+// Documents.
+//
+// Each column has one entry per token, so the table maps directly onto a
+// record batch in columnar formats such as Apache Arrow or Parquet.
+type TokenTable struct {
+	Document []int    // The index of the token's Document.
+	Sentence []int    // The index of the token's sentence; -1 if unknown.
+	Text     []string // The token's actual content.
+	Start    []int    // The byte offset of the token's start in its Document.
+	End      []int    // The byte offset just past the token's end.
+	Tag      []string // The token's part-of-speech tag.
+	Label    []string // The token's IOB label.
+}
+
+// NewTokenTable builds a TokenTable from the tokens of `docs`.
+func NewTokenTable(docs ...*Document) *TokenTable {
+	table := &TokenTable{}
+	for i, doc := range docs {
+		table.append(i, doc)
+	}
+	return table
+}
+
+// Len returns the number of rows in the table.
+func (t *TokenTable) Len() int {
+	return len(t.Text)
+}
+
+func (t *TokenTable) append(index int, doc *Document) {
+	sents := sentenceIndex(doc)
+	for i, tok := range doc.tokens {
+		t.Document = append(t.Document, index)
+		t.Sentence = append(t.Sentence, sents[i])
+		t.Text = append(t.Text, tok.Text)
+		t.Start = append(t.Start, tok.Start)
+		t.End = append(t.End, tok.End)
+		t.Tag = append(t.Tag, tok.Tag)
+		t.Label = append(t.Label, tok.Label)
+	}
+}
+
+// sentenceIndex returns the index of the sentence of each of `doc`'s tokens
+// (see TokensInSentence), or -1 for all of them if `doc` wasn't segmented.
+func sentenceIndex(doc *Document) []int {
+	index := make([]int, len(doc.tokens))
+	if len(doc.sentences) == 0 {
+		for i := range index {
+			index[i] = -1
+		}
+		return index
+	}
+	for sent := 0; sent+1 < len(doc.sentTokens); sent++ {
+		for i := doc.sentTokens[sent]; i < doc.sentTokens[sent+1]; i++ {
+			index[i] = sent
+		}
+	}
+	return index
+}
@@ -0,0 +1,40 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenTable(t *testing.T) {
+	first, err := NewDocument("Go is fun. I like it.")
+	require.NoError(t, err)
+	second, err := NewDocument("Hello world.")
+	require.NoError(t, err)
+
+	table := NewTokenTable(first, second)
+	assert.Equal(t, len(first.Tokens())+len(second.Tokens()), table.Len())
+	assert.Equal(t, []int{0, 0, 0, 0, 1, 1, 1, 1, 0, 0, 0}, table.Sentence)
+	assert.Equal(t, []int{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1}, table.Document)
+	assert.Equal(t, "fun", table.Text[2])
+	assert.Equal(t, []int{6, 9}, []int{table.Start[2], table.End[2]})
+	assert.Equal(t, []int{0, 5}, []int{table.Start[8], table.End[8]})
+	assert.Equal(t, "VBZ", table.Tag[1])
+	assert.Equal(t, "O", table.Label[1])
+}
+
+func TestTokenTableSanitizedText(t *testing.T) {
+	// The sanitizer rewrites "&rsquo;", so the text and tokens differ.
+	doc, err := NewDocument("I don&rsquo;t know. He is. Ok.", WithExtraction(false))
+	require.NoError(t, err)
+	table := NewTokenTable(doc)
+	assert.Equal(t, []int{0, 0, 0, 0, 0, 1, 1, 1, 2}, table.Sentence)
+	for i := range table.Text {
+		assert.True(t, table.Start[i] < table.End[i], table.Text[i])
+	}
+
+	unsegmented, err := NewDocument("Go is fun. I like it.", WithSegmentation(false))
+	require.NoError(t, err)
+	assert.Equal(t, []int{-1, -1, -1, -1, -1, -1, -1, -1}, NewTokenTable(unsegmented).Sentence)
+}
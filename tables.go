@@ -0,0 +1,117 @@
+package prose
+
+import (
+	"sort"
+	"strings"
+)
+
+// A TablePolicy determines how NewDocument handles table-like regions of
+// text (see DetectBlocks).
+type TablePolicy int
+
+const (
+	// TablesIgnore treats tables as running text (the default).
+	TablesIgnore TablePolicy = iota
+	// TablesDetect processes tables as running text, but exposes them via
+	// Document.Tables.
+	TablesDetect
+	// TablesSkip excludes tables from segmentation, tokenization, tagging,
+	// and entity extraction.
+	TablesSkip
+	// TablesRows makes each table row a sentence of its own instead of
+	// running the sentence segmenter over it.
+	TablesRows
+)
+
+// A Table represents a table-like region of a Document.
+type Table struct {
+	Start int        // The byte offset of the table's start.
+	End   int        // The byte offset of the table's end.
+	Text  string     // The table's text.
+	Rows  [][]string // The table's cells, row by row.
+}
+
+// WithTables determines how table-like regions are handled.
+func WithTables(policy TablePolicy) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Tables = policy
+	}
+}
+
+// Tables returns `doc`'s tables; it's nil unless tables were requested with
+// WithTables.
+func (doc *Document) Tables() []Table {
+	return doc.tables
+}
+
+// detectTables finds the table-like regions of `text`.
+func detectTables(text string) []Table {
+	tables := []Table{}
+	for _, block := range DetectBlocks(text) {
+		if block.Kind != BlockTable {
+			continue
+		}
+		table := Table{Start: block.Start, End: block.End, Text: block.Text}
+		for _, line := range strings.Split(block.Text, "\n") {
+			row := []string{}
+			for _, cell := range reColumnGap.Split(strings.TrimSpace(line), -1) {
+				if cell != "" {
+					row = append(row, cell)
+				}
+			}
+			if len(row) > 0 {
+				table.Rows = append(table.Rows, row)
+			}
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// blankTables replaces the (non-newline) contents of `tables` with spaces,
+// preserving all offsets into `text`.
+func blankTables(text string, tables []Table) string {
+	spans := make([][2]int, len(tables))
+	for i, table := range tables {
+		spans[i] = [2]int{table.Start, table.End}
+	}
+	return blankSpans(text, spans)
+}
+
+// tableRowSentences merges the rows of `tables` into `sents`, in document
+// order.
+func tableRowSentences(sents []Sentence, tables []Table) []Sentence {
+	type positioned struct {
+		start int
+		sent  Sentence
+	}
+
+	merged := []positioned{}
+	cursor := 0
+	for _, sent := range sents {
+		if sent.Start >= 0 {
+			cursor = sent.Start
+		}
+		merged = append(merged, positioned{start: cursor, sent: sent})
+	}
+	for _, table := range tables {
+		start := table.Start
+		for _, line := range strings.SplitAfter(table.Text, "\n") {
+			if row := strings.TrimSpace(line); row != "" {
+				rowStart := start + strings.Index(line, row)
+				merged = append(merged, positioned{start: rowStart,
+					sent: Sentence{Text: row, Start: rowStart, End: rowStart + len(row)}})
+			}
+			start += len(line)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].start < merged[j].start
+	})
+	result := make([]Sentence, len(merged))
+	for i, p := range merged {
+		result[i] = p.sent
+	}
+	return result
+}
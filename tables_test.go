@@ -0,0 +1,63 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tableText = "Payment is due monthly.\n\n" +
+	"Item      Qty   Price\n" +
+	"Widget    2     $5\n" +
+	"Gadget    10    $12\n\n" +
+	"Send payment to Acme Corp. Thank you."
+
+func sentenceTexts(doc *Document) []string {
+	texts := []string{}
+	for _, sent := range doc.Sentences() {
+		texts = append(texts, sent.Text)
+	}
+	return texts
+}
+
+func TestTablesDetect(t *testing.T) {
+	doc, err := NewDocument(tableText, WithTables(TablesDetect))
+	require.NoError(t, err)
+
+	tables := doc.Tables()
+	require.Len(t, tables, 1)
+	assert.Equal(t, tables[0].Text, tableText[tables[0].Start:tables[0].End])
+	assert.Equal(t, [][]string{
+		{"Item", "Qty", "Price"},
+		{"Widget", "2", "$5"},
+		{"Gadget", "10", "$12"}}, tables[0].Rows)
+
+	doc, err = NewDocument(tableText)
+	require.NoError(t, err)
+	assert.Nil(t, doc.Tables())
+}
+
+func TestTablesSkip(t *testing.T) {
+	doc, err := NewDocument(tableText, WithTables(TablesSkip))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Payment is due monthly.",
+		"Send payment to Acme Corp.",
+		"Thank you."}, sentenceTexts(doc))
+	for _, tok := range doc.Tokens() {
+		assert.NotEqual(t, "Widget", tok.Text)
+	}
+}
+
+func TestTablesRows(t *testing.T) {
+	doc, err := NewDocument(tableText, WithTables(TablesRows))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Payment is due monthly.",
+		"Item      Qty   Price",
+		"Widget    2     $5",
+		"Gadget    10    $12",
+		"Send payment to Acme Corp.",
+		"Thank you."}, sentenceTexts(doc))
+}
@@ -21,10 +21,14 @@
 package prose
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"math"
-	"path"
+	"math/rand"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -38,26 +42,95 @@ func (t TupleSlice) Len() int { return len(t) }
 // Swap switches the ith and jth elements in a Tuple.
 func (t TupleSlice) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
 
-// ReadTagged converts pre-tagged input into a TupleSlice suitable for training.
-func ReadTagged(text, sep string) TupleSlice {
-	lines := strings.Split(text, "\n")
-	length := len(lines)
-	t := make(TupleSlice, length)
-	for i, sent := range lines {
-		set := strings.Split(sent, " ")
-		length = len(set)
-		tokens := make([]string, length)
-		tags := make([]string, length)
-		for j, token := range set {
-			parts := strings.Split(token, sep)
-			tokens[j] = parts[0]
-			tags[j] = parts[1]
+// ReadTagged converts pre-tagged input into a TupleSlice suitable for
+// training. Each line holds a sentence of space-separated token-tag pairs,
+// such as "Pierre|NNP Vinken|NNP" with `sep` "|"; the last `sep` in a pair
+// separates its tag, so tokens may contain it (e.g., "||SYM"). Blank lines
+// are skipped, and malformed pairs are reported by line and column.
+func ReadTagged(text, sep string) (TupleSlice, error) {
+	if sep == "" {
+		return nil, errors.New("empty separator")
+	}
+	t := TupleSlice{}
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		tokens, tags := []string{}, []string{}
+		column := 1
+		for _, pair := range strings.Split(line, " ") {
+			if pair != "" {
+				idx := strings.LastIndex(pair, sep)
+				if idx <= 0 || idx+len(sep) == len(pair) {
+					return nil, fmt.Errorf("line %d, column %d: %q isn't a token and tag separated by %q",
+						i+1, column, pair, sep)
+				}
+				tokens = append(tokens, pair[:idx])
+				tags = append(tags, pair[idx+len(sep):])
+			}
+			column += len(pair) + 1
+		}
+		t = append(t, [][]string{tokens, tags})
+	}
+	return t, nil
+}
+
+// ReadTaggedColumns converts pre-tagged input in a column format, such as
+// CoNLL-U, into a TupleSlice suitable for training. Each line holds a token
+// with tab-separated columns, of which `tokenCol` and `tagCol` (counting
+// from 0) hold the token and its tag, e.g., 1 and 4 for the form and the
+// Penn Treebank tag in CoNLL-U; blank lines separate sentences. Comments
+// (lines starting with "#"), multi-word token ranges (e.g., "3-4"), and
+// empty nodes (e.g., "8.1") are skipped.
+func ReadTaggedColumns(r io.Reader, tokenCol, tagCol int) (TupleSlice, error) {
+	if tokenCol < 0 || tagCol < 0 {
+		return nil, errors.New("negative column")
+	}
+	columns := tokenCol // The last column needed.
+	if tagCol > columns {
+		columns = tagCol
+	}
+	t := TupleSlice{}
+	tokens, tags := []string{}, []string{}
+	flush := func() {
+		if len(tokens) > 0 {
+			t = append(t, [][]string{tokens, tags})
+			tokens, tags = []string{}, []string{}
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			flush()
+			continue
+		} else if strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if multiword.MatchString(fields[0]) {
+			continue
+		} else if len(fields) <= columns {
+			return nil, fmt.Errorf("line %d: expected at least %d columns, found %d",
+				line, columns+1, len(fields))
+		} else if fields[tokenCol] == "" || fields[tagCol] == "" {
+			return nil, fmt.Errorf("line %d: empty token or tag", line)
 		}
-		t[i] = [][]string{tokens, tags}
+		tokens = append(tokens, fields[tokenCol])
+		tags = append(tags, fields[tagCol])
 	}
-	return t
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read tagged columns: %w", err)
+	}
+	flush()
+	return t, nil
 }
 
+// multiword matches the IDs of CoNLL-U's multi-word tokens and empty nodes.
+var multiword = regexp.MustCompile(`^\d+[-.]\d+$`)
+
 var none = regexp.MustCompile(`^(?:0|\*[\w?]\*|\*\-\d{1,3}|\*[A-Z]+\*\-\d{1,3}|\*)$`)
 var keep = regexp.MustCompile(`^\-[A-Z]{3}\-$`)
 
@@ -65,15 +138,23 @@ var keep = regexp.MustCompile(`^\-[A-Z]{3}\-$`)
 type averagedPerceptron struct {
 	classes  []string
 	classMap map[string]int
-	stamps   map[string]float64
-	totals   map[string]float64
 	tagMap   map[string]string
-	//	weights       map[string]map[string]float64
+
 	linearWeights map[string][]float64
 
-	// TODO: Training
-	//
-	// instances float64
+	// Training state: for averaging, the running total of each weight and
+	// the instance at which it was last updated.
+	stamps    map[string][]float64
+	totals    map[string][]float64
+	instances float64
+
+	// embedded is true for the built-in model, which Models don't save
+	// (they load it instead).
+	embedded bool
+
+	// digest summarizes the contents of the model, once computed (see
+	// Model.fingerprint).
+	digest string
 }
 
 // newAveragedPerceptron creates a new AveragedPerceptron model.
@@ -83,89 +164,97 @@ func newAveragedPerceptron(tags map[string]string, classes []string, linearWeigh
 		cm[classes[i]] = i
 	}
 	return &averagedPerceptron{
-		totals: make(map[string]float64), stamps: make(map[string]float64),
+		totals: make(map[string][]float64), stamps: make(map[string][]float64),
 		classes: classes, tagMap: tags, classMap: cm, linearWeights: linearWeights}
 }
 
-/* TODO: Training API
-
-"github.com/shogo82148/go-shuffle"
-
-// marshal saves the model to disk.
-func (m *averagedPerceptron) marshal(path string) error {
-	folder := filepath.Join(path, "AveragedPerceptron")
-	err := os.Mkdir(folder, os.ModePerm)
-	for i, entry := range []string{"weights", "tags", "classes"} {
-		component, _ := os.Create(filepath.Join(folder, entry+".gob"))
-		encoder := gob.NewEncoder(component)
-		if i == 0 {
-			checkError(encoder.Encode(m.weights))
-		} else if i == 1 {
-			checkError(encoder.Encode(m.tagMap))
-		} else {
-			checkError(encoder.Encode(m.classes))
+// check determines if the perceptron has a weight for each of its classes
+// for every feature, so that a corrupt model is rejected when it's loaded
+// rather than when it's used.
+func (m *averagedPerceptron) check() error {
+	if len(m.classes) == 0 {
+		return withCode(ErrCorruptModel, errors.New("no classes"))
+	}
+	for feature, weights := range m.linearWeights {
+		if len(weights) != len(m.classes) {
+			return withCode(ErrCorruptModel, fmt.Errorf("feature %q has %d weights for %d classes",
+				feature, len(weights), len(m.classes)))
 		}
 	}
-	return err
+	return nil
 }
 
-// train an Averaged Perceptron model based on sentences.
-func (pt *perceptronTagger) train(sentences TupleSlice, iterations int) {
-	var guess string
-	var found bool
+// Train replaces the model of `pt` with one trained on `sentences` (for
+// example, the output of ReadTagged) over `iterations` passes. The
+// sentences are shuffled between passes using the seed set by SetSeed, so
+// training is reproducible.
+func (pt *PerceptronTagger) Train(sentences TupleSlice, iterations int) error {
+	if iterations < 1 {
+		return fmt.Errorf("invalid number of iterations %d", iterations)
+	}
+	for i, tuple := range sentences {
+		if len(tuple) != 2 || len(tuple[0]) != len(tuple[1]) {
+			return fmt.Errorf("sentence %d doesn't have one tag per word", i)
+		}
+	}
+
+	model := trainingPerceptron(sentences)
+	trainer := &PerceptronTagger{model: model}
 
-	pt.makeTagMap(sentences)
+	order := make(TupleSlice, len(sentences))
+	copy(order, sentences)
+	rng := rand.New(rand.NewSource(pt.seed))
 	for i := 0; i < iterations; i++ {
-		for _, tuple := range sentences {
-			words, tags := tuple[0], tuple[1]
-			p1, p2 := "-START-", "-START2-"
-			context := []string{p1, p2}
-			for _, w := range words {
-				if w == "" {
-					continue
-				}
-				context = append(context, normalize(w))
-			}
-			context = append(context, []string{"-END-", "-END2-"}...)
-			for i, word := range words {
-				if guess, found = pt.tagMap[word]; !found {
-					feats := featurize(i, context, word, p1, p2)
-					guess = pt.model.predict(feats)
-					pt.model.update(tags[i], guess, feats)
-				}
-				p2 = p1
-				p1 = guess
-			}
+		for _, tuple := range order {
+			trainer.trainSentence(tuple[0], tuple[1])
 		}
-		shuffle.Shuffle(sentences)
+		rng.Shuffle(len(order), order.Swap)
 	}
-	pt.model.averageWeights()
+	model.averageWeights()
+
+	pt.model = model
+	return nil
 }
 
-func (m *averagedPerceptron) averageWeights() {
-	for feat, weights := range m.weights {
-		newWeights := make(map[string]float64)
-		for class, weight := range weights {
-			key := feat + "-" + class
-			total := m.totals[key]
-			total += (m.instances - m.stamps[key]) * weight
-			averaged, _ := stats.Round(total/m.instances, 3)
-			if averaged != 0.0 {
-				newWeights[class] = averaged
-			}
-		}
-		m.weights[feat] = newWeights
-	}
+// SetSeed sets the seed used to shuffle sentences during training; the
+// default is zero.
+func (pt *PerceptronTagger) SetSeed(seed int64) {
+	pt.seed = seed
+}
+
+// Classes returns the tags `pt` can assign.
+func (pt *PerceptronTagger) Classes() []string {
+	return append([]string{}, pt.model.classes...)
 }
 
-// newTrainedPerceptronTagger creates a new PerceptronTagger using the given
-// model.
-func newTrainedPerceptronTagger(model *averagedPerceptron) *perceptronTagger {
-	return &perceptronTagger{model: model}
+// trainSentence tags `words` and updates the model wherever the guess
+// differs from `tags`.
+func (pt *PerceptronTagger) trainSentence(words, tags []string) {
+	p1, p2 := "-START-", "-START2-"
+	context := make([]string, 0, len(words)+4)
+	context = append(context, p1, p2)
+	for _, w := range words {
+		context = append(context, normalize(w))
+	}
+	context = append(context, "-END-", "-END2-")
+
+	for i, word := range words {
+		guess, found := pt.knownTag(word)
+		if !found && word != "" {
+			feats := featurize(i, context, word, p1, p2)
+			guess = pt.model.predict(feats)
+			pt.model.update(tags[i], guess, feats)
+		}
+		p2 = p1
+		p1 = guess
+	}
 }
 
-func (pt *perceptronTagger) makeTagMap(sentences TupleSlice) {
+// trainingPerceptron creates an empty averagedPerceptron for `sentences`:
+// it knows their tags, and the words whose tag is (nearly) always the same.
+func trainingPerceptron(sentences TupleSlice) *averagedPerceptron {
 	counts := make(map[string]map[string]int)
+	classes := []string{}
 	for _, tuple := range sentences {
 		words, tags := tuple[0], tuple[1]
 		for i, word := range words {
@@ -174,79 +263,74 @@ func (pt *perceptronTagger) makeTagMap(sentences TupleSlice) {
 				counts[word] = make(map[string]int)
 			}
 			counts[word][tag]++
-			pt.model.addClass(tag)
+			if !stringInSlice(tag, classes) {
+				classes = append(classes, tag)
+			}
 		}
 	}
+	sort.Strings(classes)
+
+	tagMap := make(map[string]string)
 	for word, tagFreqs := range counts {
-		tag, mode := maxValue(tagFreqs)
-		n := float64(sumValues(tagFreqs))
-		if n >= 20 && (float64(mode)/n) >= 0.97 {
-			pt.tagMap[word] = tag
+		tag, mode, n := "", 0, 0
+		for t, count := range tagFreqs {
+			n += count
+			if count > mode || (count == mode && t < tag) {
+				tag, mode = t, count
+			}
 		}
-	}
-}
-
-func sumValues(m map[string]int) int {
-	sum := 0
-	for _, v := range m {
-		sum += v
-	}
-	return sum
-}
-
-func maxValue(m map[string]int) (string, int) {
-	maxValue := 0
-	key := ""
-	for k, v := range m {
-		if v >= maxValue {
-			maxValue = v
-			key = k
+		if n >= 20 && float64(mode)/float64(n) >= 0.97 {
+			tagMap[word] = tag
 		}
 	}
-	return key, maxValue
-}
 
-func get(k string, m map[string]float64) float64 {
-	if v, ok := m[k]; ok {
-		return v
-	}
-	return 0.0
+	return newAveragedPerceptron(tagMap, classes, make(map[string][]float64))
 }
 
-func (m *averagedPerceptron) update(truth, guess string, feats map[string]float64) {
+// update rewards the features of a wrong guess for the true class and
+// penalizes them for the guessed one.
+func (m *averagedPerceptron) update(truth, guess string, feats [14]string) {
 	m.instances++
 	if truth == guess {
 		return
 	}
-	for f := range feats {
-		weights := make(map[string]float64)
-		if val, ok := m.weights[f]; ok {
-			weights = val
-		} else {
-			m.weights[f] = weights
+	t, g := m.classMap[truth], m.classMap[guess]
+	for _, f := range feats {
+		if _, found := m.linearWeights[f]; !found {
+			m.linearWeights[f] = make([]float64, len(m.classes))
+			m.totals[f] = make([]float64, len(m.classes))
+			m.stamps[f] = make([]float64, len(m.classes))
 		}
-		m.updateFeat(truth, f, get(truth, weights), 1.0)
-		m.updateFeat(guess, f, get(guess, weights), -1.0)
+		m.updateFeat(f, t, 1.0)
+		m.updateFeat(f, g, -1.0)
 	}
 }
 
-func (m *averagedPerceptron) updateFeat(c, f string, v, w float64) {
-	key := f + "-" + c
-	m.totals[key] = (m.instances - m.stamps[key]) * w
-	m.stamps[key] = m.instances
-	m.weights[f][c] = w + v
+func (m *averagedPerceptron) updateFeat(f string, c int, w float64) {
+	weights := m.linearWeights[f]
+	m.totals[f][c] += (m.instances - m.stamps[f][c]) * weights[c]
+	m.stamps[f][c] = m.instances
+	weights[c] += w
 }
 
-func (m *averagedPerceptron) addClass(class string) {
-	if !stringInSlice(class, m.classes) {
-		m.classes = append(m.classes, class)
+// averageWeights replaces each weight with its average over all training
+// instances, which makes the model less sensitive to the last few updates.
+func (m *averagedPerceptron) averageWeights() {
+	for f, weights := range m.linearWeights {
+		for c, weight := range weights {
+			total := m.totals[f][c] + (m.instances-m.stamps[f][c])*weight
+			weights[c] = total / m.instances
+		}
 	}
-}*/
+	m.totals = make(map[string][]float64)
+	m.stamps = make(map[string][]float64)
+}
 
 // perceptronTagger is a port of Textblob's "fast and accurate" POS tagger.
 // See https://github.com/sloria/textblob-aptagger for details.
 type PerceptronTagger struct {
 	model *averagedPerceptron
+	seed  int64
 }
 
 // newPerceptronTagger creates a new PerceptronTagger and loads the built-in
@@ -256,38 +340,32 @@ func NewPerceptronTagger() (*PerceptronTagger, error) {
 	var classes []string
 	var lwts map[string][]float64
 
-	dec, err := ReadAndDecodeBytes(path.Join("AveragedPerceptron", "classes.gob"))
-	if err != nil {
-		return nil, fmt.Errorf("unable to read classes: %w", err)
-	}
-	err = dec.Decode(&classes)
-	if err != nil {
-		return nil, fmt.Errorf("unable to decode classes: %w", err)
+	if err := decodeAsset("AveragedPerceptron/classes.gob", &classes); err != nil {
+		return nil, fmt.Errorf("unable to load classes: %w", err)
 	}
-
-	dec, err = ReadAndDecodeBytes(path.Join("AveragedPerceptron", "tags.gob"))
-	if err != nil {
-		return nil, fmt.Errorf("unable to read tags: %w", err)
+	if err := decodeAsset("AveragedPerceptron/tags.gob", &tags); err != nil {
+		return nil, fmt.Errorf("unable to load tags: %w", err)
 	}
-	err = dec.Decode(&tags)
-	if err != nil {
-		return nil, fmt.Errorf("unable to decode tags: %w", err)
+	if err := decodeAsset("AveragedPerceptron/weights-linear.gob", &lwts); err != nil {
+		return nil, fmt.Errorf("unable to load linear weights: %w", err)
 	}
 
-	dec, err = ReadAndDecodeBytes(path.Join("AveragedPerceptron", "weights-linear.gob"))
-	if err != nil {
-		return nil, fmt.Errorf("unable to read linear weights: %w", err)
-	}
-	err = dec.Decode(&lwts)
-	if err != nil {
-		return nil, fmt.Errorf("unable to decode lienar weights: %w", err)
+	model := newAveragedPerceptron(tags, classes, lwts)
+	if err := model.check(); err != nil {
+		return nil, fmt.Errorf("invalid embedded tagger: %w", err)
 	}
-
-	return &PerceptronTagger{model: newAveragedPerceptron(tags, classes, lwts)}, nil
+	model.embedded = true
+	return &PerceptronTagger{model: model}, nil
 }
 
 // Tag takes a slice of words and returns a slice of tagged tokens.
 func (pt *PerceptronTagger) Tag(tokens []*Token) []*Token {
+	return pt.tag(tokens, false)
+}
+
+// tag assigns each of `tokens` a tag; if `keep` is true, tokens that already
+// have one keep it, and it informs the tags of the tokens that follow.
+func (pt *PerceptronTagger) tag(tokens []*Token, keep bool) []*Token {
 	var tag string
 	var found bool
 
@@ -303,18 +381,9 @@ func (pt *PerceptronTagger) Tag(tokens []*Token) []*Token {
 	context[length-1] = "-END2-"
 	for i := 0; i < len(tokens); i++ {
 		word := tokens[i].Text
-		if word == "-" {
-			tag = "-"
-		} else if _, ok := emoticons[word]; ok {
-			tag = "SYM"
-		} else if strings.HasPrefix(word, "@") {
-			// TODO: URLs and emails?
-			tag = "NN"
-		} else if none.MatchString(word) {
-			tag = "-NONE-"
-		} else if keep.MatchString(word) {
-			tag = word
-		} else if tag, found = pt.model.tagMap[word]; !found {
+		if keep && tokens[i].Tag != "" {
+			tag = tokens[i].Tag
+		} else if tag, found = pt.knownTag(word); !found {
 			tag = pt.model.predict(featurize(i, context, word, p1, p2))
 		}
 		tokens[i].Tag = tag
@@ -325,22 +394,114 @@ func (pt *PerceptronTagger) Tag(tokens []*Token) []*Token {
 	return tokens
 }
 
+// knownTag returns the tag of `word` if it doesn't depend on context: it's
+// either fixed by rule or unambiguous in the training data.
+func (pt *PerceptronTagger) knownTag(word string) (string, bool) {
+	if word == "-" || isDashToken(word) {
+		return "-", true
+	} else if _, ok := emoticons[word]; ok {
+		return "SYM", true
+	} else if strings.HasPrefix(word, "@") || isWebToken(word) {
+		return "NN", true
+	} else if none.MatchString(word) {
+		return "-NONE-", true
+	} else if keep.MatchString(word) {
+		return word, true
+	}
+	tag, found := pt.model.tagMap[word]
+	return tag, found
+}
+
+// A TagSequence is one candidate tagging of a sequence of tokens.
+type TagSequence struct {
+	Tags  []string // The tag of each token.
+	Score float64  // The sum of the perceptron's scores for Tags.
+}
+
+// TagNBest returns up to `n` of the highest-scoring tag sequences for
+// `tokens`, best first, using a beam search of width `n`.
+//
+// Unlike Tag, TagNBest doesn't modify `tokens`. The best sequence of
+// TagNBest(tokens, 1) is the one assigned by Tag.
+func (pt *PerceptronTagger) TagNBest(tokens []*Token, n int) []TagSequence {
+	type state struct {
+		tags   []string
+		p1, p2 string
+		score  float64
+	}
+
+	if n < 1 {
+		return []TagSequence{}
+	}
+
+	length := len(tokens) + 4
+	context := make([]string, length)
+	context[0] = "-START-"
+	context[1] = "-START2-"
+	for i, t := range tokens {
+		context[i+2] = normalize(t.Text)
+	}
+	context[length-2] = "-END-"
+	context[length-1] = "-END2-"
+
+	beam := []state{{p1: "-START-", p2: "-START2-"}}
+	for i, tok := range tokens {
+		next := []state{}
+		fixed, found := pt.knownTag(tok.Text)
+		for _, st := range beam {
+			if found {
+				next = append(next, state{
+					append(st.tags[:len(st.tags):len(st.tags)], fixed),
+					fixed, st.p1, st.score})
+				continue
+			}
+			scores := pt.model.scores(featurize(i, context, tok.Text, st.p1, st.p2))
+			for c, score := range scores {
+				tag := pt.model.classes[c]
+				next = append(next, state{
+					append(st.tags[:len(st.tags):len(st.tags)], tag),
+					tag, st.p1, st.score + score})
+			}
+		}
+		sort.SliceStable(next, func(a, b int) bool {
+			return next[a].score > next[b].score
+		})
+		if len(next) > n {
+			next = next[:n]
+		}
+		beam = next
+	}
+
+	sequences := make([]TagSequence, len(beam))
+	for i, st := range beam {
+		tags := st.tags
+		if tags == nil {
+			tags = []string{}
+		}
+		sequences[i] = TagSequence{Tags: tags, Score: st.score}
+	}
+	return sequences
+}
+
 func (m *averagedPerceptron) predict(features [14]string) string {
+	return m.classes[max(m.scores(features))]
+}
+
+// scores returns the score of each of the model's classes given `features`.
+func (m *averagedPerceptron) scores(features [14]string) []float64 {
 	var weights []float64
 	var found bool
 
 	scores := make([]float64, len(m.classes))
 	for _, feat := range features {
-		// if we re-add training then also check feat.cnt == 0 {
 		if weights, found = m.linearWeights[feat]; !found {
 			continue
 		}
 		for label, weight := range weights {
-			// If we add training then scores[label] += feat.cnt * weight
 			scores[label] += weight
 		}
 	}
-	return m.classes[max(scores)]
+	return scores
 }
 
 func max(scores []float64) int {
@@ -355,12 +516,6 @@ func max(scores []float64) int {
 	return class
 }
 
-// We may need this struct if we want to add back training code
-// type freq struct {
-// 	feat string
-// 	cnt  float64
-// }
-
 func featurize(i int, ctx []string, w, p1, p2 string) [14]string {
 	feats := [14]string{}
 	suf := min(len(w), 3)
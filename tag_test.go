@@ -3,8 +3,10 @@ package prose
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,7 +22,11 @@ func makeTagger(text string) (*Document, error) {
 
 func ExampleReadTagged() {
 	tagged := "Pierre|NNP Vinken|NNP ,|, 61|CD years|NNS"
-	fmt.Println(ReadTagged(tagged, "|"))
+	sentences, err := ReadTagged(tagged, "|")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(sentences)
 	// Output: [[[Pierre Vinken , 61 years] [NNP NNP , CD NNS]]]
 }
 
@@ -40,6 +46,33 @@ func TestTagSimple(t *testing.T) {
 	}
 }
 
+func TestTagNBest(t *testing.T) {
+	tagger, err := NewPerceptronTagger()
+	require.NoError(t, err)
+
+	tokens := NewIterTokenizer().Tokenize("They refuse to permit us to obtain the refuse permit.")
+	best := tagger.TagNBest(tokens, 5)
+	require.Len(t, best, 5)
+
+	for _, tok := range tokens {
+		assert.Equal(t, "", tok.Tag)
+	}
+	for i, seq := range best {
+		assert.Len(t, seq.Tags, len(tokens))
+		if i > 0 {
+			assert.LessOrEqual(t, seq.Score, best[i-1].Score)
+			assert.NotEqual(t, best[i-1].Tags, seq.Tags)
+		}
+	}
+
+	greedy := []string{}
+	for _, tok := range tagger.Tag(tokens) {
+		greedy = append(greedy, tok.Tag)
+	}
+	assert.Equal(t, greedy, tagger.TagNBest(tokens, 1)[0].Tags)
+	assert.Empty(t, tagger.TagNBest(tokens, 0))
+}
+
 func TestTagTreebank(t *testing.T) {
 	tagger, err := NewPerceptronTagger()
 	assert.NoError(t, err)
@@ -79,8 +112,6 @@ func BenchmarkTag(b *testing.B) {
 	}
 }
 
-/* TODO: POS training API
-
 var wsj = "Pierre|NNP Vinken|NNP ,|, 61|CD years|NNS old|JJ ,|, will|MD " +
 	"join|VB the|DT board|NN as|IN a|DT nonexecutive|JJ director|NN " +
 	"Nov.|NNP 29|CD .|.\nMr.|NNP Vinken|NNP is|VBZ chairman|NN of|IN " +
@@ -95,27 +126,124 @@ var wsj = "Pierre|NNP Vinken|NNP ,|, 61|CD years|NNS old|JJ ,|, will|MD " +
 	"of|IN workers|NNS exposed|VBN to|TO it|PRP more|RBR than|IN " +
 	"30|CD years|NNS ago|IN ,|, researchers|NNS reported|VBD .|."
 
+// readWSJ returns the sentences of `wsj`.
+func readWSJ(t *testing.T) TupleSlice {
+	sentences, err := ReadTagged(wsj, "|")
+	require.NoError(t, err)
+	return sentences
+}
+
+func TestReadTagged(t *testing.T) {
+	sentences, err := ReadTagged("Pierre|NNP Vinken|NNP ,|,\r\n\r\n61|CD  years|NNS\r\n", "|")
+	require.NoError(t, err)
+	assert.Equal(t, TupleSlice{
+		{{"Pierre", "Vinken", ","}, {"NNP", "NNP", ","}},
+		{{"61", "years"}, {"CD", "NNS"}}}, sentences)
+
+	// The last separator delimits the tag.
+	sentences, err = ReadTagged("a||SYM b|/c|NN", "|")
+	require.NoError(t, err)
+	assert.Equal(t, TupleSlice{{{"a|", "b|/c"}, {"SYM", "NN"}}}, sentences)
+	sentences, err = ReadTagged("x|NN ||SYM", "|")
+	require.NoError(t, err)
+	assert.Equal(t, TupleSlice{{{"x", "|"}, {"NN", "SYM"}}}, sentences)
+
+	for input, column := range map[string]int{
+		"Pierre|NNP Vinken":      12,
+		"Pierre|NNP |NNP":        12,
+		"Pierre|NNP Vinken| ,|,": 12,
+	} {
+		_, err = ReadTagged("ok|JJ\n"+input, "|")
+		require.Error(t, err, input)
+		assert.Contains(t, err.Error(), fmt.Sprintf("line 2, column %d", column), input)
+	}
+	_, err = ReadTagged("a|DT", "")
+	assert.Error(t, err)
+}
+
+func TestReadTaggedColumns(t *testing.T) {
+	f, err := os.Open(filepath.Join(testdata, "ud_sample.conllu"))
+	require.NoError(t, err)
+	defer f.Close()
+	sentences, err := ReadTaggedColumns(f, 1, 4)
+	require.NoError(t, err)
+	assert.Equal(t, TupleSlice{
+		{{"Al", "-", "Zaman", ":", "American", "forces", "killed"},
+			{"NNP", "HYPH", "NNP", ":", "JJ", "NNS", "VBD"}},
+		{{"I", "do", "n't", "know", "."}, {"PRP", "VBP", "RB", "VB", "."}}}, sentences)
+
+	// Columns are separated by tabs.
+	_, err = ReadTaggedColumns(strings.NewReader("The DT\nend NN\n"), 0, 1)
+	require.Error(t, err)
+	sentences, err = ReadTaggedColumns(strings.NewReader("The\tDT\r\nend\tNN\r\n\r\n"), 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, TupleSlice{{{"The", "end"}, {"DT", "NN"}}}, sentences)
+
+	for input, message := range map[string]string{
+		"1\tThe\tthe\tDET\n2\tend\tend\tNOUN\tNN":  "line 1: expected at least 5 columns, found 4",
+		"1\tThe\tthe\tDET\tDT\n2\t\tend\tNOUN\tNN": "line 2: empty token or tag",
+	} {
+		_, err = ReadTaggedColumns(strings.NewReader(input), 1, 4)
+		require.Error(t, err, input)
+		assert.Contains(t, err.Error(), message, input)
+	}
+	_, err = ReadTaggedColumns(strings.NewReader(""), -1, 4)
+	assert.Error(t, err)
+}
+
 func TestTrain(t *testing.T) {
-	sentences := ReadTagged(wsj, "|")
-	iter := random(5, 20)
-	tagger.Train(sentences, iter)
+	sentences := readWSJ(t)
+	tagger, err := NewPerceptronTagger()
+	require.NoError(t, err)
+	require.NoError(t, tagger.Train(sentences, 10))
 
 	tagSet := []string{}
-	nrWords := 0
 	for _, tuple := range sentences {
-		nrWords += len(tuple[0])
 		for _, tag := range tuple[1] {
-			if !util.StringInSlice(tag, tagSet) {
+			if !stringInSlice(tag, tagSet) {
 				tagSet = append(tagSet, tag)
 			}
 		}
 	}
+	assert.ElementsMatch(t, tagSet, tagger.Classes())
 
-	assert.Equal(t, nrWords*iter, int(tagger.model.instances))
-	assert.Subset(t, tagger.Classes(), tagSet)
+	correct, total := 0, 0
+	for _, tuple := range sentences {
+		tokens := make([]*Token, len(tuple[0]))
+		for i, word := range tuple[0] {
+			tokens[i] = &Token{Text: word}
+		}
+		for i, tok := range tagger.Tag(tokens) {
+			if tok.Tag == tuple[1][i] {
+				correct++
+			}
+			total++
+		}
+	}
+	assert.Greater(t, float64(correct)/float64(total), 0.9)
+
+	// The same seed gives the same model.
+	again, err := NewPerceptronTagger()
+	require.NoError(t, err)
+	require.NoError(t, again.Train(readWSJ(t), 10))
+	assert.Equal(t, tagger.model.linearWeights, again.model.linearWeights)
+
+	assert.Error(t, tagger.Train(TupleSlice{{{"a", "b"}, {"DT"}}}, 1))
+	assert.Error(t, tagger.Train(sentences, 0))
 }
 
-func random(min, max int) int {
-	rand.Seed(time.Now().Unix())
-	return rand.Intn(max-min) + min
-}*/
+func TestTrainedTaggerModel(t *testing.T) {
+	tagger, err := NewPerceptronTagger()
+	require.NoError(t, err)
+	require.NoError(t, tagger.Train(readWSJ(t), 10))
+
+	model, err := ModelFromData("wsj", UsingTagger(tagger))
+	require.NoError(t, err)
+
+	doc, err := NewDocument("Pierre Vinken is chairman of Elsevier N.V.",
+		UsingModel(model), WithExtraction(false))
+	require.NoError(t, err)
+	for _, tok := range doc.Tokens() {
+		assert.Contains(t, tagger.Classes(), tok.Tag)
+	}
+}
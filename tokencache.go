@@ -0,0 +1,83 @@
+package prose
+
+import (
+	"container/list"
+	"sync"
+)
+
+// A CacheStrategy determines how the tokenizer caches the tokens of
+// whitespace-delimited spans.
+type CacheStrategy int
+
+const (
+	// CachePerCall caches spans for the duration of a single call to
+	// Tokenize (the default).
+	CachePerCall CacheStrategy = iota
+	// CacheOff disables caching, which avoids its overhead for text with
+	// few repeated spans.
+	CacheOff
+	// CacheShared caches spans across calls in a bounded, least-recently
+	// used cache (see UsingCacheSize), which pays off for highly repetitive
+	// input.
+	CacheShared
+)
+
+// DefaultCacheSize is the number of spans held by a shared cache unless
+// another size is given with UsingCacheSize.
+const DefaultCacheSize = 10000
+
+// spanCache is a concurrency-safe LRU cache of tokenized spans.
+type spanCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type spanEntry struct {
+	span   string
+	tokens []*Token
+}
+
+func newSpanCache(size int) *spanCache {
+	return &spanCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element)}
+}
+
+func (c *spanCache) get(span string) ([]*Token, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, found := c.entries[span]; found {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*spanEntry).tokens, true
+	}
+	return nil, false
+}
+
+func (c *spanCache) put(span string, tokens []*Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, found := c.entries[span]; found {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[span] = c.order.PushFront(&spanEntry{span: span, tokens: tokens})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*spanEntry).span)
+	}
+}
+
+// copyTokens returns fresh copies of `tokens`, so cached tokens are never
+// shared with (and modified by) the callers of Tokenize.
+func copyTokens(tokens []*Token) []*Token {
+	copied := make([]*Token, len(tokens))
+	for i, tok := range tokens {
+		t := *tok
+		copied[i] = &t
+	}
+	return copied
+}
@@ -1,7 +1,12 @@
 package prose
 
 import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -13,6 +18,23 @@ type Tokenizer interface {
 	Tokenize(string) []*Token
 }
 
+// A DashPolicy determines how the tokenizer handles Unicode dashes (en
+// dashes, em dashes, and minus signs). A minus sign that begins a number
+// ("−5") is always kept as part of it.
+type DashPolicy int
+
+const (
+	// DashJoiner keeps dashes inside the surrounding token (the default):
+	// "2010–2012" -> [2010–2012].
+	DashJoiner DashPolicy = iota
+	// DashSeparator splits tokens at dashes, which are then dropped:
+	// "2010–2012" -> [2010, 2012].
+	DashSeparator
+	// DashPunct splits tokens at dashes, which become tokens of their own:
+	// "2010–2012" -> [2010, –, 2012].
+	DashPunct
+)
+
 // iterTokenizer splits a sentence into words.
 type iterTokenizer struct {
 	specialRE      *regexp.Regexp
@@ -21,8 +43,23 @@ type iterTokenizer struct {
 	splitCases     []string
 	suffixes       []string
 	prefixes       []string
+	punctRuns      []string
 	emoticons      map[string]struct{}
 	isUnsplittable TokenTester
+	dashes         DashPolicy
+	tracer         func(TraceStep)
+	caching        CacheStrategy
+	cacheSize      int
+	shared         *spanCache
+	whitespace     bool
+}
+
+// A TraceStep records a single decision made by the tokenizer (see
+// UsingTracer).
+type TraceStep struct {
+	Span  string // The text being split.
+	Rule  string // The rule that fired: "special", "prefix", "split-case", "suffix", "dash", or "word".
+	Token string // The token the rule produced.
 }
 
 type TokenizerOptFunc func(*iterTokenizer)
@@ -62,6 +99,14 @@ func UsingPrefixes(x []string) TokenizerOptFunc {
 	}
 }
 
+// Use the provided multi-character punctuation tokens (e.g., "..." or "?!"),
+// which are kept whole instead of being split into single characters.
+func UsingPunctRuns(x []string) TokenizerOptFunc {
+	return func(tokenizer *iterTokenizer) {
+		tokenizer.punctRuns = x
+	}
+}
+
 // Use the provided map of emoticons.
 func UsingEmoticons(x map[string]struct{}) TokenizerOptFunc {
 	return func(tokenizer *iterTokenizer) {
@@ -83,6 +128,43 @@ func UsingSplitCases(x []string) TokenizerOptFunc {
 	}
 }
 
+// Use the provided policy for Unicode dashes.
+func UsingDashPolicy(x DashPolicy) TokenizerOptFunc {
+	return func(tokenizer *iterTokenizer) {
+		tokenizer.dashes = x
+	}
+}
+
+// Use the provided function to trace each split decision. Tracing disables
+// the tokenizer's span cache, so every span is traced.
+func UsingTracer(x func(TraceStep)) TokenizerOptFunc {
+	return func(tokenizer *iterTokenizer) {
+		tokenizer.tracer = x
+	}
+}
+
+// Use the provided cache strategy.
+func UsingCacheStrategy(x CacheStrategy) TokenizerOptFunc {
+	return func(tokenizer *iterTokenizer) {
+		tokenizer.caching = x
+	}
+}
+
+// Use the provided maximum number of spans for a shared cache.
+func UsingCacheSize(x int) TokenizerOptFunc {
+	return func(tokenizer *iterTokenizer) {
+		tokenizer.cacheSize = x
+	}
+}
+
+// Record the whitespace that follows each token (see Token.Whitespace), so
+// that Detokenize can reconstruct the text.
+func UsingPreserveWhitespace(x bool) TokenizerOptFunc {
+	return func(tokenizer *iterTokenizer) {
+		tokenizer.whitespace = x
+	}
+}
+
 // Constructor for default iterTokenizer
 func NewIterTokenizer(opts ...TokenizerOptFunc) *iterTokenizer {
 	tok := new(iterTokenizer)
@@ -90,11 +172,12 @@ func NewIterTokenizer(opts ...TokenizerOptFunc) *iterTokenizer {
 	// Set default parameters
 	tok.contractions = contractions
 	tok.emoticons = emoticons
-	tok.isUnsplittable = func(_ string) bool { return false }
 	tok.prefixes = prefixes
+	tok.punctRuns = punctRuns
 	tok.sanitizer = sanitizer
 	tok.specialRE = internalRE
 	tok.suffixes = suffixes
+	tok.cacheSize = DefaultCacheSize
 
 	// Apply options if provided
 	for _, applyOpt := range opts {
@@ -102,63 +185,220 @@ func NewIterTokenizer(opts ...TokenizerOptFunc) *iterTokenizer {
 	}
 
 	tok.splitCases = append(tok.splitCases, tok.contractions...)
+	if tok.caching == CacheShared {
+		tok.shared = newSpanCache(tok.cacheSize)
+	}
 
 	return tok
 }
 
-func addToken(s string, toks []*Token) []*Token {
+// Fingerprint summarizes the tokenizer's configuration, so that models can
+// detect being used with a tokenizer other than the one they were trained
+// with. Custom TokenTesters and sanitizers can't be compared, so they don't
+// contribute to it.
+func (t *iterTokenizer) Fingerprint() string {
+	emoticons := make([]string, 0, len(t.emoticons))
+	for emoticon := range t.emoticons {
+		emoticons = append(emoticons, emoticon)
+	}
+	sort.Strings(emoticons)
+
+	h := fnv.New64a()
+	for _, part := range [][]string{
+		t.splitCases, t.suffixes, t.prefixes, emoticons, t.punctRuns,
+		{t.specialRE.String(), fmt.Sprint(t.dashes)}} {
+		h.Write([]byte(strings.Join(part, "\x00")))
+		h.Write([]byte{1})
+	}
+	return fmt.Sprintf("iter-%x", h.Sum64())
+}
+
+// tokenizerFingerprint identifies the configuration of `tokenizer`: its
+// Fingerprint method, if it has one, or else its type.
+func tokenizerFingerprint(tokenizer Tokenizer) string {
+	if f, ok := tokenizer.(interface{ Fingerprint() string }); ok {
+		return f.Fingerprint()
+	}
+	return fmt.Sprintf("%T", tokenizer)
+}
+
+// addToken appends `s`, which starts at byte offset `start`, to `toks`
+// unless it's blank.
+func addToken(s string, start int, toks []*Token) []*Token {
 	if strings.TrimSpace(s) != "" {
-		toks = append(toks, &Token{Text: s})
+		toks = append(toks, &Token{Text: s, Start: start, End: start + len(s)})
 	}
 	return toks
 }
 
+// trace reports that `rule` produced `token` from `span`, if tracing.
+func (t *iterTokenizer) trace(span, rule, token string) {
+	if t.tracer != nil && strings.TrimSpace(token) != "" {
+		t.tracer(TraceStep{Span: span, Rule: rule, Token: token})
+	}
+}
+
 func (t *iterTokenizer) isSpecial(token string) bool {
 	_, found := t.emoticons[token]
-	return found || t.specialRE.MatchString(token) || t.isUnsplittable(token)
+	return found || stringInSlice(token, t.punctRuns) || t.specialRE.MatchString(token) ||
+		(t.isUnsplittable != nil && t.isUnsplittable(token)) ||
+		isWebToken(token)
+}
+
+// webLen returns the length of the URL, e-mail address, hashtag, or mention
+// that `token` starts with if it's followed by nothing but punctuation, and
+// zero otherwise.
+func webLen(token string) int {
+	if !strings.ContainsAny(token, "#@") && !strings.HasPrefix(token, "www.") &&
+		!strings.Contains(token, "://") {
+		return 0 // Skip the regexp for most tokens.
+	}
+	loc := webRE.FindStringIndex(token)
+	if loc == nil || strings.Trim(token[loc[1]:], webTrailing) != "" {
+		return 0
+	}
+	return loc[1]
+}
+
+// isWebToken determines if `token` is a URL, e-mail address, hashtag, or
+// mention.
+func isWebToken(token string) bool {
+	return token != "" && webLen(token) == len(token)
+}
+
+// splitSpan tokenizes a whitespace-delimited span of text.
+func (t *iterTokenizer) splitSpan(span string) []*Token {
+	if t.dashes == DashJoiner || dashIndex(span) < 0 {
+		return t.doSplit(span)
+	}
+
+	tokens := []*Token{}
+	for offset := 0; span != ""; {
+		idx := dashIndex(span)
+		if idx < 0 {
+			tokens = append(tokens, shiftTokens(t.doSplit(span), offset)...)
+			break
+		}
+		tokens = append(tokens, shiftTokens(t.doSplit(span[:idx]), offset)...)
+		_, size := utf8.DecodeRuneInString(span[idx:])
+		if t.dashes == DashPunct {
+			tokens = addToken(span[idx:idx+size], offset+idx, tokens)
+			t.trace(span, "dash", span[idx:idx+size])
+		}
+		span = span[idx+size:]
+		offset += idx + size
+	}
+	return tokens
 }
 
+// isDash determines if `r` is one of the Unicode dashes governed by a
+// DashPolicy.
+func isDash(r rune) bool {
+	switch r {
+	case '\u2012', '\u2013', '\u2014', '\u2015', '\u2212':
+		return true
+	}
+	return false
+}
+
+// dashIndex returns the index of the first dash in `span` that a DashPolicy
+// applies to, or -1 if there's none. Minus signs that begin a number ("−5")
+// are part of the number, not dashes.
+func dashIndex(span string) int {
+	prev := ' '
+	for i, r := range span {
+		if isDash(r) && !(r == '\u2212' && isNumericMinus(prev, span[i+utf8.RuneLen(r):])) {
+			return i
+		}
+		prev = r
+	}
+	return -1
+}
+
+// isNumericMinus determines if a minus sign preceded by `prev` and followed
+// by `rest` is the sign of a number.
+func isNumericMinus(prev rune, rest string) bool {
+	next, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsDigit(next) && !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
+
+// isDashToken determines if `word` is a single Unicode dash.
+func isDashToken(word string) bool {
+	r, size := utf8.DecodeRuneInString(word)
+	return size == len(word) && isDash(r)
+}
+
+// doSplit tokenizes `token`; the offsets of the resulting tokens are
+// relative to it.
 func (t *iterTokenizer) doSplit(token string) []*Token {
 	tokens := []*Token{}
 	suffs := []*Token{}
 
-	last := 0
+	last, start := 0, 0
 	for token != "" && utf8.RuneCountInString(token) != last {
+		// URLs (etc.) keep their contents, but not trailing punctuation.
+		web := webLen(token) > 0
 		if t.isSpecial(token) {
 			// We've found a special case (e.g., an emoticon) -- so, we add it as a token without
 			// any further processing.
-			tokens = addToken(token, tokens)
+			tokens = addToken(token, start, tokens)
+			t.trace(token, "special", token)
 			break
 		}
 		last = utf8.RuneCountInString(token)
 		lower := strings.ToLower(token)
-		if hasAnyPrefix(token, t.prefixes) {
-			// Remove prefixes -- e.g., $100 -> [$, 100].
-			tokens = addToken(string(token[0]), tokens)
-			token = token[1:]
-		} else if idx := hasAnyIndex(lower, t.splitCases); idx > -1 {
+		if prefix := longestPrefix(token, t.prefixes); prefix != "" {
+			// Remove prefixes -- e.g., $100 -> [$, 100], --Note -> [--, Note].
+			tokens = addToken(prefix, start, tokens)
+			t.trace(token, "prefix", prefix)
+			token = token[len(prefix):]
+			start += len(prefix)
+		} else if idx := hasAnyIndex(lower, t.splitCases); idx > 0 && !web {
 			// Handle "they'll", "I'll", "Don't", "won't", amount($).
 			//
 			// they'll -> [they, 'll].
 			// don't -> [do, n't].
 			// amount($) -> [amount, (, $, )].
-			tokens = addToken(token[:idx], tokens)
+			tokens = addToken(token[:idx], start, tokens)
+			t.trace(token, "split-case", token[:idx])
 			token = token[idx:]
-		} else if hasAnySuffix(token, t.suffixes) {
-			// Remove suffixes -- e.g., Well) -> [Well, )].
+			start += idx
+		} else if suffix := t.suffix(token, web); suffix != "" {
+			// Remove suffixes -- e.g., Well) -> [Well, )], wait... -> [wait, ...].
+			end := start + len(token)
 			suffs = append([]*Token{
-				{Text: string(token[len(token)-1])}},
+				{Text: suffix, Start: end - len(suffix), End: end}},
 				suffs...)
-			token = token[:len(token)-1]
+			t.trace(token, "suffix", suffix)
+			token = token[:len(token)-len(suffix)]
 		} else {
-			tokens = addToken(token, tokens)
+			tokens = addToken(token, start, tokens)
+			t.trace(token, "word", token)
 		}
 	}
 
 	return append(tokens, suffs...)
 }
 
-// tokenize splits a sentence into a slice of words.
+// suffix returns the suffix doSplit should remove from `token`, if any: the
+// longest of the tokenizer's suffixes and punctuation runs or, if `web` (see
+// webLen), the last character.
+func (t *iterTokenizer) suffix(token string, web bool) string {
+	suffix := longestSuffix(token, t.suffixes)
+	if run := longestSuffix(token, t.punctRuns); len(run) > len(suffix) {
+		suffix = run
+	}
+	if suffix != "" || !web {
+		return suffix
+	}
+	_, size := utf8.DecodeLastRuneInString(token)
+	return token[len(token)-size:]
+}
+
+// Tokenize splits a sentence into a slice of words.
+//
+// The tokens' Start and End are byte offsets into `text`, even where the
+// sanitizer changed it.
 func (t *iterTokenizer) Tokenize(text string) []*Token {
 	var tokens []*Token
 
@@ -166,7 +406,10 @@ func (t *iterTokenizer) Tokenize(text string) []*Token {
 	length := len(clean)
 
 	start, index := 0, 0
-	cache := map[string][]*Token{}
+	var cache map[string][]*Token
+	if t.caching == CachePerCall {
+		cache = map[string][]*Token{}
+	}
 	for index <= length {
 		uc, size := utf8.DecodeRuneInString(clean[index:])
 		if size == 0 {
@@ -176,14 +419,7 @@ func (t *iterTokenizer) Tokenize(text string) []*Token {
 		}
 		if unicode.IsSpace(uc) != white {
 			if start < index {
-				span := clean[start:index]
-				if toks, found := cache[span]; found {
-					tokens = append(tokens, toks...)
-				} else {
-					toks := t.doSplit(span)
-					cache[span] = toks
-					tokens = append(tokens, toks...)
-				}
+				tokens = append(tokens, t.cachedSplit(clean[start:index], start, cache)...)
 			}
 			if uc == ' ' {
 				start = index + 1
@@ -196,13 +432,302 @@ func (t *iterTokenizer) Tokenize(text string) []*Token {
 	}
 
 	if start < index {
-		tokens = append(tokens, t.doSplit(clean[start:index])...)
+		tokens = append(tokens, t.cachedSplit(clean[start:index], start, cache)...)
+	}
+
+	if t.whitespace {
+		recordWhitespace(clean, tokens)
+	}
+	if clean != text {
+		offsets := sanitizedOffsets(t.sanitizer, text, clean)
+		for _, tok := range tokens {
+			tok.Start, tok.End = offsets[tok.Start], offsets[tok.End]
+		}
 	}
 
 	return tokens
 }
 
+// recordWhitespace sets the Whitespace of `tokens`, whose offsets are into
+// `clean`: the whitespace between each token and the next (or the end).
+// Only the last token of a whitespace-delimited span has any. The first
+// token's LeadingWhitespace is the whitespace before it.
+func recordWhitespace(clean string, tokens []*Token) {
+	if len(tokens) > 0 {
+		tokens[0].LeadingWhitespace = onlySpace(clean[:tokens[0].Start])
+	}
+	for i, tok := range tokens {
+		end := len(clean)
+		if i+1 < len(tokens) {
+			end = tokens[i+1].Start
+		}
+		tok.Whitespace = onlySpace(clean[tok.End:end])
+	}
+}
+
+// onlySpace returns the whitespace of `text`; dashes dropped by
+// DashSeparator aren't whitespace.
+func onlySpace(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return r
+		}
+		return -1
+	}, text)
+}
+
+// Detokenize reconstructs the text `tokens` were created from by a
+// tokenizer using UsingPreserveWhitespace: the sanitized text, less any
+// dashes dropped by DashSeparator. Text that's entirely whitespace has no
+// tokens, so it can't be reconstructed.
+func Detokenize(tokens []*Token) string {
+	var b strings.Builder
+	for _, tok := range tokens {
+		b.WriteString(tok.LeadingWhitespace)
+		b.WriteString(tok.Text)
+		b.WriteString(tok.Whitespace)
+	}
+	return b.String()
+}
+
+const (
+	// streamChunk is the size of TokenizeFunc's reads.
+	streamChunk = 64 * 1024
+	// maxStreamWindow is the most text TokenizeFunc buffers.
+	maxStreamWindow = 1024 * 1024
+)
+
+// TokenizeFunc tokenizes the text read from `r`, calling `fn` with each
+// token in turn. The tokens' Start and End are byte offsets into the whole
+// stream. It stops at the first error from `r` or `fn`, returning it.
+//
+// The text is read in chunks and tokenized up to the end of the last run of
+// whitespace in each, so only the text after it is buffered; the tokens are the same as
+// Tokenize's unless there's a run of more than 1MB without whitespace,
+// which is split.
+func (t *iterTokenizer) TokenizeFunc(r io.Reader, fn func(*Token) error) error {
+	chunk := make([]byte, streamChunk)
+	buf := []byte{}
+	base := 0
+	for {
+		n, err := r.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+
+		eof := errors.Is(err, io.EOF)
+		if err != nil && !eof {
+			return fmt.Errorf("unable to read text: %w", err)
+		}
+
+		cut := len(buf)
+		if !eof {
+			cut = streamCut(buf, maxStreamWindow)
+		}
+		if cut > 0 {
+			for _, tok := range t.Tokenize(string(buf[:cut])) {
+				tok.Start += base
+				tok.End += base
+				if err = fn(tok); err != nil {
+					return err
+				}
+			}
+			base += cut
+			buf = append(buf[:0], buf[cut:]...)
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+// streamCut returns the length of the prefix of `buf` that can be tokenized
+// independently of the text that follows it: up to the end of its last
+// complete run of whitespace (i.e., the start of the word after it) or, if
+// there is none and `buf` holds at least `max` bytes, its last complete rune.
+func streamCut(buf []byte, max int) int {
+	for i := len(buf); i > 0; {
+		r, size := utf8.DecodeLastRune(buf[:i])
+		if unicode.IsSpace(r) && i < len(buf) && utf8.FullRune(buf[i:]) {
+			// The whitespace ends here, so the tokens before it (and their
+			// Whitespace) are complete.
+			if next, _ := utf8.DecodeRune(buf[i:]); !unicode.IsSpace(next) {
+				return i
+			}
+		}
+		i -= size
+	}
+	if len(buf) < max {
+		return 0
+	}
+	start := len(buf) - 1
+	for start > 0 && !utf8.RuneStart(buf[start]) {
+		start--
+	}
+	if utf8.FullRune(buf[start:]) {
+		return len(buf)
+	}
+	return start
+}
+
+// cachedSplit tokenizes `span`, which starts at byte offset `start`,
+// consulting the cache (`local`, for CachePerCall) first.
+func (t *iterTokenizer) cachedSplit(span string, start int, local map[string][]*Token) []*Token {
+	if t.tracer != nil || t.caching == CacheOff {
+		return shiftTokens(t.splitSpan(span), start)
+	}
+
+	if t.shared != nil {
+		toks, found := t.shared.get(span)
+		if !found {
+			toks = t.splitSpan(span)
+			t.shared.put(span, toks)
+		}
+		return shiftTokens(copyTokens(toks), start)
+	}
+
+	toks, found := local[span]
+	if !found {
+		toks = t.splitSpan(span)
+		local[span] = toks
+	}
+	return shiftTokens(copyTokens(toks), start)
+}
+
+// shiftTokens moves the offsets of `tokens` forward by `offset` bytes.
+func shiftTokens(tokens []*Token, offset int) []*Token {
+	if offset != 0 {
+		for _, tok := range tokens {
+			tok.Start += offset
+			tok.End += offset
+		}
+	}
+	return tokens
+}
+
+// sanitizedOffsets maps each byte offset into `clean`, the result of
+// applying `sanitizer` to `text`, to the corresponding offset into `text`.
+//
+// Replacements never cross whitespace (in practice), so we align the
+// whitespace-delimited runs of the two texts one at a time.
+func sanitizedOffsets(sanitizer *strings.Replacer, text, clean string) []int {
+	offsets := make([]int, 0, len(clean)+1)
+	for _, run := range whitespaceRuns(text) {
+		piece := text[run[0]:run[1]]
+		replaced := sanitizer.Replace(piece)
+		if replaced == piece {
+			for i := run[0]; i < run[1]; i++ {
+				offsets = append(offsets, i)
+			}
+			continue
+		}
+		for _, i := range alignReplaced(sanitizer, piece, replaced) {
+			offsets = append(offsets, run[0]+i)
+		}
+	}
+	offsets = append(offsets, len(text))
+
+	if len(offsets) != len(clean)+1 {
+		// A replacement spans whitespace, so we align the whole text.
+		offsets = append(alignReplaced(sanitizer, text, clean), len(text))
+	}
+	return offsets
+}
+
+// maxReplaced bounds the length of the text that alignReplaced expects a
+// sanitizer to replace at once.
+const maxReplaced = 32
+
+// alignReplaced maps each byte offset into `replaced`, the result of
+// applying `sanitizer` to `text`, to the corresponding offset into `text`;
+// offsets within the output of a replacement map to its start.
+//
+// It steps through `text` one replacement (or unchanged rune) at a time,
+// applying `sanitizer` only to a bounded window at each step, so it takes
+// time linear in the length of `text`.
+func alignReplaced(sanitizer *strings.Replacer, text, replaced string) []int {
+	offsets := make([]int, 0, len(replaced))
+	for k := 0; k < len(text); {
+		window := text[k:]
+		if len(window) > maxReplaced {
+			end := maxReplaced
+			for end > 1 && !utf8.RuneStart(window[end]) {
+				end--
+			}
+			window = window[:end]
+		}
+		if size := replacedLength(sanitizer, window); size > 0 {
+			for n := len(sanitizer.Replace(window[:size])); n > 0; n-- {
+				offsets = append(offsets, k)
+			}
+			k += size
+		} else {
+			_, size = utf8.DecodeRuneInString(window)
+			for i := 0; i < size; i++ {
+				offsets = append(offsets, k+i)
+			}
+			k += size
+		}
+	}
+
+	// A replacement longer than maxReplaced would leave the two out of step.
+	for len(offsets) < len(replaced) {
+		last := 0
+		if len(offsets) > 0 {
+			last = offsets[len(offsets)-1]
+		}
+		offsets = append(offsets, last)
+	}
+	return offsets[:len(replaced)]
+}
+
+// replacedLength returns the length of the text that `sanitizer` replaces at
+// the start of `window`, or 0 if it leaves the first rune as is.
+func replacedLength(sanitizer *strings.Replacer, window string) int {
+	whole := sanitizer.Replace(window)
+	_, size := utf8.DecodeRuneInString(window)
+	if whole == window[:size]+sanitizer.Replace(window[size:]) {
+		return 0
+	}
+	for m := size; m <= len(window); {
+		head := sanitizer.Replace(window[:m])
+		if head != window[:m] && whole == head+sanitizer.Replace(window[m:]) {
+			return m
+		} else if m == len(window) {
+			break
+		}
+		_, next := utf8.DecodeRuneInString(window[m:])
+		m += next
+	}
+	return size
+}
+
+// whitespaceRuns returns the [start, end) byte offsets of the maximal runs
+// of whitespace and non-whitespace in `text`.
+func whitespaceRuns(text string) [][2]int {
+	runs := [][2]int{}
+	start, white := 0, false
+	for i, r := range text {
+		if i > 0 && unicode.IsSpace(r) != white {
+			runs = append(runs, [2]int{start, i})
+			start = i
+		}
+		white = unicode.IsSpace(r)
+	}
+	if start < len(text) {
+		runs = append(runs, [2]int{start, len(text)})
+	}
+	return runs
+}
+
 var internalRE = regexp.MustCompile(`^(?:[A-Za-z]\.){2,}$|^[A-Z][a-z]{1,2}\.$`)
+
+// webRE matches the URL, e-mail address, hashtag, or mention at the start
+// of a token, less any trailing punctuation (webTrailing).
+var webRE = regexp.MustCompile(`^(?:` +
+	`(?:(?:https?|ftp)://|www\.)\S*[^\s.,;:!?)"'\]]|` +
+	`[\w.+-]+@[\w-]+(?:\.[\w-]+)+|` +
+	`#\w*[A-Za-z]\w*|` +
+	`@\w+)`)
+var webTrailing = `.,;:!?)"']`
 var sanitizer = strings.NewReplacer(
 	"\u201c", `"`,
 	"\u201d", `"`,
@@ -212,6 +737,7 @@ var sanitizer = strings.NewReplacer(
 var contractions = []string{"'ll", "'s", "'re", "'m", "n't"}
 var suffixes = []string{",", ")", `"`, "]", "!", ";", ".", "?", ":", "'"}
 var prefixes = []string{"$", "(", `"`, "["}
+var punctRuns = []string{"...", "!!!", "???", "?!", "!?", "!!", "??"}
 var emoticons = map[string]struct{}{
 	"(-8":         {},
 	"(-;":         {},
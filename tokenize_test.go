@@ -2,9 +2,17 @@ package prose
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
 )
@@ -190,6 +198,11 @@ func TestTokenizationSplitCases(t *testing.T) {
 	tokens := tokenizer.Tokenize("amount($)")
 	expected := []string{"amount", "(", "$", ")"}
 	checkTokens(t, tokens, expected, "TokenizationSplitCases(custom-found)")
+
+	// A split case at the start of a word doesn't drop it.
+	tokens = NewIterTokenizer().Tokenize("from Carlsbad. 'Remarkable as 'sup")
+	expected = []string{"from", "Carlsbad", ".", "'Remarkable", "as", "'sup"}
+	checkTokens(t, tokens, expected, "TokenizationSplitCases(leading)")
 }
 
 func TestTokenizationContractions(t *testing.T) {
@@ -233,3 +246,386 @@ func BenchmarkTokenizationSimple(b *testing.B) {
 		}
 	}
 }
+
+// stressKinds are the kinds of synthetic text generated by stressCorpus.
+var stressKinds = []string{"long-tokens", "emoji", "punctuation", "boilerplate"}
+
+// stressCorpus deterministically generates `n` words of synthetic text that
+// stresses a particular part of the tokenizer.
+func stressCorpus(kind string, n int) string {
+	rng := rand.New(rand.NewSource(1))
+	pick := func(options []string) string {
+		return options[rng.Intn(len(options))]
+	}
+
+	words := make([]string, n)
+	for i := range words {
+		switch kind {
+		case "long-tokens":
+			words[i] = strings.Repeat(pick([]string{"ab", "x-", "9.", "_z"}), 20+rng.Intn(100))
+		case "emoji":
+			words[i] = pick([]string{":-)", "(ಠ_ಠ)", "😀😀", "¯\\(ツ)/¯", "xD", "🎉", "word", "<3"})
+		case "punctuation":
+			words[i] = pick([]string{"(a)", `"b,"`, "c.)", "[d];", "e?!", "$5,000", "f...", "'g'", "don't"})
+		default:
+			words[i] = pick([]string{
+				"WHEREAS", "the", "Party", "of", "the", "first", "part", "(the",
+				`"Licensor")`, "shall,", "notwithstanding", "Section", "4.2(b),",
+				"indemnify", "U.S.", "Inc.", "hereinafter;"})
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func TestStressCorpus(t *testing.T) {
+	tokenizer := NewIterTokenizer()
+	for _, kind := range stressKinds {
+		text := stressCorpus(kind, 500)
+		require.Equal(t, text, stressCorpus(kind, 500), kind)
+		for _, tok := range tokenizer.Tokenize(text) {
+			require.NotEqual(t, "", strings.TrimSpace(tok.Text), kind)
+		}
+	}
+}
+
+func BenchmarkTokenizationStress(b *testing.B) {
+	for _, kind := range stressKinds {
+		text := stressCorpus(kind, 10000)
+		b.Run(kind, func(b *testing.B) {
+			tokenizer := NewIterTokenizer()
+			b.SetBytes(int64(len(text)))
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				tokenizer.Tokenize(text)
+			}
+		})
+	}
+}
+
+func TestTokenizationDashPolicy(t *testing.T) {
+	text := "From 2010–2012 we grew—fast. It was −5 degrees."
+	cases := []struct {
+		policy   DashPolicy
+		expected []string
+	}{
+		{DashJoiner, []string{
+			"From", "2010–2012", "we", "grew—fast", ".", "It", "was", "−5",
+			"degrees", "."}},
+		{DashSeparator, []string{
+			"From", "2010", "2012", "we", "grew", "fast", ".", "It", "was", "−5",
+			"degrees", "."}},
+		{DashPunct, []string{
+			"From", "2010", "–", "2012", "we", "grew", "—", "fast", ".", "It",
+			"was", "−5", "degrees", "."}},
+	}
+	for _, c := range cases {
+		tokenizer := NewIterTokenizer(UsingDashPolicy(c.policy))
+		checkTokens(t, tokenizer.Tokenize(text), c.expected, "DashPolicy")
+	}
+
+	// Only a minus sign that begins a number is kept.
+	tokenizer := NewIterTokenizer(UsingDashPolicy(DashSeparator))
+	checkTokens(t, tokenizer.Tokenize("−5 to 10−12 (−3)"),
+		[]string{"−5", "to", "10", "12", "(", "−3", ")"}, "DashPolicy")
+
+	doc, err := NewDocument("It rose 5% — a record.",
+		UsingTokenizer(NewIterTokenizer(UsingDashPolicy(DashPunct))))
+	require.NoError(t, err)
+	require.Equal(t, "—", doc.Tokens()[3].Text)
+	require.Equal(t, "-", doc.Tokens()[3].Tag)
+}
+
+func TestTokenizationTrace(t *testing.T) {
+	steps := []TraceStep{}
+	tokenizer := NewIterTokenizer(UsingTracer(func(step TraceStep) {
+		steps = append(steps, step)
+	}))
+
+	tokens := tokenizer.Tokenize(`"They'll pay $5." :-) "They'll pay $5."`)
+	checkTokens(t, tokens, []string{
+		`"`, "They", "'ll", "pay", "$", "5", ".", `"`, ":-)",
+		`"`, "They", "'ll", "pay", "$", "5", ".", `"`}, "Trace")
+
+	require.Len(t, steps, len(tokens))
+	require.Equal(t, []TraceStep{
+		{Span: `"They'll`, Rule: "prefix", Token: `"`},
+		{Span: "They'll", Rule: "split-case", Token: "They"},
+		{Span: "'ll", Rule: "word", Token: "'ll"},
+		{Span: "pay", Rule: "word", Token: "pay"},
+		{Span: "$5.\"", Rule: "prefix", Token: "$"},
+		{Span: "5.\"", Rule: "suffix", Token: `"`},
+		{Span: "5.", Rule: "suffix", Token: "."},
+		{Span: "5", Rule: "word", Token: "5"},
+		{Span: ":-)", Rule: "special", Token: ":-)"},
+	}, steps[:9])
+}
+
+func TestTokenizationCacheStrategy(t *testing.T) {
+	text := "The buyer paid. The seller paid. The buyer left."
+	expected := []string{
+		"The", "buyer", "paid", ".", "The", "seller", "paid", ".", "The",
+		"buyer", "left", "."}
+
+	for _, strategy := range []CacheStrategy{CachePerCall, CacheOff, CacheShared} {
+		tokenizer := NewIterTokenizer(UsingCacheStrategy(strategy), UsingCacheSize(2))
+		for i := 0; i < 2; i++ {
+			tokens := tokenizer.Tokenize(text)
+			checkTokens(t, tokens, expected, "CacheStrategy")
+
+			// Repeated spans must not share tokens.
+			tokens[0].Tag = "DT"
+			require.Equal(t, "", tokens[4].Tag)
+		}
+	}
+
+	cache := newSpanCache(2)
+	cache.put("a", []*Token{{Text: "a"}})
+	cache.put("b", []*Token{{Text: "b"}})
+	_, found := cache.get("a")
+	require.True(t, found)
+	cache.put("c", []*Token{{Text: "c"}})
+	_, found = cache.get("b")
+	require.False(t, found)
+	_, found = cache.get("a")
+	require.True(t, found)
+}
+
+func TestTokenizationOffsets(t *testing.T) {
+	for _, text := range []string{
+		"Well) I don&rsquo;t know :-) — “café” costs $100.",
+		"Naïve résumé (über) ☺ won't: fine!",
+		"  leading\tand\ntrailing whitespace  ",
+		"Smart ‘quotes’ and &rsquo;entities&rsquo;",
+	} {
+		for _, tokenizer := range []Tokenizer{
+			NewIterTokenizer(),
+			NewIterTokenizer(UsingCacheStrategy(CacheShared)),
+			NewIterTokenizer(UsingDashPolicy(DashPunct)),
+		} {
+			for _, tok := range tokenizer.Tokenize(text) {
+				require.True(t, tok.Start >= 0 && tok.Start < tok.End && tok.End <= len(text), "%q: %v", text, tok)
+				raw := text[tok.Start:tok.End]
+				if raw != tok.Text {
+					// Only the sanitizer may change a token's text.
+					require.Equal(t, tok.Text, sanitizer.Replace(raw), "%q", text)
+				}
+			}
+		}
+	}
+
+	tokens := NewIterTokenizer().Tokenize("Well) don&rsquo;t :-)")
+	spans := [][2]int{}
+	for _, tok := range tokens {
+		spans = append(spans, [2]int{tok.Start, tok.End})
+	}
+	require.Equal(t, [][2]int{{0, 4}, {4, 5}, {6, 8}, {8, 17}, {18, 21}}, spans)
+
+	// Replacements that span whitespace are aligned across the whole text.
+	tokenizer := NewIterTokenizer(UsingSanitizer(strings.NewReplacer("New York", "NYC", "“", `"`, "”", `"`)))
+	text := strings.Repeat("I ♥ New York “now”. ", 2000)
+	tokens = tokenizer.Tokenize(text)
+	require.Len(t, tokens, 2000*7)
+	for i, tok := range tokens[:8] {
+		raw := []string{"I", "♥", "New York", "“", "now", "”", ".", "I"}[i]
+		require.Equal(t, raw, text[tok.Start:tok.End], "%v", tok)
+	}
+	last := tokens[len(tokens)-2]
+	require.Equal(t, "”", text[last.Start:last.End])
+
+	// Document offsets refer to its (original) text.
+	text = "Ｐａｒｉｓ is nice. Bob left."
+	doc, err := NewDocument(text, WithUnicodeNormalization(UnicodeNFKC),
+		WithBoundaries(regexp.MustCompile(`\. `)), WithExtraction(false))
+	require.NoError(t, err)
+	found := map[string]string{}
+	for _, tok := range doc.Tokens() {
+		found[tok.Text] = text[tok.Start:tok.End]
+	}
+	require.Equal(t, "Ｐａｒｉｓ", found["Paris"])
+	require.Equal(t, "Bob", found["Bob"])
+}
+
+func TestTokenizationPreserveWhitespace(t *testing.T) {
+	for _, text := range []string{
+		"Well) I don't know :-)\t\t$100.\n\nNew   paragraph (here).  ",
+		"“Smart” quotes\r\nand &rsquo;entities&rsquo; they'll see\n",
+		"From 2010–2012 we grew — fast.",
+		"single",
+		"\n\t  Leading whitespace is kept.",
+		"",
+	} {
+		for _, tokenizer := range []Tokenizer{
+			NewIterTokenizer(UsingPreserveWhitespace(true)),
+			NewIterTokenizer(UsingPreserveWhitespace(true), UsingCacheStrategy(CacheShared)),
+			NewIterTokenizer(UsingPreserveWhitespace(true), UsingDashPolicy(DashPunct)),
+		} {
+			tokens := tokenizer.Tokenize(text)
+			require.Equal(t, sanitizer.Replace(text), Detokenize(tokens), "%q", text)
+		}
+	}
+
+	// Only the last token of a span has whitespace.
+	tokens := NewIterTokenizer(UsingPreserveWhitespace(true)).Tokenize("($100)  ok")
+	checkTokens(t, tokens, []string{"(", "$", "100", ")", "ok"}, "PreserveWhitespace")
+	for i, want := range []string{"", "", "", "  ", ""} {
+		require.Equal(t, want, tokens[i].Whitespace)
+	}
+	tokens = NewIterTokenizer(UsingPreserveWhitespace(true)).Tokenize("  two words")
+	require.Equal(t, "  ", tokens[0].LeadingWhitespace)
+	require.Equal(t, "", tokens[1].LeadingWhitespace)
+
+	// Whitespace is opt-in.
+	for _, tok := range NewIterTokenizer().Tokenize("a  b\tc") {
+		require.Equal(t, "", tok.Whitespace)
+	}
+
+	// Dropped dashes aren't whitespace.
+	tokenizer := NewIterTokenizer(UsingPreserveWhitespace(true), UsingDashPolicy(DashSeparator))
+	require.Equal(t, "From 2010 2012 we grew  fast.", Detokenize(tokenizer.Tokenize("From 2010 –2012 we grew — fast.")))
+}
+
+func TestTokenizationWebTokens(t *testing.T) {
+	tokenizer := NewIterTokenizer()
+	cases := map[string][]string{
+		"see https://x.com.":         {"see", "https://x.com", "."},
+		"https://example.com/a.b":    {"https://example.com/a.b"},
+		"(www.x.org/it's?)":          {"(", "www.x.org/it's", "?", ")"},
+		`"https://x.com/q?a=1&b=2",`: {`"`, "https://x.com/q?a=1&b=2", `"`, ","},
+		"Mail Jane.Doe@example.com.": {"Mail", "Jane.Doe@example.com", "."},
+		"@john's #golang, #1!":       {"@john", "'s", "#golang", ",", "#1", "!"},
+	}
+	for text, expected := range cases {
+		checkTokens(t, tokenizer.Tokenize(text), expected, text)
+	}
+
+	doc, err := NewDocument("Email jane@example.com or visit https://example.com/docs today.")
+	require.NoError(t, err)
+	for _, tok := range doc.Tokens() {
+		if isWebToken(tok.Text) {
+			require.Equal(t, "NN", tok.Tag, tok.Text)
+		}
+	}
+}
+
+func TestTokenizationMultiCharAffixes(t *testing.T) {
+	tokenizer := NewIterTokenizer(
+		UsingPrefixes(append([]string{"--", "''", "«"}, prefixes...)),
+		UsingSuffixes(append([]string{"...", "--", "''", "»"}, suffixes...)))
+
+	tokens := tokenizer.Tokenize("--Note: wait... ''quoted'' «naïve» end--")
+	checkTokens(t, tokens, []string{
+		"--", "Note", ":", "wait", "...", "''", "quoted", "''", "«", "naïve", "»",
+		"end", "--"}, "MultiCharAffixes")
+	text := "--Note: wait... ''quoted'' «naïve» end--"
+	for _, tok := range tokens {
+		require.Equal(t, tok.Text, text[tok.Start:tok.End])
+	}
+
+	// Single-character affixes are unchanged.
+	checkTokens(t, NewIterTokenizer().Tokenize("wait. ($5)"),
+		[]string{"wait", ".", "(", "$", "5", ")"}, "SingleCharAffixes")
+}
+
+func TestTokenizationMultiByteAffixes(t *testing.T) {
+	tokenizer := NewIterTokenizer(
+		UsingSanitizer(strings.NewReplacer()),
+		UsingPrefixes(append([]string{"«", "“", "‘"}, prefixes...)),
+		UsingSuffixes(append([]string{"»", "…", "”", "’"}, suffixes...)))
+
+	cases := map[string][]string{
+		"«Bonjour…», dit-il.":    {"«", "Bonjour", "…", "»", ",", "dit-il", "."},
+		"“Quoted” and ‘single’!": {"“", "Quoted", "”", "and", "‘", "single", "’", "!"},
+		`("«Mixed»")`:            {"(", `"`, "«", "Mixed", "»", `"`, ")"},
+		"Wait…":                  {"Wait", "…"},
+		"»«":                     {"»«"},
+	}
+	for text, expected := range cases {
+		tokens := tokenizer.Tokenize(text)
+		checkTokens(t, tokens, expected, text)
+		for _, tok := range tokens {
+			require.True(t, utf8.ValidString(tok.Text), "%q: %q", text, tok.Text)
+			require.Equal(t, tok.Text, text[tok.Start:tok.End], text)
+		}
+	}
+}
+
+func TestTokenizationPunctRuns(t *testing.T) {
+	cases := map[string][]string{
+		"Wait... what?! No!!! ok.": {"Wait", "...", "what", "?!", "No", "!!!", "ok", "."},
+		`"Really?!"`:               {`"`, "Really", "?!", `"`},
+		"...":                      {"..."},
+		"Why?? Hmm!?":              {"Why", "??", "Hmm", "!?"},
+	}
+	for text, expected := range cases {
+		checkTokens(t, NewIterTokenizer().Tokenize(text), expected, text)
+	}
+
+	tokenizer := NewIterTokenizer(UsingPunctRuns([]string{"!!!"}))
+	checkTokens(t, tokenizer.Tokenize("Wait... No!!!"),
+		[]string{"Wait", ".", ".", ".", "No", "!!!"}, "PunctRuns(custom)")
+	require.NotEqual(t, NewIterTokenizer().Fingerprint(), tokenizer.Fingerprint())
+}
+
+func TestTokenizeFunc(t *testing.T) {
+	content, err := ioutil.ReadFile(filepath.Join(testdata, "sherlock.txt"))
+	require.NoError(t, err)
+	texts := []string{
+		string(content[:20000]),
+		"Naïve “résumé” — café costs €5.\n\n  Ünïcödé… wait?! ",
+		"",
+	}
+
+	tokenizer := NewIterTokenizer(UsingPreserveWhitespace(true))
+	for _, text := range texts {
+		expected := tokenizer.Tokenize(text)
+		for _, r := range []io.Reader{
+			strings.NewReader(text),
+			iotest.OneByteReader(strings.NewReader(text)),
+			iotest.HalfReader(strings.NewReader(text)),
+		} {
+			tokens := []*Token{}
+			err = tokenizer.TokenizeFunc(r, func(tok *Token) error {
+				tokens = append(tokens, tok)
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, derefTokens(expected), derefTokens(tokens))
+		}
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	err = tokenizer.TokenizeFunc(strings.NewReader("a b c"), func(tok *Token) error {
+		count++
+		return stop
+	})
+	require.Equal(t, stop, err)
+	require.Equal(t, 1, count)
+
+	err = tokenizer.TokenizeFunc(iotest.ErrReader(stop), func(*Token) error { return nil })
+	require.True(t, errors.Is(err, stop))
+}
+
+func derefTokens(tokens []*Token) []Token {
+	values := make([]Token, len(tokens))
+	for i, tok := range tokens {
+		values[i] = *tok
+	}
+	return values
+}
+
+func TestStreamCut(t *testing.T) {
+	require.Equal(t, 0, streamCut([]byte("ab "), 10))
+	require.Equal(t, 3, streamCut([]byte("ab cd"), 10))
+	require.Equal(t, 7, streamCut([]byte("ab \ncd e"), 10))
+	// The cut is at the end of the whitespace, not its start.
+	require.Equal(t, 6, streamCut([]byte("ab \t\n cd"), 10))
+	require.Equal(t, 6, streamCut([]byte("ab \u00a0 cd"), 10))
+	// An incomplete space (U+00A0) may continue the whitespace.
+	require.Equal(t, 0, streamCut([]byte("ab \xc2"), 10))
+	require.Equal(t, 0, streamCut([]byte("abcd"), 10))
+	require.Equal(t, 4, streamCut([]byte("abcd"), 4))
+	// An incomplete rune stays buffered.
+	require.Equal(t, 3, streamCut([]byte("abc\xc3"), 4))
+	require.Equal(t, 5, streamCut([]byte("abc\xc3\xa9"), 4))
+}
@@ -0,0 +1,89 @@
+package prose
+
+// An NERSource is how the NER classifier arrived at a decision.
+type NERSource int
+
+const (
+	// SourceModel decisions are the classifier's predictions.
+	SourceModel NERSource = iota
+	// SourceLookup decisions come from a distilled model's lookup table
+	// (see Distill).
+	SourceLookup
+	// SourceInput decisions are labels given with pre-tokenized input (see
+	// UsingTokens), which the classifier keeps.
+	SourceInput
+)
+
+// An NERStep is one decision of the NER classifier, in the order they were
+// made (see Document.NERTrace).
+//
+// The classifier labels the tokens of each run of text from left to right,
+// and the (simplified) label of each token is a feature of the next one's
+// decision, so an early mistake can cascade.
+type NERStep struct {
+	Token      int       // The index of the token in Document.Tokens.
+	Label      string    // The label assigned to the token.
+	Confidence float64   // The confidence of Label; see Token.Confidence.
+	Source     NERSource // How the label was decided.
+
+	// Previous is the history feature the decision used: the History of the
+	// preceding step in the run, or NoneFeat at the start of one.
+	Previous string
+	// History is the simplified label passed on to the next decision.
+	History string
+}
+
+// WithNERTrace records the decisions of the NER classifier, which
+// Document.NERTrace returns.
+func WithNERTrace(include bool) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Trace = include
+	}
+}
+
+// NERTrace returns the decisions `doc`'s NER classifier made, or nil if it
+// was created without WithNERTrace.
+//
+// Labels are those assigned before entities were trimmed, filtered, and
+// reconciled (see WithMinConfidence and WithConsistency).
+func (doc *Document) NERTrace() []NERStep {
+	if doc.trace == nil {
+		return nil
+	}
+	return append([]NERStep{}, doc.trace...)
+}
+
+// nerTrace collects the decisions of the NER classifier by token.
+type nerTrace struct {
+	steps  []NERStep
+	tokens []*Token
+}
+
+func (t *nerTrace) record(tok *Token, source NERSource, previous, history string) {
+	if t == nil {
+		return
+	}
+	t.steps = append(t.steps, NERStep{
+		Label:      tok.Label,
+		Confidence: tok.Confidence,
+		Source:     source,
+		Previous:   previous,
+		History:    history,
+	})
+	t.tokens = append(t.tokens, tok)
+}
+
+// resolve returns the recorded steps with their Token set to the index of
+// their token in `tokens`.
+func (t *nerTrace) resolve(tokens []*Token) []NERStep {
+	index := make(map[*Token]int, len(tokens))
+	for i, tok := range tokens {
+		index[tok] = i
+	}
+	steps := make([]NERStep, len(t.steps))
+	for i, step := range t.steps {
+		step.Token = index[t.tokens[i]]
+		steps[i] = step
+	}
+	return steps
+}
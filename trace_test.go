@@ -0,0 +1,59 @@
+package prose
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNERTrace(t *testing.T) {
+	text := "Tim Cook visited Paris.\n\nThen he left."
+
+	doc, err := NewDocument(text, WithBoundaries(regexp.MustCompile(`\n\n`)))
+	require.NoError(t, err)
+	assert.Nil(t, doc.NERTrace())
+
+	doc, err = NewDocument(text, WithBoundaries(regexp.MustCompile(`\n\n`)), WithNERTrace(true))
+	require.NoError(t, err)
+	tokens := doc.Tokens()
+	trace := doc.NERTrace()
+	require.Len(t, trace, len(tokens))
+
+	for i, step := range trace {
+		assert.Equal(t, i, step.Token)
+		assert.Equal(t, tokens[i].Label, step.Label)
+		assert.Equal(t, tokens[i].Confidence, step.Confidence)
+		assert.Equal(t, SourceModel, step.Source)
+		assert.Equal(t, simplePOS(step.Label), step.History)
+	}
+
+	// History is passed on within each section and restarts at the next.
+	assert.Equal(t, NoneFeat, trace[0].Previous)
+	for i := 1; i < len(trace); i++ {
+		if tokens[i].Text == "Then" {
+			assert.Equal(t, NoneFeat, trace[i].Previous)
+		} else {
+			assert.Equal(t, trace[i-1].History, trace[i].Previous)
+		}
+	}
+	assert.Equal(t, "B-PERSON", trace[0].Label)
+	assert.Equal(t, "B", trace[0].History)
+}
+
+func TestNERTraceInput(t *testing.T) {
+	doc, err := NewDocument("", WithNERTrace(true), UsingTokens([]Token{
+		{Text: "Zuva", Label: "B-ORG"},
+		{Text: "is"},
+		{Text: "here"},
+	}))
+	require.NoError(t, err)
+
+	trace := doc.NERTrace()
+	require.Len(t, trace, 3)
+	assert.Equal(t, SourceInput, trace[0].Source)
+	assert.Equal(t, "B-ORG", trace[0].Label)
+	assert.Equal(t, "B", trace[1].Previous)
+	assert.Equal(t, SourceModel, trace[1].Source)
+}
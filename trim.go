@@ -0,0 +1,58 @@
+package prose
+
+import (
+	"strings"
+	"unicode"
+)
+
+// An EntityTrim determines which stray tokens are removed from the edges of
+// entities; its values may be combined (e.g., TrimPunctuation|TrimDeterminers).
+type EntityTrim int
+
+const (
+	// TrimPunctuation removes leading and trailing punctuation tokens:
+	// "( Microsoft" -> "Microsoft".
+	TrimPunctuation EntityTrim = 1 << iota
+	// TrimDeterminers removes leading determiners: "the Acme Corp." ->
+	// "Acme Corp.".
+	TrimDeterminers
+)
+
+// WithEntityTrimming removes stray tokens, which chunking frequently
+// includes, from the edges of entities; by default, entities aren't trimmed.
+func WithEntityTrimming(trim EntityTrim) DocOpt {
+	return func(doc *Document, opts *Config) {
+		opts.Trim = trim
+	}
+}
+
+// trimEntity removes the tokens selected by `trim` from the edges of the
+// entity made up of `parts`.
+func trimEntity(parts []*Token, trim EntityTrim) []*Token {
+	for len(parts) > 0 {
+		first := parts[0]
+		if trim&TrimPunctuation != 0 && isPunctToken(first.Text) {
+			parts = parts[1:]
+		} else if trim&TrimDeterminers != 0 && isDeterminer(first) {
+			parts = parts[1:]
+		} else {
+			break
+		}
+	}
+	for len(parts) > 0 && trim&TrimPunctuation != 0 && isPunctToken(parts[len(parts)-1].Text) {
+		parts = parts[:len(parts)-1]
+	}
+	return parts
+}
+
+func isPunctToken(text string) bool {
+	return text != "" && strings.IndexFunc(text, func(r rune) bool {
+		return !unicode.IsPunct(r)
+	}) < 0
+}
+
+func isDeterminer(tok *Token) bool {
+	return tok.Tag == "DT" || stringInSlice(strings.ToLower(tok.Text), determiners)
+}
+
+var determiners = []string{"a", "an", "the", "this", "that", "these", "those"}
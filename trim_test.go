@@ -0,0 +1,19 @@
+package prose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimEntity(t *testing.T) {
+	parts := []*Token{
+		{Text: "(", Tag: "("}, {Text: "the", Tag: "DT"}, {Text: "Acme", Tag: "NNP"},
+		{Text: "Corp.", Tag: "NNP"}, {Text: ")", Tag: ")"}}
+
+	assert.Equal(t, parts, trimEntity(parts, 0))
+	assert.Equal(t, parts[1:4], trimEntity(parts, TrimPunctuation))
+	assert.Equal(t, parts, trimEntity(parts, TrimDeterminers))
+	assert.Equal(t, parts[2:4], trimEntity(parts, TrimPunctuation|TrimDeterminers))
+	assert.Empty(t, trimEntity(parts[4:], TrimPunctuation))
+}
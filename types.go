@@ -6,15 +6,41 @@ type Token struct {
 	Tag   string // The token's part-of-speech tag.
 	Text  string // The token's actual content.
 	Label string // The token's IOB label.
+	Start int    // The byte offset of the token's start in the input.
+	End   int    // The byte offset just past the token's end in the input.
+
+	// Whitespace is the whitespace that follows the token, if the tokenizer
+	// records it (see UsingPreserveWhitespace).
+	Whitespace string
+	// LeadingWhitespace is the whitespace that precedes the first token of
+	// the text, which the tokenizer records along with Whitespace.
+	LeadingWhitespace string
+
+	// Confidence is the NER model's probability for the token's Label (1
+	// for labels that were given rather than predicted).
+	Confidence float64
 }
 
 // An Entity represents an individual named-entity.
 type Entity struct {
-	Text  string // The entity's actual content.
+	Text  string // The entity's actual content, exactly as written.
 	Label string // The entity's label.
+	Start int    // The byte offset of the entity's start in the text.
+	End   int    // The byte offset just past the entity's end in the text.
+
+	// Confidence is the lowest Confidence of the entity's tokens.
+	Confidence float64
 }
 
 // A Sentence represents a segmented portion of text.
 type Sentence struct {
-	Text string // The sentence's text.
+	Text  string // The sentence's text.
+	Start int    // The byte offset of the sentence's start, or -1 if unknown.
+	End   int    // The byte offset of the sentence's end, or -1 if unknown.
+}
+
+// A TokenizedSentence is a sentence and the tokens it contains.
+type TokenizedSentence struct {
+	Sentence
+	Tokens []Token
 }
@@ -1,11 +1,9 @@
 package prose
 
 import (
-	"bytes"
 	"encoding/gob"
 	"fmt"
 	"io/fs"
-	"path"
 	"strconv"
 	"strings"
 )
@@ -20,6 +18,14 @@ func min(a, b int) int {
 
 // isPunct determines if the string represents a number.
 func isNumeric(s string) bool {
+	if strings.IndexAny(s, "0123456789") < 0 {
+		// Skip ParseFloat, which allocates an error, for most words.
+		word := strings.TrimLeft(s, "+-")
+		if !strings.EqualFold(word, "inf") && !strings.EqualFold(word, "infinity") &&
+			!strings.EqualFold(word, "nan") {
+			return false
+		}
+	}
 	_, err := strconv.ParseFloat(s, 64)
 	return err == nil
 }
@@ -34,36 +40,41 @@ func stringInSlice(a string, slice []string) bool {
 	return false
 }
 
-func getAsset(folder, name string) (*gob.Decoder, error) {
-	b, err := ReadBytes(path.Join(folder, name))
+// decodeFS decodes the gob stored in `name` within `filesys` into `v`.
+func decodeFS(filesys fs.FS, name string, v interface{}) error {
+	file, err := filesys.Open(name)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read stored gob: %w", err)
+		return fmt.Errorf("unable to open %s: %w", name, err)
 	}
-	return gob.NewDecoder(bytes.NewReader(b)), nil
-}
-
-func getDiskAsset(file fs.File) *gob.Decoder {
-	return gob.NewDecoder(file)
+	defer file.Close()
+	if err = gob.NewDecoder(file).Decode(v); err != nil {
+		return withCode(ErrCorruptModel, err)
+	}
+	return nil
 }
 
-func hasAnyPrefix(s string, prefixes []string) bool {
-	n := len(s)
+// longestPrefix returns the longest of `prefixes` that's a proper prefix of
+// `s`, or "" if there isn't one.
+func longestPrefix(s string, prefixes []string) string {
+	longest := ""
 	for _, prefix := range prefixes {
-		if n > len(prefix) && strings.HasPrefix(s, prefix) {
-			return true
+		if len(s) > len(prefix) && len(prefix) > len(longest) && strings.HasPrefix(s, prefix) {
+			longest = prefix
 		}
 	}
-	return false
+	return longest
 }
 
-func hasAnySuffix(s string, suffixes []string) bool {
-	n := len(s)
+// longestSuffix returns the longest of `suffixes` that's a proper suffix of
+// `s`, or "" if there isn't one.
+func longestSuffix(s string, suffixes []string) string {
+	longest := ""
 	for _, suffix := range suffixes {
-		if n > len(suffix) && strings.HasSuffix(s, suffix) {
-			return true
+		if len(s) > len(suffix) && len(suffix) > len(longest) && strings.HasSuffix(s, suffix) {
+			longest = suffix
 		}
 	}
-	return false
+	return longest
 }
 
 func hasAnyIndex(s string, suffixes []string) int {